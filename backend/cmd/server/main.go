@@ -2,8 +2,10 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"gram-panchayat/internal/database"
 	"gram-panchayat/internal/handlers"
 	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/repository"
 	"gram-panchayat/internal/service"
 )
@@ -23,46 +26,128 @@ func main() {
 
 	// Initialize database
 	db := database.InitDB()
+	if err := repository.RegisterTenantCallbacks(db); err != nil {
+		log.Fatal("Failed to register tenant callbacks:", err)
+	}
 	
 	// Run migrations
 	database.RunMigrations(db)
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	tokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	otpRepo := repository.NewOTPRepository(db)
 	applicationRepo := repository.NewApplicationRepository(db)
 	complaintRepo := repository.NewComplaintRepository(db)
+	complaintRepo.SetPostGIS(repository.HasPostGIS(db))
 	propertyRepo := repository.NewPropertyRepository(db)
 	noticeRepo := repository.NewNoticeRepository(db)
 	meetingRepo := repository.NewMeetingRepository(db)
+	attendanceRepo := repository.NewAttendanceRepository(db)
+	resolutionRepo := repository.NewResolutionRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	jurisdictionRepo := repository.NewJurisdictionRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	panchayatRepo := repository.NewPanchayatRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	workOrderRepo := repository.NewWorkOrderRepository(db)
+	materialRepo := repository.NewMaterialRepository(db)
+
+	// OTP delivery: set OTP_DELIVERY=log in local development to print codes
+	// to the server log instead of sending email/SMS
+	otpSenders := map[string]service.Sender{
+		models.OTPChannelEmail: service.NewEmailSender(),
+		models.OTPChannelSMS:   service.NewSMSSender(),
+	}
+	if os.Getenv("OTP_DELIVERY") == "log" {
+		otpSenders = map[string]service.Sender{
+			models.OTPChannelEmail: service.LogSender{},
+			models.OTPChannelSMS:   service.LogSender{},
+		}
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo)
-	userService := service.NewUserService(userRepo)
-	applicationService := service.NewApplicationService(applicationRepo)
-	complaintService := service.NewComplaintService(complaintRepo)
-	propertyService := service.NewPropertyService(propertyRepo)
+	roleService := service.NewRoleService(roleRepo)
+	panchayatService := service.NewPanchayatService(panchayatRepo)
+	panchayats, err := panchayatService.GetPanchayats()
+	if err != nil {
+		log.Fatal("Failed to load panchayats:", err)
+	}
+	for i := range panchayats {
+		if err := roleService.ForTenant(&panchayats[i]).EnsureDefaultRoles(); err != nil {
+			log.Fatal("Failed to create default roles:", err)
+		}
+	}
+	auditService := service.NewAuditService(auditRepo)
+	otpService := service.NewOTPService(otpRepo, otpSenders)
+	authService := service.NewAuthService(userRepo, tokenRepo, sessionRepo, otpService)
+	userService := service.NewUserService(userRepo, sessionRepo, roleService)
+	jurisdictionService := service.NewJurisdictionService(jurisdictionRepo, userRepo)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, otpSenders[models.OTPChannelSMS])
+	applicationService := service.NewApplicationService(applicationRepo, userRepo, roleService, notificationService)
+	complaintService := service.NewComplaintService(complaintRepo, userRepo, notificationService)
+	workOrderService := service.NewWorkOrderService(workOrderRepo, materialRepo, complaintService, notificationService)
+	propertyService := service.NewPropertyService(propertyRepo, paymentRepo, userRepo, otpSenders[models.OTPChannelSMS])
 	noticeService := service.NewNoticeService(noticeRepo)
-	meetingService := service.NewMeetingService(meetingRepo)
+	meetingService := service.NewMeetingService(meetingRepo, attendanceRepo)
+	quorumRules, err := service.ParseQuorumRules(os.Getenv("MEETING_QUORUM"))
+	if err != nil {
+		log.Fatal("Invalid MEETING_QUORUM:", err)
+	}
+	meetingService.SetQuorumRules(quorumRules)
+	attendanceService := service.NewAttendanceService(meetingRepo, attendanceRepo, userRepo)
+	resolutionService := service.NewResolutionService(meetingRepo, attendanceRepo, resolutionRepo)
+
+	// Escalate complaints past their SLA target every COMPLAINT_SLA_INTERVAL
+	slaInterval := 5 * time.Minute
+	if value := os.Getenv("COMPLAINT_SLA_INTERVAL"); value != "" {
+		slaInterval, err = time.ParseDuration(value)
+		if err != nil || slaInterval <= 0 {
+			log.Fatal("Invalid COMPLAINT_SLA_INTERVAL:", value)
+		}
+	}
+	service.NewComplaintSLAScheduler(complaintService, complaintRepo, panchayatRepo, slaInterval).Start(context.Background())
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	userHandler := handlers.NewUserHandler(userService)
-	applicationHandler := handlers.NewApplicationHandler(applicationService)
-	complaintHandler := handlers.NewComplaintHandler(complaintService)
-	propertyHandler := handlers.NewPropertyHandler(propertyService)
-	noticeHandler := handlers.NewNoticeHandler(noticeService)
-	meetingHandler := handlers.NewMeetingHandler(meetingService)
+	userHandler := handlers.NewUserHandler(userService, jurisdictionService, auditService)
+	roleHandler := handlers.NewRoleHandler(roleService, auditService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	panchayatHandler := handlers.NewPanchayatHandler(panchayatService, auditService)
+	applicationHandler := handlers.NewApplicationHandler(applicationService, auditService)
+	complaintHandler := handlers.NewComplaintHandler(complaintService, auditService)
+	workOrderHandler := handlers.NewWorkOrderHandler(workOrderService, auditService)
+	propertyHandler := handlers.NewPropertyHandler(propertyService, auditService)
+	noticeHandler := handlers.NewNoticeHandler(noticeService, auditService)
+	meetingHandler := handlers.NewMeetingHandler(meetingService, auditService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService, auditService)
+	resolutionHandler := handlers.NewResolutionHandler(resolutionService, auditService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	dashboardHandler := handlers.NewDashboardHandler(userService, applicationService, complaintService)
 
 	// Initialize Gin router
 	r := gin.Default()
 
+	// Each panchayat is served from <slug>.TENANT_BASE_DOMAIN; without a base
+	// domain the tenant comes from the X-Panchayat header or DEFAULT_PANCHAYAT
+	tenantBaseDomain := os.Getenv("TENANT_BASE_DOMAIN")
+
 	// CORS middleware
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:5173", "http://localhost:3000"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
+	if tenantBaseDomain != "" {
+		config.AllowOrigins = append(config.AllowOrigins, "https://*."+tenantBaseDomain)
+		config.AllowWildcard = true
+	}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", middleware.TenantHeader, middleware.RequestIDHeader}
+	config.ExposeHeaders = []string{middleware.RequestIDHeader}
 	r.Use(cors.New(config))
 
+	// Request ID, echoed in the X-Request-ID header and in error responses
+	r.Use(middleware.RequestID())
+
 	// Request logger
 	r.Use(middleware.Logger())
 
@@ -73,36 +158,57 @@ func main() {
 
 	// API routes
 	api := r.Group("/api")
+	api.Use(middleware.TenantMiddleware(panchayatService, tenantBaseDomain, database.DefaultPanchayatSlug()))
 	{
+		api.GET("/panchayat", panchayatHandler.GetPanchayat)
+
 		// Auth routes (public)
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
 			auth.POST("/verify-otp", authHandler.VerifyOTP)
+			auth.POST("/resend-otp", authHandler.ResendVerificationOTP)
+
+			// Phone OTP endpoints send SMS or check codes, so each is also
+			// limited per IP, with its own counter
+			auth.POST("/register/phone-otp", middleware.RateLimit(10, 15*time.Minute), authHandler.RequestRegistrationOTP)
+			auth.POST("/phone/request-otp", middleware.RateLimit(10, 15*time.Minute), authHandler.RequestLoginOTP)
+			auth.POST("/phone/login", middleware.RateLimit(10, 15*time.Minute), authHandler.LoginWithOTP)
 		}
 
+		// Meeting calendar feed (public so phone calendars can subscribe)
+		api.GET("/meetings/calendar.ics", meetingHandler.GetCalendarFeed)
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(authService, roleService), middleware.ScopeMiddleware(jurisdictionService))
 		{
 			// User routes
 			protected.GET("/auth/profile", authHandler.GetProfile)
 			protected.PUT("/auth/profile", authHandler.UpdateProfile)
 			protected.POST("/auth/change-password", authHandler.ChangePassword)
+			protected.POST("/auth/phone/request-change", authHandler.RequestPhoneChange)
+			protected.POST("/auth/phone/confirm-change", authHandler.ConfirmPhoneChange)
+			protected.POST("/auth/logout", authHandler.Logout)
+			protected.POST("/auth/logout-all", authHandler.LogoutAll)
 
 			// Dashboard
-			protected.GET("/dashboard/admin", middleware.RoleMiddleware("admin"), dashboardHandler.GetAdminDashboard)
+			protected.GET("/dashboard/admin", middleware.RequirePermission(models.PermDashboardAdminView), dashboardHandler.GetAdminDashboard)
 			protected.GET("/dashboard/citizen", dashboardHandler.GetCitizenDashboard)
 
 			// Services/Applications
 			applications := protected.Group("/services")
 			{
+				applications.GET("/types", applicationHandler.GetApplicationTypes)
+				applications.GET("/types/:code", applicationHandler.GetApplicationType)
 				applications.POST("/apply", applicationHandler.CreateApplication)
 				applications.GET("/applications", applicationHandler.GetUserApplications)
 				applications.GET("/applications/:id", applicationHandler.GetApplication)
+				applications.POST("/applications/:id/resubmit", applicationHandler.ResubmitApplication)
 				applications.GET("/my-applications", applicationHandler.GetMyApplications)
 			}
 
@@ -113,16 +219,36 @@ func main() {
 				complaints.GET("", complaintHandler.GetComplaints)
 				complaints.GET("/:id", complaintHandler.GetComplaint)
 				complaints.POST("/:id/comments", complaintHandler.AddComment)
+				complaints.POST("/:id/feedback", complaintHandler.RateComplaint)
+				complaints.POST("/:id/reopen", complaintHandler.ReopenComplaint)
+			}
+
+			// Notifications
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.GetNotifications)
+				notifications.PUT("/:id/read", notificationHandler.MarkNotificationRead)
 			}
 
 			// Property Tax
 			properties := protected.Group("/property-tax")
 			{
 				properties.GET("/properties", propertyHandler.GetProperties)
+				properties.GET("/properties/:propertyId", propertyHandler.GetProperty)
 				properties.POST("/properties", propertyHandler.CreateProperty)
 				properties.GET("/:propertyId/bills", propertyHandler.GetBills)
 				properties.POST("/:propertyId/payment", propertyHandler.MakePayment)
 				properties.GET("/payment-history", propertyHandler.GetPaymentHistory)
+				properties.GET("/payments/:paymentId", propertyHandler.GetPayment)
+				properties.GET("/due-bills", propertyHandler.GetDueBills)
+				properties.GET("/payments/:paymentId/receipt", propertyHandler.DownloadReceipt)
+
+				properties.PUT("/properties/:propertyId", middleware.RequirePermission(models.PermPropertyManage), propertyHandler.UpdateProperty)
+				properties.DELETE("/properties/:propertyId", middleware.RequirePermission(models.PermPropertyManage), propertyHandler.DeleteProperty)
+				properties.POST("/:propertyId/bills", middleware.RequirePermission(models.PermPropertyBillCreate), propertyHandler.CreateBill)
+				properties.GET("/statistics", middleware.RequirePermission(models.PermPropertyReportsView), propertyHandler.GetPropertyStats)
+				properties.GET("/revenue-report", middleware.RequirePermission(models.PermPropertyReportsView), propertyHandler.GetRevenueReport)
+				properties.POST("/:propertyId/send-reminder", middleware.RequirePermission(models.PermPropertyReminderSend), propertyHandler.SendPaymentReminder)
 			}
 
 			// Notices (public read, admin write)
@@ -130,63 +256,85 @@ func main() {
 			{
 				notices.GET("", noticeHandler.GetNotices)
 				notices.GET("/:id", noticeHandler.GetNotice)
-				notices.POST("", middleware.RoleMiddleware("admin"), noticeHandler.CreateNotice)
-				notices.PUT("/:id", middleware.RoleMiddleware("admin"), noticeHandler.UpdateNotice)
-				notices.DELETE("/:id", middleware.RoleMiddleware("admin"), noticeHandler.DeleteNotice)
+				notices.POST("", middleware.RequirePermission(models.PermNoticesManage), noticeHandler.CreateNotice)
+				notices.PUT("/:id", middleware.RequirePermission(models.PermNoticesManage), noticeHandler.UpdateNotice)
+				notices.DELETE("/:id", middleware.RequirePermission(models.PermNoticesManage), noticeHandler.DeleteNotice)
 			}
 
 			// Meetings
 			meetings := protected.Group("/meetings")
 			{
 				meetings.GET("", meetingHandler.GetMeetings)
+				meetings.GET("/attendance/report", middleware.RequirePermission(models.PermMeetingsAttendanceReport), attendanceHandler.GetAttendanceReport)
+				meetings.GET("/resolutions", resolutionHandler.GetResolutions)
+				meetings.GET("/resolutions/:resolutionId", resolutionHandler.GetResolution)
+				meetings.PUT("/resolutions/:resolutionId/votes", middleware.RequirePermission(models.PermMeetingsResolutionsWrite), resolutionHandler.RecordVotes)
 				meetings.GET("/:id", meetingHandler.GetMeeting)
-				meetings.POST("", middleware.RoleMiddleware("admin"), meetingHandler.CreateMeeting)
-				meetings.POST("/:id/minutes", middleware.RoleMiddleware("admin"), meetingHandler.AddMinutes)
+				meetings.GET("/:id/minutes", meetingHandler.GetMinutes)
+				meetings.GET("/:id/invite.ics", meetingHandler.DownloadInvite)
+				meetings.POST("", middleware.RequirePermission(models.PermMeetingsManage), meetingHandler.CreateMeeting)
+				meetings.POST("/:id/minutes", middleware.RequirePermission(models.PermMeetingsMinutesWrite), meetingHandler.AddMinutes)
+				meetings.PUT("/:id/status", middleware.RequirePermission(models.PermMeetingsManage), meetingHandler.UpdateStatus)
+				meetings.GET("/:id/attendance", attendanceHandler.GetAttendance)
+				meetings.PUT("/:id/attendance", middleware.RequirePermission(models.PermMeetingsAttendanceRecord), attendanceHandler.RecordAttendance)
+				meetings.POST("/:id/attendance/check-in", middleware.RequirePermission(models.PermMeetingsAttend), attendanceHandler.CheckIn)
+				meetings.POST("/:id/resolutions", middleware.RequirePermission(models.PermMeetingsResolutionsWrite), resolutionHandler.CreateResolution)
 			}
 
-			// Admin routes
+			// Admin routes; each route requires its own permission
 			admin := protected.Group("/admin")
-			admin.Use(middleware.RoleMiddleware("admin"))
 			{
-				admin.GET("/users", userHandler.GetUsers)
-				admin.GET("/users/:id", userHandler.GetUser)
-				admin.PUT("/users/:id", userHandler.UpdateUser)
-				admin.DELETE("/users/:id", userHandler.DeleteUser)
-				
-				admin.PUT("/applications/:id/status", applicationHandler.UpdateStatus)
-				admin.PUT("/complaints/:id", complaintHandler.UpdateComplaint)
-			}
+				admin.GET("/users", middleware.RequirePermission(models.PermUsersView), userHandler.GetUsers)
+				admin.GET("/users/:id", middleware.RequirePermission(models.PermUsersView), userHandler.GetUser)
+				admin.PUT("/users/:id", middleware.RequirePermission(models.PermUsersManage), userHandler.UpdateUser)
+				admin.DELETE("/users/:id", middleware.RequirePermission(models.PermUsersManage), userHandler.DeleteUser)
+				admin.PUT("/users/:id/status", middleware.RequirePermission(models.PermUsersManage), userHandler.ActivateDeactivateUser)
+				admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermUsersRoleAssign), userHandler.ChangeUserRole)
+				admin.PUT("/users/:id/password", middleware.RequirePermission(models.PermUsersManage), userHandler.ResetUserPassword)
+				admin.GET("/users/:id/sessions", middleware.RequirePermission(models.PermUsersView), userHandler.GetUserSessions)
+				admin.DELETE("/users/:id/sessions/:sessionId", middleware.RequirePermission(models.PermUsersManage), userHandler.RevokeUserSession)
+				admin.GET("/users/:id/jurisdictions", middleware.RequirePermission(models.PermUsersView), userHandler.GetUserJurisdictions)
+				admin.PUT("/users/:id/jurisdictions", middleware.RequirePermission(models.PermUsersManage), userHandler.SetUserJurisdictions)
+				admin.GET("/users/:id/activity", middleware.RequirePermission(models.PermUsersView, models.PermAuditView), userHandler.GetUserActivity)
 
+				admin.GET("/audit", middleware.RequirePermission(models.PermAuditView), auditHandler.GetAuditLogs)
+				admin.GET("/audit/verify", middleware.RequirePermission(models.PermAuditView), auditHandler.VerifyAuditChain)
 
-			// Property Tax routes
-properties := protected.Group("/property-tax")
-{
-    // Citizen & Admin routes
-    properties.GET("/properties", propertyHandler.GetProperties)
-    properties.GET("/properties/:propertyId", propertyHandler.GetProperty)
-    properties.POST("/properties", propertyHandler.CreateProperty)
-    properties.GET("/:propertyId/bills", propertyHandler.GetBills)
-    properties.POST("/:propertyId/payment", propertyHandler.MakePayment)
-    properties.GET("/payment-history", propertyHandler.GetPaymentHistory)
-    properties.GET("/payments/:paymentId", propertyHandler.GetPayment)
-    properties.GET("/due-bills", propertyHandler.GetDueBills)
-    properties.GET("/payments/:paymentId/receipt", propertyHandler.DownloadReceipt)
-    
-    // Admin only routes
-    admin := properties.Group("")
-    admin.Use(middleware.RoleMiddleware("admin"))
-    {
-        admin.PUT("/properties/:propertyId", propertyHandler.UpdateProperty)
-        admin.DELETE("/properties/:propertyId", propertyHandler.DeleteProperty)
-        admin.POST("/:propertyId/bills", propertyHandler.CreateBill)
-        admin.GET("/statistics", propertyHandler.GetPropertyStats)
-        admin.GET("/revenue-report", propertyHandler.GetRevenueReport)
-        admin.POST("/:propertyId/send-reminder", propertyHandler.SendPaymentReminder)
-    }
+				admin.PUT("/panchayat", middleware.RequirePermission(models.PermPanchayatSettingsManage), panchayatHandler.UpdatePanchayat)
+				admin.GET("/complaints/sla", middleware.RequirePermission(models.PermPanchayatSettingsManage), panchayatHandler.GetComplaintSLA)
+				admin.PUT("/complaints/sla", middleware.RequirePermission(models.PermPanchayatSettingsManage), panchayatHandler.UpdateComplaintSLA)
 
+				admin.GET("/permissions", middleware.RequirePermission(models.PermRolesManage), roleHandler.GetPermissions)
+				admin.GET("/roles", middleware.RequirePermission(models.PermRolesManage), roleHandler.GetRoles)
+				admin.POST("/roles", middleware.RequirePermission(models.PermRolesManage), roleHandler.CreateRole)
+				admin.PUT("/roles/:name", middleware.RequirePermission(models.PermRolesManage), roleHandler.UpdateRole)
+				admin.DELETE("/roles/:name", middleware.RequirePermission(models.PermRolesManage), roleHandler.DeleteRole)
 
-	
-}
+				admin.POST("/applications/:id/actions", middleware.RequirePermission(models.PermApplicationsStatusUpdate), applicationHandler.ReviewApplication)
+				admin.GET("/application-types", middleware.RequirePermission(models.PermApplicationTypesManage), applicationHandler.GetAllApplicationTypes)
+				admin.PUT("/application-types/:code", middleware.RequirePermission(models.PermApplicationTypesManage), applicationHandler.SaveApplicationType)
+				admin.GET("/complaints/stats", middleware.RequirePermission(models.PermComplaintsViewAll), complaintHandler.GetComplaintStats)
+				admin.GET("/complaints/geojson", middleware.RequirePermission(models.PermComplaintsViewAll), complaintHandler.ExportComplaintsGeoJSON)
+				admin.GET("/complaints/hotspots", middleware.RequirePermission(models.PermComplaintsViewAll), complaintHandler.GetComplaintHotspots)
+				admin.PUT("/complaints/:id", middleware.RequirePermission(models.PermComplaintsUpdate), complaintHandler.UpdateComplaint)
+				admin.PUT("/complaints/:id/assign", middleware.RequirePermission(models.PermComplaintsAssign), complaintHandler.AssignComplaint)
+				admin.GET("/complaints/:id/duplicates", middleware.RequirePermission(models.PermComplaintsViewAll), complaintHandler.GetDuplicateSuggestions)
+				admin.POST("/complaints/:id/merge", middleware.RequirePermission(models.PermComplaintsUpdate), complaintHandler.MergeComplaint)
+
+				admin.GET("/work-orders", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.GetWorkOrders)
+				admin.POST("/work-orders", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.CreateWorkOrder)
+				admin.GET("/work-orders/cost-report", middleware.RequirePermission(models.PermWorkOrdersReportsView), workOrderHandler.GetWorkOrderCostReport)
+				admin.GET("/work-orders/:id", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.GetWorkOrder)
+				admin.PUT("/work-orders/:id", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.UpdateWorkOrder)
+				admin.POST("/work-orders/:id/complete", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.CompleteWorkOrder)
+				admin.POST("/work-orders/:id/materials", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.IssueMaterial)
+				admin.POST("/work-orders/:id/photos", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.AddWorkOrderPhoto)
+
+				admin.GET("/materials", middleware.RequirePermission(models.PermWorkOrdersManage), workOrderHandler.GetMaterials)
+				admin.POST("/materials", middleware.RequirePermission(models.PermMaterialsManage), workOrderHandler.CreateMaterial)
+				admin.PUT("/materials/:id", middleware.RequirePermission(models.PermMaterialsManage), workOrderHandler.UpdateMaterial)
+				admin.POST("/materials/:id/restock", middleware.RequirePermission(models.PermMaterialsManage), workOrderHandler.RestockMaterial)
+			}
 		}
 	}
 
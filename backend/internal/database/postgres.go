@@ -2,6 +2,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
 )
 
 func InitDB() *gorm.DB {
@@ -34,26 +36,227 @@ func InitDB() *gorm.DB {
 	return db
 }
 
+// DefaultPanchayatSlug is the panchayat used when a request names none, and
+// the one existing records are assigned to when multi-tenancy is introduced
+func DefaultPanchayatSlug() string {
+	if slug := os.Getenv("DEFAULT_PANCHAYAT"); slug != "" {
+		return slug
+	}
+	return "default"
+}
+
 func RunMigrations(db *gorm.DB) {
-	err := db.AutoMigrate(
+	// Migrations read and write every panchayat
+	db = repository.WithAllTenants(db)
+
+	if err := db.AutoMigrate(&models.Panchayat{}); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+	defaultPanchayat, err := ensureDefaultPanchayat(db)
+	if err != nil {
+		log.Fatal("Failed to create default panchayat:", err)
+	}
+	if err := migrateResolutionSequences(db, defaultPanchayat.ID); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	if err := migrateComplaintColumns(db); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	tenantModels := []interface{}{
 		&models.User{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.StaffJurisdiction{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.OTPCode{},
 		&models.Application{},
+		&models.ApplicationType{},
+		&models.ApplicationAction{},
 		&models.Complaint{},
+		&models.ComplaintStatusChange{},
+		&models.ComplaintComment{},
+		&models.ComplaintAttachment{},
+		&models.Material{},
+		&models.WorkOrder{},
+		&models.WorkOrderMaterial{},
+		&models.WorkOrderPhoto{},
 		&models.Property{},
 		&models.TaxBill{},
 		&models.Payment{},
 		&models.Notice{},
 		&models.Meeting{},
 		&models.MeetingMinutes{},
+		&models.MeetingStatusChange{},
+		&models.MeetingAttendance{},
+		&models.Resolution{},
+		&models.ResolutionVote{},
+		&models.ResolutionSequence{},
 		&models.Scheme{},
 		&models.SchemeApplication{},
 		&models.Document{},
 		&models.Notification{},
-	)
+	}
+	if err := db.AutoMigrate(tenantModels...); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
 
-	if err != nil {
+	// Sessions and codes sent to a user belong to the user's panchayat; the
+	// rest of the records created before panchayats existed belong to the
+	// default one
+	for _, table := range []string{"sessions", "otp_codes"} {
+		err := db.Exec(fmt.Sprintf("UPDATE %[1]s SET panchayat_id = users.panchayat_id FROM users "+
+			"WHERE %[1]s.user_id = users.id AND (%[1]s.panchayat_id IS NULL OR %[1]s.panchayat_id = 0)", table)).Error
+		if err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	}
+	for _, model := range tenantModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+		if stmt.Schema.LookUpField("PanchayatID") == nil {
+			continue
+		}
+		err := db.Exec(
+			fmt.Sprintf("UPDATE %s SET panchayat_id = ? WHERE panchayat_id IS NULL OR panchayat_id = 0", stmt.Schema.Table),
+			defaultPanchayat.ID,
+		).Error
+		if err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	}
+
+	// Radius searches use PostGIS where it is installed
+	if repository.HasPostGIS(db) {
+		err := db.Exec("CREATE INDEX IF NOT EXISTS idx_complaints_geography ON complaints " +
+			"USING GIST (geography(ST_MakePoint(longitude, latitude))) " +
+			"WHERE latitude IS NOT NULL AND longitude IS NOT NULL").Error
+		if err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	}
+
+	if err := migrateAuditLogs(db); err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Users, role names and resolution numbers are unique per panchayat;
+	// these indexes made them unique across all panchayats
+	for _, index := range []string{
+		"idx_users_email",
+		"idx_users_email_present",
+		"idx_users_phone_number",
+		"idx_users_aadhar_number",
+		"idx_resolution_number",
+		"idx_roles_name",
+	} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	}
+
 	log.Println("Database migrations completed")
-}
\ No newline at end of file
+}
+
+// ensureDefaultPanchayat creates the first panchayat on a fresh or
+// single-panchayat database
+func ensureDefaultPanchayat(db *gorm.DB) (*models.Panchayat, error) {
+	slug := DefaultPanchayatSlug()
+
+	var panchayat models.Panchayat
+	err := db.Where("slug = ?", slug).First(&panchayat).Error
+	if err == nil {
+		return &panchayat, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name := os.Getenv("PANCHAYAT_NAME")
+	if name == "" {
+		name = "Gram Panchayat"
+	}
+	panchayat = models.Panchayat{
+		Slug:                    slug,
+		Name:                    name,
+		FinancialYearStartMonth: 4,
+		TaxRates:                map[string]float64{},
+		IsActive:                true,
+	}
+	if err := db.Create(&panchayat).Error; err != nil {
+		return nil, err
+	}
+	return &panchayat, nil
+}
+
+// migrateComplaintColumns renames the subject and body of complaints filed
+// before the complaint model gained its title and description
+func migrateComplaintColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Complaint{}) {
+		return nil
+	}
+	for from, to := range map[string]string{"subject": "title", "body": "description"} {
+		if !migrator.HasColumn(&models.Complaint{}, from) || migrator.HasColumn(&models.Complaint{}, to) {
+			continue
+		}
+		if err := migrator.RenameColumn(&models.Complaint{}, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateResolutionSequences moves resolution_sequences from one counter per
+// financial year to one per panchayat and financial year
+func migrateResolutionSequences(db *gorm.DB, defaultPanchayatID uint) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.ResolutionSequence{}) || migrator.HasColumn(&models.ResolutionSequence{}, "panchayat_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE resolution_sequences ADD COLUMN panchayat_id bigint NOT NULL DEFAULT %d", defaultPanchayatID),
+			"ALTER TABLE resolution_sequences ALTER COLUMN panchayat_id DROP DEFAULT",
+			"ALTER TABLE resolution_sequences DROP CONSTRAINT IF EXISTS resolution_sequences_pkey",
+			"ALTER TABLE resolution_sequences ADD PRIMARY KEY (panchayat_id, financial_year)",
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateAuditLogs creates audit_logs and makes it append-only, so records
+// can only be removed by someone able to drop the trigger
+func migrateAuditLogs(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs",
+		`CREATE TRIGGER audit_logs_append_only
+			BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,50 +2,105 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
-	
+
 	"github.com/gin-gonic/gin"
 )
 
 type ApplicationHandler struct {
 	applicationService *service.ApplicationService
+	auditService       *service.AuditService
 }
 
-func NewApplicationHandler(applicationService *service.ApplicationService) *ApplicationHandler {
-	return &ApplicationHandler{applicationService: applicationService}
+func NewApplicationHandler(applicationService *service.ApplicationService, auditService *service.AuditService) *ApplicationHandler {
+	return &ApplicationHandler{
+		applicationService: applicationService,
+		auditService:       auditService,
+	}
 }
 
 type CreateApplicationRequest struct {
-	Type     string                 `json:"type" binding:"required"` // birth, death, income, caste, residence, marriage
+	Type     string                 `json:"type" binding:"required"` // an application type code, e.g. birth
 	FormData map[string]interface{} `json:"form_data" binding:"required"`
-	Priority string                 `json:"priority"`
+	Priority string                 `json:"priority" binding:"omitempty,oneof=normal urgent"`
+}
+
+type ReviewApplicationRequest struct {
+	Action  string `json:"action" binding:"required,oneof=approve reject return send_back"`
+	Remarks string `json:"remarks" binding:"required,max=1000"`
+}
+
+type ResubmitApplicationRequest struct {
+	FormData map[string]interface{} `json:"form_data"` // replaces the answers when given
+	Remarks  string                 `json:"remarks" binding:"max=1000"`
+}
+
+type SaveApplicationTypeRequest struct {
+	Name        string                 `json:"name" binding:"required,max=100"`
+	Description string                 `json:"description" binding:"max=1000"`
+	Form        models.FormSchema      `json:"form"`
+	Workflow    []models.WorkflowStage `json:"workflow"`  // keeps the current workflow when omitted
+	IsActive    *bool                  `json:"is_active"` // defaults to true
+}
+
+// applicationErrorStatus maps application service errors to HTTP statuses
+func applicationErrorStatus(err error) int {
+	if errors.Is(err, service.ErrApplicationNotFound) || errors.Is(err, service.ErrApplicationTypeNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, service.ErrNotApplicant) || errors.Is(err, service.ErrNotStageReviewer) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
 }
 
+// CreateApplication - Apply for a certificate, filling in the form of its type
 func (h *ApplicationHandler) CreateApplication(c *gin.Context) {
 	userID := c.GetUint("userID")
-	
+
 	var req CreateApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	application, err := h.applicationService.CreateApplication(userID, req.Type, req.FormData, req.Priority)
+	application, err := h.applicationService.ForTenant(middleware.Tenant(c)).CreateApplication(userID, req.Type, req.FormData, req.Priority)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create application", err.Error())
+		serviceErrorResponse(c, applicationErrorStatus(err), "Failed to create application", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Application submitted successfully", application)
 }
 
+// applicationListOptions are the sort orders GetUserApplications accepts
+var applicationListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"title":      "title",
+	},
+	DefaultSort: "-created_at",
+}
+
+// GetUserApplications - List the caller's applications, or every application
+// in their jurisdiction for staff who can view all; awaiting=me keeps those
+// waiting at a stage of the caller's role
 func (h *ApplicationHandler) GetUserApplications(c *gin.Context) {
 	userID := c.GetUint("userID")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	list, err := utils.ParseListQuery(c, applicationListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
 	status := c.Query("status")
 	applicationType := c.Query("type")
 
@@ -57,35 +112,42 @@ func (h *ApplicationHandler) GetUserApplications(c *gin.Context) {
 		filters["type"] = applicationType
 	}
 
-	applications, total, err := h.applicationService.GetUserApplications(userID, page, limit, filters)
+	scope := models.JurisdictionScope{All: true}
+	if middleware.HasPermission(c, models.PermApplicationsViewAll) {
+		scope = middleware.Scope(c)
+	} else {
+		filters["user_id"] = userID
+	}
+	if c.Query("awaiting") == "me" {
+		if role := c.GetString("role"); role == models.RoleAdmin {
+			filters["status"] = []string{models.ApplicationStatusSubmitted, models.ApplicationStatusUnderReview}
+		} else {
+			filters["stage_role"] = role
+		}
+	}
+
+	applications, total, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetApplications(list, filters, scope)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch applications", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Applications retrieved", applications, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Applications retrieved", applications, list.Pagination(total))
 }
 
 func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	applicationID, _ := strconv.Atoi(c.Param("id"))
 
-	application, err := h.applicationService.GetApplication(uint(applicationID))
+	application, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetApplication(uint(applicationID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Application not found", err.Error())
+		serviceErrorResponse(c, applicationErrorStatus(err), "Application not found", err)
 		return
 	}
 
-	// Check if user has permission to view this application
-	if role != "admin" && application.UserID != userID {
+	// Applicants see their own applications; staff those in their jurisdiction
+	if application.UserID != userID && !(middleware.HasPermission(c, models.PermApplicationsViewAll) &&
+		middleware.Scope(c).Allows(application.Village, application.Taluka)) {
 		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "")
 		return
 	}
@@ -95,8 +157,8 @@ func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 
 func (h *ApplicationHandler) GetMyApplications(c *gin.Context) {
 	userID := c.GetUint("userID")
-	
-	applications, err := h.applicationService.GetAllUserApplications(userID)
+
+	applications, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetAllUserApplications(userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch applications", err.Error())
 		return
@@ -105,25 +167,135 @@ func (h *ApplicationHandler) GetMyApplications(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Applications retrieved", applications)
 }
 
-func (h *ApplicationHandler) UpdateStatus(c *gin.Context) {
-	adminID := c.GetUint("userID")
+// ReviewApplication - Approve, reject, return or send back an application at
+// its current workflow stage (Admin)
+func (h *ApplicationHandler) ReviewApplication(c *gin.Context) {
+	reviewerID := c.GetUint("userID")
+	applicationID, _ := strconv.Atoi(c.Param("id"))
+
+	var req ReviewApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	applicationService := h.applicationService.ForTenant(middleware.Tenant(c))
+	before, err := applicationService.GetApplication(uint(applicationID))
+	if err == nil && !middleware.Scope(c).Allows(before.Village, before.Taluka) {
+		err = service.ErrApplicationNotFound
+	}
+	if err != nil {
+		serviceErrorResponse(c, applicationErrorStatus(err), "Application not found", err)
+		return
+	}
+
+	application, err := applicationService.ReviewApplication(uint(applicationID), reviewerID, c.GetString("role"), req.Action, req.Remarks)
+	if err != nil {
+		serviceErrorResponse(c, applicationErrorStatus(err), "Failed to review application", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityApplication, application.ID, before, application) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Application reviewed", application)
+}
+
+// ResubmitApplication - Send a returned application back for review, with
+// corrected form data
+func (h *ApplicationHandler) ResubmitApplication(c *gin.Context) {
 	applicationID, _ := strconv.Atoi(c.Param("id"))
 
-	var req struct {
-		Status  string `json:"status" binding:"required"` // approved, rejected, under_review
-		Remarks string `json:"remarks"`
+	var req ResubmitApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	application, err := h.applicationService.ForTenant(middleware.Tenant(c)).ResubmitApplication(uint(applicationID), c.GetUint("userID"), req.FormData, req.Remarks)
+	if err != nil {
+		serviceErrorResponse(c, applicationErrorStatus(err), "Failed to resubmit application", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Application resubmitted", application)
+}
+
+// GetApplicationTypes - List the certificates that can be applied for, with
+// their forms
+func (h *ApplicationHandler) GetApplicationTypes(c *gin.Context) {
+	types, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetApplicationTypes(false)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch application types", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Application types retrieved", types)
+}
+
+// GetApplicationType - Get the form of one application type
+func (h *ApplicationHandler) GetApplicationType(c *gin.Context) {
+	kind, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetApplicationType(c.Param("code"))
+	if err == nil && !kind.IsActive {
+		err = service.ErrApplicationTypeNotFound
+	}
+	if err != nil {
+		serviceErrorResponse(c, applicationErrorStatus(err), "Application type not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Application type retrieved", kind)
+}
+
+// GetAllApplicationTypes - List every application type, inactive ones included (Admin)
+func (h *ApplicationHandler) GetAllApplicationTypes(c *gin.Context) {
+	types, err := h.applicationService.ForTenant(middleware.Tenant(c)).GetApplicationTypes(true)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch application types", err.Error())
+		return
 	}
 
+	utils.SuccessResponse(c, http.StatusOK, "Application types retrieved", types)
+}
+
+// SaveApplicationType - Add an application type or replace its form (Admin)
+func (h *ApplicationHandler) SaveApplicationType(c *gin.Context) {
+	var req SaveApplicationTypeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
+		return
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	code := c.Param("code")
+	applicationService := h.applicationService.ForTenant(middleware.Tenant(c))
+	before, err := applicationService.GetApplicationType(code)
+	if err != nil && !errors.Is(err, service.ErrApplicationTypeNotFound) {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save application type", err.Error())
 		return
 	}
 
-	application, err := h.applicationService.UpdateApplicationStatus(uint(applicationID), adminID, req.Status, req.Remarks)
+	kind, err := applicationService.SaveApplicationType(code, service.ApplicationTypeInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Form:        req.Form,
+		Workflow:    req.Workflow,
+		IsActive:    isActive,
+	})
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update application", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to save application type", err)
+		return
+	}
+	action := models.AuditActionUpdate
+	if before == nil || before.ID == 0 {
+		action = models.AuditActionCreate
+	}
+	if !recordAudit(c, h.auditService, action, models.AuditEntityApplicationType, kind.ID, before, kind) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Application status updated", application)
-}
\ No newline at end of file
+	utils.SuccessResponse(c, http.StatusOK, "Application type saved", kind)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttendanceHandler struct {
+	attendanceService *service.AttendanceService
+	auditService      *service.AuditService
+}
+
+func NewAttendanceHandler(attendanceService *service.AttendanceService, auditService *service.AuditService) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: attendanceService,
+		auditService:      auditService,
+	}
+}
+
+type AttendanceEntryRequest struct {
+	UserID      uint   `json:"user_id" binding:"required"`
+	Status      string `json:"status" binding:"required"` // present, absent, excused
+	CheckedInAt string `json:"checked_in_at"`
+	Remarks     string `json:"remarks"`
+}
+
+// GetAttendance - Get the attendance register of a meeting
+func (h *AttendanceHandler) GetAttendance(c *gin.Context) {
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	register, err := h.attendanceService.ForTenant(middleware.Tenant(c)).GetRegister(uint(meetingID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance retrieved", register)
+}
+
+// RecordAttendance - Record or correct attendance entries (Admin)
+func (h *AttendanceHandler) RecordAttendance(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	var req struct {
+		Entries []AttendanceEntryRequest `json:"entries" binding:"required,min=1,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	entries := make([]service.AttendanceEntry, 0, len(req.Entries))
+	for _, e := range req.Entries {
+		entry := service.AttendanceEntry{
+			UserID:  e.UserID,
+			Status:  e.Status,
+			Remarks: e.Remarks,
+		}
+		if e.CheckedInAt != "" {
+			checkedInAt, err := time.Parse(time.RFC3339, e.CheckedInAt)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid check-in time", "Check-in time must be in RFC3339 format")
+				return
+			}
+			entry.CheckedInAt = &checkedInAt
+		}
+		entries = append(entries, entry)
+	}
+
+	before, err := h.attendanceService.ForTenant(middleware.Tenant(c)).GetRegister(uint(meetingID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
+		return
+	}
+
+	register, err := h.attendanceService.ForTenant(middleware.Tenant(c)).RecordAttendance(uint(meetingID), adminID, entries)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to record attendance", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityMeeting, uint(meetingID),
+		map[string]interface{}{"attendance": before}, map[string]interface{}{"attendance": register}) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance recorded", register)
+}
+
+// CheckIn - Mark the current user present at a meeting in progress
+func (h *AttendanceHandler) CheckIn(c *gin.Context) {
+	userID := c.GetUint("userID")
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	record, err := h.attendanceService.ForTenant(middleware.Tenant(c)).CheckIn(uint(meetingID), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Check-in failed", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checked in", record)
+}
+
+// GetAttendanceReport - Attendance percentage per member over a date range (Admin)
+func (h *AttendanceHandler) GetAttendanceReport(c *gin.Context) {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), time.Local)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date", "Date must be in YYYY-MM-DD format")
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", c.Query("to"), time.Local)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date", "Date must be in YYYY-MM-DD format")
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if meetingType := c.Query("meeting_type"); meetingType != "" {
+		filters["meeting_type"] = meetingType
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid user ID", err.Error())
+			return
+		}
+		filters["user_id"] = uint(id)
+	}
+
+	report, err := h.attendanceService.ForTenant(middleware.Tenant(c)).GetAttendanceReport(from, to, filters)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to generate report", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance report generated", report)
+}
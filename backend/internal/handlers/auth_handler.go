@@ -2,7 +2,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"gram-panchayat/internal/middleware"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
@@ -18,7 +20,7 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 }
 
 type RegisterRequest struct {
-	Email        string `json:"email" binding:"required,email"`
+	Email        string `json:"email" binding:"omitempty,email"` // optional when phone_otp is given
 	Password     string `json:"password" binding:"required,min=6"`
 	FirstName    string `json:"first_name" binding:"required"`
 	LastName     string `json:"last_name" binding:"required"`
@@ -27,23 +29,25 @@ type RegisterRequest struct {
 	Address      string `json:"address" binding:"required"`
 	Village      string `json:"village" binding:"required"`
 	Pincode      string `json:"pincode" binding:"required"`
+	PhoneOTP     string `json:"phone_otp" binding:"omitempty,len=6"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	DeviceID string `json:"device_id"` // stable per-install ID; a new login replaces the device's old session
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	user, err := h.authService.Register(req)
+	user, err := h.authService.ForTenant(middleware.Tenant(c)).Register(service.RegisterInput(req))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Registration failed", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Registration failed", err)
 		return
 	}
 
@@ -53,25 +57,135 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	result, err := h.authService.Login(req.Email, req.Password)
+	result, err := h.authService.ForTenant(middleware.Tenant(c)).Login(req.Email, req.Password, deviceInfo(c, req.DeviceID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Login failed", err.Error())
+		serviceErrorResponse(c, http.StatusUnauthorized, "Login failed", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Login successful", result)
 }
 
+// RequestRegistrationOTP - Send a code by SMS to verify the phone number before registering
+func (h *AuthHandler) RequestRegistrationOTP(c *gin.Context) {
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).RequestRegistrationOTP(req.PhoneNumber); err != nil {
+		serviceErrorResponse(c, otpErrorStatus(err), "Failed to send OTP", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "OTP sent to phone number", nil)
+}
+
+// RequestLoginOTP - Send a login code by SMS
+func (h *AuthHandler) RequestLoginOTP(c *gin.Context) {
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).RequestLoginOTP(req.PhoneNumber); err != nil {
+		serviceErrorResponse(c, otpErrorStatus(err), "Failed to send OTP", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "If the phone number is registered, an OTP has been sent", nil)
+}
+
+// LoginWithOTP - Log in with a phone number and the SMS code
+func (h *AuthHandler) LoginWithOTP(c *gin.Context) {
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required"`
+		OTP         string `json:"otp" binding:"required,len=6"`
+		DeviceID    string `json:"device_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.authService.ForTenant(middleware.Tenant(c)).LoginWithOTP(req.PhoneNumber, req.OTP, deviceInfo(c, req.DeviceID))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, service.ErrOTPAttemptsExceeded) {
+			status = http.StatusTooManyRequests
+		} else if errors.Is(err, service.ErrAccountDeactivated) {
+			status = http.StatusForbidden
+		}
+		serviceErrorResponse(c, status, "Login failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", result)
+}
+
+// RefreshToken - Exchange a refresh token for a new token pair
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.authService.ForTenant(middleware.Tenant(c)).Refresh(req.RefreshToken, c.ClientIP())
+	if err != nil {
+		serviceErrorResponse(c, http.StatusUnauthorized, "Token refresh failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed", result)
+}
+
+// Logout - Sign out the current session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID := c.GetUint("sessionID")
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).Logout(sessionID); err != nil {
+		serviceErrorResponse(c, http.StatusInternalServerError, "Logout failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll - Sign out every session of the current user
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).LogoutAll(userID); err != nil {
+		serviceErrorResponse(c, http.StatusInternalServerError, "Logout failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out from all devices", nil)
+}
+
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID := c.GetUint("userID")
 	
-	user, err := h.authService.GetUserByID(userID)
+	user, err := h.authService.ForTenant(middleware.Tenant(c)).GetUserByID(userID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeUserNotFound, "User not found", err.Error())
 		return
 	}
 
@@ -81,15 +195,14 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID := c.GetUint("userID")
 	
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	var patch service.ProfilePatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	user, err := h.authService.UpdateProfile(userID, updates)
+	user, err := h.authService.ForTenant(middleware.Tenant(c)).UpdateProfile(userID, patch)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Update failed", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Update failed", err)
 		return
 	}
 
@@ -102,13 +215,13 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	err := h.authService.SendPasswordResetOTP(req.Email)
+	err := h.authService.ForTenant(middleware.Tenant(c)).SendPasswordResetOTP(req.Email)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to send OTP", err.Error())
+		serviceErrorResponse(c, otpErrorStatus(err), "Failed to send OTP", err)
 		return
 	}
 
@@ -122,19 +235,85 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	valid, err := h.authService.VerifyOTP(req.Email, req.OTP)
+	valid, err := h.authService.ForTenant(middleware.Tenant(c)).VerifyOTP(req.Email, req.OTP)
 	if err != nil || !valid {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid OTP", "")
+		if err == nil {
+			err = service.ErrOTPInvalid
+		}
+		serviceErrorResponse(c, otpErrorStatus(err), "Invalid OTP", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "OTP verified", nil)
 }
 
+// ResendVerificationOTP - Send a new registration verification code
+func (h *AuthHandler) ResendVerificationOTP(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).SendVerificationOTP(req.Email); err != nil {
+		serviceErrorResponse(c, otpErrorStatus(err), "Failed to send OTP", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "OTP sent to email", nil)
+}
+
+// RequestPhoneChange - Send a code by SMS to a new phone number
+func (h *AuthHandler) RequestPhoneChange(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.authService.ForTenant(middleware.Tenant(c)).RequestPhoneChange(userID, req.PhoneNumber); err != nil {
+		serviceErrorResponse(c, otpErrorStatus(err), "Failed to send OTP", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "OTP sent to new phone number", nil)
+}
+
+// ConfirmPhoneChange - Verify the SMS code and save the new phone number
+func (h *AuthHandler) ConfirmPhoneChange(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required"`
+		OTP         string `json:"otp" binding:"required,len=6"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.authService.ForTenant(middleware.Tenant(c)).ConfirmPhoneChange(userID, req.PhoneNumber, req.OTP)
+	if err != nil {
+		serviceErrorResponse(c, otpErrorStatus(err), "Phone number change failed", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Phone number updated", user)
+}
+
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req struct {
 		Email       string `json:"email" binding:"required,email"`
@@ -143,17 +322,17 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	err := h.authService.ResetPassword(req.Email, req.OTP, req.NewPassword)
+	err := h.authService.ForTenant(middleware.Tenant(c)).ResetPassword(req.Email, req.OTP, req.NewPassword)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Password reset failed", err.Error())
+		serviceErrorResponse(c, otpErrorStatus(err), "Password reset failed", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Password reset successful", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Password reset successful, please log in again", nil)
 }
 
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
@@ -165,15 +344,32 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	err := h.authService.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
+	err := h.authService.ForTenant(middleware.Tenant(c)).ChangePassword(userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Password change failed", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Password change failed", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Password changed successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Password changed successfully, please log in again", nil)
+}
+
+// otpErrorStatus maps OTP failures to a status code; rate limiting is 429
+func otpErrorStatus(err error) int {
+	if errors.Is(err, service.ErrOTPResendTooSoon) || errors.Is(err, service.ErrOTPAttemptsExceeded) ||
+		errors.Is(err, service.ErrOTPLimitReached) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
+
+func deviceInfo(c *gin.Context, deviceID string) service.DeviceInfo {
+	return service.DeviceInfo{
+		DeviceID:  deviceID,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
 }
\ No newline at end of file
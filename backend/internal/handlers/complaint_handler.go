@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
@@ -11,20 +14,25 @@ import (
 
 type ComplaintHandler struct {
 	complaintService *service.ComplaintService
+	auditService     *service.AuditService
 }
 
-func NewComplaintHandler(complaintService *service.ComplaintService) *ComplaintHandler {
-	return &ComplaintHandler{complaintService: complaintService}
+func NewComplaintHandler(complaintService *service.ComplaintService, auditService *service.AuditService) *ComplaintHandler {
+	return &ComplaintHandler{
+		complaintService: complaintService,
+		auditService:     auditService,
+	}
 }
 
 type CreateComplaintRequest struct {
 	Title       string   `json:"title" binding:"required"`
 	Description string   `json:"description" binding:"required"`
-	Category    string   `json:"category" binding:"required"`
+	Category    string   `json:"category" binding:"required,oneof=infrastructure water electricity sanitation road street_light garbage other"`
 	Location    string   `json:"location"`
 	Latitude    *float64 `json:"latitude"`
 	Longitude   *float64 `json:"longitude"`
-	Priority    string   `json:"priority"`
+	Ward        string   `json:"ward" binding:"max=100"`
+	Priority    string   `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
 }
 
 // CreateComplaint - Register a new complaint
@@ -33,25 +41,20 @@ func (h *ComplaintHandler) CreateComplaint(c *gin.Context) {
 	
 	var req CreateComplaintRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
-		return
-	}
-
-	// Validate category
-	validCategories := []string{"infrastructure", "water", "electricity", "sanitation", "road", "street_light", "garbage", "other"}
-	isValidCategory := false
-	for _, cat := range validCategories {
-		if req.Category == cat {
-			isValidCategory = true
-			break
-		}
-	}
-	if !isValidCategory {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category", "Please select a valid category")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	complaint, err := h.complaintService.CreateComplaint(userID, &req)
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).CreateComplaint(userID, service.CreateComplaintInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+		Location:    req.Location,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Ward:        req.Ward,
+		Priority:    req.Priority,
+	})
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create complaint", err.Error())
 		return
@@ -60,70 +63,83 @@ func (h *ComplaintHandler) CreateComplaint(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Complaint registered successfully", complaint)
 }
 
+// complaintListOptions are the sort orders GetComplaints accepts
+var complaintListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"priority":   "priority",
+		"status":     "status",
+		"category":   "category",
+	},
+	DefaultSort: "-created_at",
+}
+
 // GetComplaints - List all complaints (filtered by user for citizens)
 func (h *ComplaintHandler) GetComplaints(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
-	category := c.Query("category")
-	priority := c.Query("priority")
-
-	filters := map[string]interface{}{}
-	if status != "" {
-		filters["status"] = status
+	list, err := utils.ParseListQuery(c, complaintListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
 	}
-	if category != "" {
-		filters["category"] = category
+
+	filters, ok := complaintFilters(c)
+	if !ok {
+		return
 	}
-	if priority != "" {
-		filters["priority"] = priority
+	geo, err := utils.ParseGeoFilter(c)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
 	}
 
-	// If citizen, only show their complaints
-	if role != "admin" {
+	// Staff see complaints in their jurisdiction; citizens only their own
+	scope := middleware.Scope(c)
+	if !middleware.HasPermission(c, models.PermComplaintsViewAll) {
 		filters["user_id"] = userID
+		scope = models.JurisdictionScope{All: true}
 	}
 
-	complaints, total, err := h.complaintService.GetComplaints(page, limit, filters)
+	complaints, total, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaints(list, filters, scope, geo)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch complaints", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Complaints retrieved successfully", complaints, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Complaints retrieved successfully", complaints, list.Pagination(total))
 }
 
 // GetComplaint - Get single complaint details
 func (h *ComplaintHandler) GetComplaint(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	complaintID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid complaint ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid complaint ID", err.Error())
 		return
 	}
 
-	complaint, err := h.complaintService.GetComplaint(uint(complaintID))
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaint(uint(complaintID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Complaint not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeComplaintNotFound, "Complaint not found", err.Error())
 		return
 	}
 
-	// Check permission
-	if role != "admin" && complaint.UserID != userID {
+	// Reporters see their own complaints, and those their duplicates were
+	// merged into; staff those in their jurisdiction
+	allowed := complaint.UserID == userID || (middleware.HasPermission(c, models.PermComplaintsViewAll) &&
+		middleware.Scope(c).Allows(complaint.Village, complaint.Taluka))
+	if !allowed && complaint.DuplicateCount > 0 {
+		allowed, _ = h.complaintService.ForTenant(middleware.Tenant(c)).IsReporter(complaint.ID, userID)
+	}
+	if !allowed {
 		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "")
 		return
 	}
+	if !middleware.HasPermission(c, models.PermComplaintsViewAll) {
+		complaint.HideInternalComments()
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Complaint retrieved successfully", complaint)
 }
@@ -131,86 +147,340 @@ func (h *ComplaintHandler) GetComplaint(c *gin.Context) {
 // UpdateComplaint - Update complaint status and details (Admin)
 func (h *ComplaintHandler) UpdateComplaint(c *gin.Context) {
 	adminID := c.GetUint("userID")
-	complaintID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid complaint ID", err.Error())
+	before, ok := h.scopedComplaint(c)
+	if !ok {
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	var patch service.ComplaintPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	// Add admin info
-	updates["assigned_to"] = adminID
+	// Reassigning needs complaints.assign. The staff member updating an
+	// unassigned complaint takes it on; an assigned one stays with its
+	// assignee, who is credited with its SLA and feedback.
+	if patch.AssignedTo != nil {
+		if !middleware.HasPermission(c, models.PermComplaintsAssign) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to assign complaints")
+			return
+		}
+	} else if before.AssignedTo == nil {
+		patch.AssignedTo = &adminID
+	}
 
-	complaint, err := h.complaintService.UpdateComplaint(uint(complaintID), updates)
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).UpdateComplaint(before.ID, adminID, patch)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update complaint", err.Error())
+		serviceErrorResponse(c, complaintErrorStatus(err), "Failed to update complaint", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityComplaint, complaint.ID, before, complaint) {
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Complaint updated successfully", complaint)
 }
 
+type AttachmentRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=photo document"`
+	URL  string `json:"url" binding:"required,url,max=500"`
+	Name string `json:"name" binding:"max=255"`
+}
+
+type AddCommentRequest struct {
+	Comment     string              `json:"comment" binding:"required,max=5000"`
+	IsInternal  bool                `json:"is_internal"`
+	Attachments []AttachmentRequest `json:"attachments" binding:"max=5,dive"`
+}
+
 // AddComment - Add comment to complaint
 func (h *ComplaintHandler) AddComment(c *gin.Context) {
 	userID := c.GetUint("userID")
 	complaintID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid complaint ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid complaint ID", err.Error())
 		return
 	}
 
-	var req struct {
-		Comment    string `json:"comment" binding:"required"`
-		IsInternal bool   `json:"is_internal"`
+	var req AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	input := service.CommentInput{Body: req.Comment, IsInternal: req.IsInternal}
+	for _, attachment := range req.Attachments {
+		input.Attachments = append(input.Attachments, service.AttachmentInput(attachment))
+	}
+
+	comment, err := h.complaintService.ForTenant(middleware.Tenant(c)).AddComment(uint(complaintID), userID, input,
+		middleware.HasPermission(c, models.PermComplaintsViewAll), middleware.Scope(c))
+	if err != nil {
+		status := complaintErrorStatus(err)
+		if errors.Is(err, service.ErrCommentNotAllowed) || errors.Is(err, service.ErrInternalNoteDenied) {
+			status = http.StatusForbidden
+		}
+		serviceErrorResponse(c, status, "Failed to add comment", err)
+		return
 	}
 
+	utils.SuccessResponse(c, http.StatusCreated, "Comment added successfully", comment)
+}
+
+type ComplaintFeedbackRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// RateComplaint - Rate the resolution of your own complaint
+func (h *ComplaintHandler) RateComplaint(c *gin.Context) {
+	complaintID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid complaint ID", err.Error())
+		return
+	}
+
+	var req ComplaintFeedbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	comment, err := h.complaintService.AddComment(uint(complaintID), userID, req.Comment, req.IsInternal)
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).RateComplaint(uint(complaintID), c.GetUint("userID"),
+		service.ComplaintFeedbackInput(req))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add comment", err.Error())
+		serviceErrorResponse(c, feedbackErrorStatus(err), "Failed to rate complaint", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Comment added successfully", comment)
+	utils.SuccessResponse(c, http.StatusOK, "Feedback recorded successfully", complaint)
+}
+
+// ReopenComplaint - Reopen your own resolved complaint whose fix did not hold
+func (h *ComplaintHandler) ReopenComplaint(c *gin.Context) {
+	complaintID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid complaint ID", err.Error())
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).ReopenComplaint(uint(complaintID), c.GetUint("userID"), req.Reason)
+	if err != nil {
+		serviceErrorResponse(c, feedbackErrorStatus(err), "Failed to reopen complaint", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Complaint reopened successfully", complaint)
+}
+
+// feedbackErrorStatus maps reporter feedback errors to HTTP statuses
+func feedbackErrorStatus(err error) int {
+	if errors.Is(err, service.ErrNotComplaintReporter) {
+		return http.StatusForbidden
+	}
+	return complaintErrorStatus(err)
 }
 
 // AssignComplaint - Assign complaint to staff (Admin)
 func (h *ComplaintHandler) AssignComplaint(c *gin.Context) {
-	complaintID, _ := strconv.Atoi(c.Param("id"))
+	before, ok := h.scopedComplaint(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		AssignedTo uint `json:"assigned_to" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	if err := h.complaintService.AssignComplaint(uint(complaintID), req.AssignedTo); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to assign complaint", err.Error())
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).AssignComplaint(before.ID, req.AssignedTo)
+	if err != nil {
+		serviceErrorResponse(c, complaintErrorStatus(err), "Failed to assign complaint", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityComplaint, complaint.ID, before, complaint) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Complaint assigned successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Complaint assigned successfully", complaint)
 }
 
 // GetComplaintStats - Get complaint statistics (Admin)
 func (h *ComplaintHandler) GetComplaintStats(c *gin.Context) {
-	stats, err := h.complaintService.GetComplaintStats()
+	stats, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaintStats()
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch statistics", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Statistics retrieved successfully", stats)
-}
\ No newline at end of file
+}
+// complaintErrorStatus picks the HTTP status for a failed complaint change
+func complaintErrorStatus(err error) int {
+	if errors.Is(err, service.ErrComplaintNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// ExportComplaintsGeoJSON - Download located complaints as GeoJSON, with the list filters (Admin)
+func (h *ComplaintHandler) ExportComplaintsGeoJSON(c *gin.Context) {
+	filters, ok := complaintFilters(c)
+	if !ok {
+		return
+	}
+	geo, err := utils.ParseGeoFilter(c)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	collection, err := h.complaintService.ForTenant(middleware.Tenant(c)).ExportGeoJSON(filters, middleware.Scope(c), geo)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export complaints", err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="complaints.geojson"`)
+	c.Data(http.StatusOK, "application/geo+json", collection)
+}
+
+// GetComplaintHotspots - Cluster open complaints by location and category (Admin)
+func (h *ComplaintHandler) GetComplaintHotspots(c *gin.Context) {
+	filters, ok := complaintFilters(c)
+	if !ok {
+		return
+	}
+	delete(filters, "status") // hotspots are always of open complaints
+
+	cellMeters, err := strconv.ParseFloat(c.DefaultQuery("cell", "250"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid cell size", "cell must be a number of meters")
+		return
+	}
+	minCount, err := strconv.Atoi(c.DefaultQuery("min_count", "3"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid minimum count", "min_count must be a number")
+		return
+	}
+
+	hotspots, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetHotspots(filters, middleware.Scope(c), cellMeters, minCount)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to find hotspots", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Hotspots retrieved successfully", hotspots)
+}
+
+// complaintFilters reads the equality filters of complaint lists. It writes
+// the error response and returns false when one is malformed.
+func complaintFilters(c *gin.Context) (map[string]interface{}, bool) {
+	filters := map[string]interface{}{}
+	for _, key := range []string{"status", "category", "priority", "ward"} {
+		if value := c.Query(key); value != "" {
+			filters[key] = value
+		}
+	}
+	if value := c.Query("assigned_to"); value != "" {
+		assignee, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid assigned_to", err.Error())
+			return nil, false
+		}
+		filters["assigned_to"] = uint(assignee)
+	}
+	return filters, true
+}
+
+// GetDuplicateSuggestions - List complaints likely to report the same problem (Admin)
+func (h *ComplaintHandler) GetDuplicateSuggestions(c *gin.Context) {
+	complaint, ok := h.scopedComplaint(c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.complaintService.ForTenant(middleware.Tenant(c)).SuggestDuplicates(complaint.ID, middleware.Scope(c))
+	if err != nil {
+		serviceErrorResponse(c, complaintErrorStatus(err), "Failed to find duplicates", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Duplicate suggestions retrieved successfully", suggestions)
+}
+
+// MergeComplaint - Close a complaint as a duplicate of a parent complaint (Admin)
+func (h *ComplaintHandler) MergeComplaint(c *gin.Context) {
+	before, ok := h.scopedComplaint(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ParentID uint   `json:"parent_id" binding:"required"`
+		Note     string `json:"note" binding:"max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	// The parent must be in the staff member's jurisdiction too
+	target, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaint(req.ParentID)
+	if err == nil && !middleware.Scope(c).Allows(target.Village, target.Taluka) {
+		err = service.ErrComplaintNotFound
+	}
+	if err != nil {
+		serviceErrorResponse(c, complaintErrorStatus(err), "Failed to merge complaint", err)
+		return
+	}
+
+	parent, err := h.complaintService.ForTenant(middleware.Tenant(c)).MergeComplaint(before.ID, target.ID, c.GetUint("userID"), req.Note)
+	if err != nil {
+		serviceErrorResponse(c, complaintErrorStatus(err), "Failed to merge complaint", err)
+		return
+	}
+	after, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaint(before.ID)
+	if err != nil {
+		auditFailedResponse(c, models.AuditActionUpdate, models.AuditEntityComplaint, before.ID, err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityComplaint, before.ID, before, after) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Complaint merged successfully", parent)
+}
+
+// scopedComplaint loads the complaint named in the path for a staff action,
+// answering 404 when it does not exist or lies outside the staff member's
+// jurisdiction
+func (h *ComplaintHandler) scopedComplaint(c *gin.Context) (*models.Complaint, bool) {
+	complaintID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid complaint ID", err.Error())
+		return nil, false
+	}
+
+	complaint, err := h.complaintService.ForTenant(middleware.Tenant(c)).GetComplaint(uint(complaintID))
+	if err == nil && !middleware.Scope(c).Allows(complaint.Village, complaint.Taluka) {
+		err = service.ErrComplaintNotFound
+	}
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeComplaintNotFound, "Complaint not found", err.Error())
+		return nil, false
+	}
+	return complaint, true
+}
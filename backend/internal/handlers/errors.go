@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceErrorCodes are the service errors clients need to tell apart from
+// other failures with the same status
+var serviceErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrInvalidCredentials, utils.CodeInvalidCredentials},
+	{service.ErrAccountDeactivated, utils.CodeAccountDeactivated},
+	{service.ErrInvalidRefreshToken, utils.CodeRefreshTokenInvalid},
+	{service.ErrRefreshTokenReused, utils.CodeRefreshTokenReused},
+	{service.ErrSessionRevoked, utils.CodeSessionRevoked},
+	{service.ErrOTPInvalid, utils.CodeOTPInvalid},
+	{service.ErrOTPAttemptsExceeded, utils.CodeOTPAttemptsExceeded},
+	{service.ErrOTPResendTooSoon, utils.CodeOTPResendTooSoon},
+	{service.ErrOTPLimitReached, utils.CodeOTPLimitReached},
+	{service.ErrPanchayatNotFound, utils.CodePanchayatNotFound},
+	{service.ErrRoleNotFound, utils.CodeRoleNotFound},
+	{service.ErrUnknownPermission, utils.CodeUnknownPermission},
+	{service.ErrPermissionsNotHeld, utils.CodePermissionDenied},
+	{service.ErrOwnJurisdictions, utils.CodePermissionDenied},
+	{service.ErrJurisdictionOutOfScope, utils.CodePermissionDenied},
+	{service.ErrPropertyNotFound, utils.CodePropertyNotFound},
+	{service.ErrBillNotFound, utils.CodeBillNotFound},
+	{service.ErrPaymentNotFound, utils.CodePaymentNotFound},
+	{service.ErrComplaintNotFound, utils.CodeComplaintNotFound},
+	{service.ErrApplicationNotFound, utils.CodeApplicationNotFound},
+	{service.ErrApplicationTypeNotFound, utils.CodeApplicationTypeNotFound},
+	{service.ErrNotificationNotFound, utils.CodeNotificationNotFound},
+	{service.ErrFeedbackWindowClosed, utils.CodeFeedbackWindowOver},
+	{service.ErrWorkOrderNotFound, utils.CodeWorkOrderNotFound},
+	{service.ErrMaterialNotFound, utils.CodeMaterialNotFound},
+}
+
+// serviceErrorResponse aborts with the code of a known service error, or with
+// the generic code for the status otherwise. Services that check input field
+// by field return a *utils.ValidationError, which is answered as such.
+func serviceErrorResponse(c *gin.Context, status int, message string, err error) {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		utils.ValidationErrorResponse(c, validationErr)
+		return
+	}
+	for _, known := range serviceErrorCodes {
+		if errors.Is(err, known.err) {
+			utils.ErrorResponseWithCode(c, status, known.code, message, err.Error())
+			return
+		}
+	}
+	utils.ErrorResponse(c, status, message, err.Error())
+}
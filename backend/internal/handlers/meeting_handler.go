@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
@@ -11,44 +15,130 @@ import (
 
 type MeetingHandler struct {
 	meetingService *service.MeetingService
+	auditService   *service.AuditService
 }
 
-func NewMeetingHandler(meetingService *service.MeetingService) *MeetingHandler {
-	return &MeetingHandler{meetingService: meetingService}
+func NewMeetingHandler(meetingService *service.MeetingService, auditService *service.AuditService) *MeetingHandler {
+	return &MeetingHandler{
+		meetingService: meetingService,
+		auditService:   auditService,
+	}
 }
 
-func (h *MeetingHandler) GetMeetings(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+// meetingListOptions are the sort orders GetMeetings accepts
+var meetingListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"scheduled_at": "scheduled_at",
+		"created_at":   "created_at",
+		"title":        "title",
+		"status":       "status",
+	},
+	DefaultSort: "-scheduled_at",
+}
 
-	meetings, total, err := h.meetingService.GetMeetings(page, limit)
+func (h *MeetingHandler) GetMeetings(c *gin.Context) {
+	list, err := utils.ParseListQuery(c, meetingListOptions)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch meetings", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
+	meetingType := c.Query("meeting_type")
+	status := c.Query("status")
+
+	filters := map[string]interface{}{}
+	if meetingType != "" {
+		filters["meeting_type"] = meetingType
+	}
+	if status != "" {
+		filters["status"] = status
+	}
+
+	// Date range filter on scheduled_at (YYYY-MM-DD, "to" is inclusive)
+	if from := c.Query("from"); from != "" {
+		fromDate, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date", "Date must be in YYYY-MM-DD format")
+			return
+		}
+		filters["scheduled_from"] = fromDate
+	}
+	if to := c.Query("to"); to != "" {
+		toDate, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date", "Date must be in YYYY-MM-DD format")
+			return
+		}
+		filters["scheduled_to"] = toDate.AddDate(0, 0, 1)
+	}
+
+	meetings, total, err := h.meetingService.ForTenant(middleware.Tenant(c)).GetMeetings(list, filters)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch meetings", err.Error())
+		return
 	}
 
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Meetings retrieved", meetings, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Meetings retrieved", meetings, list.Pagination(total))
 }
 
 func (h *MeetingHandler) GetMeeting(c *gin.Context) {
 	meetingID, _ := strconv.Atoi(c.Param("id"))
 
-	meeting, err := h.meetingService.GetMeeting(uint(meetingID))
+	meeting, err := h.meetingService.ForTenant(middleware.Tenant(c)).GetMeeting(uint(meetingID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Meeting not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Meeting retrieved", meeting)
 }
 
+// GetCalendarFeed - iCalendar feed of upcoming meetings for calendar subscriptions
+func (h *MeetingHandler) GetCalendarFeed(c *gin.Context) {
+	feed, err := h.meetingService.ForTenant(middleware.Tenant(c)).CalendarFeed(c.Query("meeting_type"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate calendar", err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
+
+// DownloadInvite - Download a single meeting as an .ics file
+func (h *MeetingHandler) DownloadInvite(c *gin.Context) {
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	invite, err := h.meetingService.ForTenant(middleware.Tenant(c)).MeetingInvite(uint(meetingID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="meeting-%d.ics"`, meetingID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", invite)
+}
+
+// GetMinutes - Get every recorded revision of a meeting's minutes
+func (h *MeetingHandler) GetMinutes(c *gin.Context) {
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	minutes, err := h.meetingService.ForTenant(middleware.Tenant(c)).GetMinutesHistory(uint(meetingID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Minutes retrieved", minutes)
+}
+
 func (h *MeetingHandler) CreateMeeting(c *gin.Context) {
 	var req struct {
 		Title       string `json:"title" binding:"required"`
@@ -60,15 +150,25 @@ func (h *MeetingHandler) CreateMeeting(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	meeting, err := h.meetingService.CreateMeeting(&req)
+	meeting, err := h.meetingService.ForTenant(middleware.Tenant(c)).CreateMeeting(service.CreateMeetingInput{
+		Title:       req.Title,
+		Description: req.Description,
+		MeetingType: req.MeetingType,
+		ScheduledAt: req.ScheduledAt,
+		Location:    req.Location,
+		Agenda:      req.Agenda,
+	})
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create meeting", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityMeeting, meeting.ID, nil, meeting) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Meeting scheduled", meeting)
 }
@@ -78,58 +178,79 @@ func (h *MeetingHandler) AddMinutes(c *gin.Context) {
 	meetingID, _ := strconv.Atoi(c.Param("id"))
 
 	var req struct {
-		Content   string `json:"content" binding:"required"`
-		Attendees string `json:"attendees"`
-		Decisions string `json:"decisions"`
+		Content       string `json:"content" binding:"required"`
+		Attendees     string `json:"attendees"`
+		AttendeeCount int    `json:"attendee_count"`
+		Decisions     string `json:"decisions"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	minutes, err := h.meetingService.AddMinutes(uint(meetingID), adminID, &req)
+	minutes, err := h.meetingService.ForTenant(middleware.Tenant(c)).AddMinutes(uint(meetingID), adminID, service.MinutesInput{
+		Content:       req.Content,
+		Attendees:     req.Attendees,
+		AttendeeCount: req.AttendeeCount,
+		Decisions:     req.Decisions,
+	})
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add minutes", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityMeeting, uint(meetingID),
+		nil, map[string]interface{}{"minutes": minutes}) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Minutes added", minutes)
 }
 
-// internal/handlers/dashboard_handler.go
-type DashboardHandler struct {
-	userService        *service.UserService
-	applicationService *service.ApplicationService
-	complaintService   *service.ComplaintService
-}
+// UpdateStatus - Move a meeting through its lifecycle (Admin)
+func (h *MeetingHandler) UpdateStatus(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
 
-func NewDashboardHandler(userService *service.UserService, applicationService *service.ApplicationService, complaintService *service.ComplaintService) *DashboardHandler {
-	return &DashboardHandler{
-		userService:        userService,
-		applicationService: applicationService,
-		complaintService:   complaintService,
+	var req struct {
+		Status      string `json:"status" binding:"required"` // in_progress, completed, postponed, cancelled, scheduled
+		Reason      string `json:"reason"`
+		ScheduledAt string `json:"scheduled_at"` // required when rescheduling a postponed meeting
 	}
-}
 
-func (h *DashboardHandler) GetAdminDashboard(c *gin.Context) {
-	stats, err := h.applicationService.GetAdminStats()
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch dashboard stats", err.Error())
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Dashboard stats retrieved", stats)
-}
+	var rescheduleTo *time.Time
+	if req.ScheduledAt != "" {
+		scheduledAt, err := time.Parse(time.RFC3339, req.ScheduledAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scheduled time", "Scheduled time must be in RFC3339 format")
+			return
+		}
+		rescheduleTo = &scheduledAt
+	}
 
-func (h *DashboardHandler) GetCitizenDashboard(c *gin.Context) {
-	userID := c.GetUint("userID")
+	before, err := h.meetingService.ForTenant(middleware.Tenant(c)).GetMeeting(uint(meetingID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMeetingNotFound, "Meeting not found", err.Error())
+		return
+	}
 
-	stats, err := h.applicationService.GetCitizenStats(userID)
+	meeting, err := h.meetingService.ForTenant(middleware.Tenant(c)).ChangeStatus(uint(meetingID), adminID, req.Status, req.Reason, rescheduleTo)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch dashboard stats", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update meeting status", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityMeeting, meeting.ID, before, meeting) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Dashboard stats retrieved", stats)
-}
\ No newline at end of file
+	utils.SuccessResponse(c, http.StatusOK, "Meeting status updated", meeting)
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
@@ -10,10 +12,14 @@ import (
 )
 type NoticeHandler struct {
 	noticeService *service.NoticeService
+	auditService  *service.AuditService
 }
 
-func NewNoticeHandler(noticeService *service.NoticeService) *NoticeHandler {
-	return &NoticeHandler{noticeService: noticeService}
+func NewNoticeHandler(noticeService *service.NoticeService, auditService *service.AuditService) *NoticeHandler {
+	return &NoticeHandler{
+		noticeService: noticeService,
+		auditService:  auditService,
+	}
 }
 
 type CreateNoticeRequest struct {
@@ -24,17 +30,29 @@ type CreateNoticeRequest struct {
 	IsPublished bool   `json:"is_published"`
 }
 
+// noticeListOptions are the sort orders GetNotices accepts
+var noticeListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+		"title":      "title",
+	},
+	DefaultSort: "-created_at",
+}
+
 // GetNotices - List all published notices
 func (h *NoticeHandler) GetNotices(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	list, err := utils.ParseListQuery(c, noticeListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
 	category := c.Query("category")
-	role := c.GetString("role")
 
 	filters := map[string]interface{}{}
 	
 	// Non-admin users can only see published notices
-	if role != "admin" {
+	if !middleware.HasPermission(c, models.PermNoticesManage) {
 		filters["is_published"] = true
 	}
 	
@@ -42,33 +60,26 @@ func (h *NoticeHandler) GetNotices(c *gin.Context) {
 		filters["category"] = category
 	}
 
-	notices, total, err := h.noticeService.GetNotices(page, limit, filters)
+	notices, total, err := h.noticeService.GetNotices(list, filters)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch notices", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Notices retrieved successfully", notices, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Notices retrieved successfully", notices, list.Pagination(total))
 }
 
 // GetNotice - Get single notice
 func (h *NoticeHandler) GetNotice(c *gin.Context) {
 	noticeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notice ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid notice ID", err.Error())
 		return
 	}
 
 	notice, err := h.noticeService.GetNotice(uint(noticeID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Notice not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeNoticeNotFound, "Notice not found", err.Error())
 		return
 	}
 
@@ -81,7 +92,7 @@ func (h *NoticeHandler) CreateNotice(c *gin.Context) {
 	
 	var req CreateNoticeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -90,6 +101,9 @@ func (h *NoticeHandler) CreateNotice(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create notice", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityNotice, notice.ID, nil, notice) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Notice created successfully", notice)
 }
@@ -98,13 +112,23 @@ func (h *NoticeHandler) CreateNotice(c *gin.Context) {
 func (h *NoticeHandler) UpdateNotice(c *gin.Context) {
 	noticeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notice ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid notice ID", err.Error())
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	var patch service.NoticePatch
+	if !bindPatch(c, &patch) {
+		return
+	}
+	updates := patch.Updates()
+	if len(updates) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update notice", "no updatable fields provided")
+		return
+	}
+
+	before, err := h.noticeService.GetNotice(uint(noticeID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeNoticeNotFound, "Notice not found", err.Error())
 		return
 	}
 
@@ -113,6 +137,9 @@ func (h *NoticeHandler) UpdateNotice(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update notice", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityNotice, notice.ID, before, notice) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Notice updated successfully", notice)
 }
@@ -121,7 +148,13 @@ func (h *NoticeHandler) UpdateNotice(c *gin.Context) {
 func (h *NoticeHandler) DeleteNotice(c *gin.Context) {
 	noticeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notice ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid notice ID", err.Error())
+		return
+	}
+
+	before, err := h.noticeService.GetNotice(uint(noticeID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeNoticeNotFound, "Notice not found", err.Error())
 		return
 	}
 
@@ -129,6 +162,9 @@ func (h *NoticeHandler) DeleteNotice(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete notice", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionDelete, models.AuditEntityNotice, before.ID, before, nil) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Notice deleted successfully", nil)
 }
@@ -142,7 +178,7 @@ func (h *NoticeHandler) PublishNotice(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -150,6 +186,10 @@ func (h *NoticeHandler) PublishNotice(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update publish status", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityNotice, uint(noticeID),
+		nil, map[string]interface{}{"is_published": req.IsPublished}) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Notice publish status updated", nil)
 }
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// notificationListOptions are the sort orders GetNotifications accepts
+var notificationListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+	},
+	DefaultSort: "-created_at",
+}
+
+// GetNotifications - List the current user's notifications, ?unread=true for unread only
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	list, err := utils.ParseListQuery(c, notificationListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+	notifications, total, err := h.notificationService.ForTenant(middleware.Tenant(c)).GetNotifications(c.GetUint("userID"), list, unreadOnly)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch notifications", err.Error())
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", notifications, list.Pagination(total))
+}
+
+// MarkNotificationRead - Mark one of the current user's notifications read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid notification ID", err.Error())
+		return
+	}
+
+	if err := h.notificationService.ForTenant(middleware.Tenant(c)).MarkRead(c.GetUint("userID"), uint(notificationID)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+		serviceErrorResponse(c, status, "Failed to update notification", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
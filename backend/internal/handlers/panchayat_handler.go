@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PanchayatHandler struct {
+	panchayatService *service.PanchayatService
+	auditService     *service.AuditService
+}
+
+func NewPanchayatHandler(panchayatService *service.PanchayatService, auditService *service.AuditService) *PanchayatHandler {
+	return &PanchayatHandler{
+		panchayatService: panchayatService,
+		auditService:     auditService,
+	}
+}
+
+type PanchayatSettingsRequest struct {
+	Name                    string             `json:"name" binding:"required"`
+	LGDCode                 string             `json:"lgd_code"`
+	LogoURL                 string             `json:"logo_url"`
+	FinancialYearStartMonth int                `json:"financial_year_start_month" binding:"required,min=1,max=12"`
+	TaxRates                map[string]float64 `json:"tax_rates"`
+}
+
+type ComplaintSLARequest struct {
+	Hours      map[string]int          `json:"hours"`
+	Escalation []models.EscalationStep `json:"escalation"`
+	ReopenDays int                     `json:"reopen_days"`
+}
+
+// GetPanchayat - Get the current panchayat's public settings
+func (h *PanchayatHandler) GetPanchayat(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Panchayat retrieved successfully", middleware.Tenant(c))
+}
+
+// UpdatePanchayat - Update the current panchayat's settings (Admin)
+func (h *PanchayatHandler) UpdatePanchayat(c *gin.Context) {
+	var req PanchayatSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	before := middleware.Tenant(c)
+	panchayat, err := h.panchayatService.UpdateSettings(before.ID, service.PanchayatSettingsInput(req))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrPanchayatNotFound) {
+			status = http.StatusNotFound
+		}
+		serviceErrorResponse(c, status, "Failed to update panchayat", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityPanchayat, panchayat.ID, before, panchayat) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Panchayat updated successfully", panchayat)
+}
+
+// GetComplaintSLA - Get the complaint SLA targets, escalation chain and reopen window in effect (Admin)
+func (h *PanchayatHandler) GetComplaintSLA(c *gin.Context) {
+	tenant := middleware.Tenant(c)
+	hours := make(map[string]int, len(models.DefaultComplaintSLAHours))
+	for category := range models.DefaultComplaintSLAHours {
+		hours[category] = int(tenant.ComplaintSLATarget(category).Hours())
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Complaint SLA retrieved successfully", gin.H{
+		"hours":       hours,
+		"escalation":  tenant.ComplaintEscalationChain(),
+		"reopen_days": int(tenant.ComplaintReopenWindow().Hours() / 24),
+	})
+}
+
+// UpdateComplaintSLA - Set the complaint SLA targets, escalation chain and reopen window (Admin)
+func (h *PanchayatHandler) UpdateComplaintSLA(c *gin.Context) {
+	var req ComplaintSLARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	before := middleware.Tenant(c)
+	panchayat, err := h.panchayatService.UpdateComplaintSLA(before.ID, service.ComplaintSLAInput(req))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrPanchayatNotFound) {
+			status = http.StatusNotFound
+		}
+		serviceErrorResponse(c, status, "Failed to update complaint SLA", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityPanchayat, panchayat.ID, before, panchayat) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Complaint SLA updated successfully", panchayat)
+}
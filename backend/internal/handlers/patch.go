@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindPatch decodes the request body into a typed patch, answering with a 400
+// listing the rejected fields when it does not fit
+func bindPatch(c *gin.Context, patch interface{}) bool {
+	if err := utils.BindPatch(c, patch); err != nil {
+		utils.BindErrorResponse(c, err)
+		return false
+	}
+	return true
+}
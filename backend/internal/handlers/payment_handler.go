@@ -28,7 +28,7 @@ func (h *PaymentHandler) InitiatePayment(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -51,7 +51,7 @@ func (h *PaymentHandler) VerifyPayment(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -70,7 +70,7 @@ func (h *PaymentHandler) GetPaymentStatus(c *gin.Context) {
 
 	payment, err := h.paymentService.GetPaymentByID(paymentID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Payment not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePaymentNotFound, "Payment not found", err.Error())
 		return
 	}
 
@@ -86,7 +86,7 @@ func (h *PaymentHandler) RefundPayment(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
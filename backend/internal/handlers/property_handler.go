@@ -4,6 +4,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
@@ -12,10 +14,14 @@ import (
 
 type PropertyHandler struct {
 	propertyService *service.PropertyService
+	auditService    *service.AuditService
 }
 
-func NewPropertyHandler(propertyService *service.PropertyService) *PropertyHandler {
-	return &PropertyHandler{propertyService: propertyService}
+func NewPropertyHandler(propertyService *service.PropertyService, auditService *service.AuditService) *PropertyHandler {
+	return &PropertyHandler{
+		propertyService: propertyService,
+		auditService:    auditService,
+	}
 }
 
 // CreatePropertyRequest - Request structure for creating property
@@ -26,20 +32,36 @@ type CreatePropertyRequest struct {
 	AnnualTaxAmount float64 `json:"annual_tax_amount"`
 }
 
+// propertyListOptions are the sort orders GetProperties accepts
+var propertyListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at":        "created_at",
+		"property_type":     "property_type",
+		"village":           "village",
+		"area":              "area",
+		"annual_tax_amount": "annual_tax_amount",
+	},
+	DefaultSort: "-created_at",
+}
+
 // GetProperties - Get all properties (filtered by ownership for citizens)
 func (h *PropertyHandler) GetProperties(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	list, err := utils.ParseListQuery(c, propertyListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
 	propertyType := c.Query("property_type")
 
 	filters := map[string]interface{}{}
-	
-	// If citizen, only show their properties
-	if role != "admin" {
+	scope := middleware.Scope(c)
+
+	// Staff see properties in their jurisdiction; citizens only their own
+	if !middleware.HasPermission(c, models.PermPropertyViewAll) {
 		filters["owner_id"] = userID
+		scope = models.JurisdictionScope{All: true}
 	}
 
 	// Add property type filter if provided
@@ -47,40 +69,31 @@ func (h *PropertyHandler) GetProperties(c *gin.Context) {
 		filters["property_type"] = propertyType
 	}
 
-	properties, total, err := h.propertyService.GetProperties(page, limit, filters)
+	properties, total, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetProperties(list, filters, scope)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch properties", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Properties retrieved successfully", properties, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Properties retrieved successfully", properties, list.Pagination(total))
 }
 
 // GetProperty - Get single property by ID
 func (h *PropertyHandler) GetProperty(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	propertyID, err := strconv.Atoi(c.Param("propertyId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid property ID", err.Error())
 		return
 	}
 
-	property, err := h.propertyService.GetProperty(uint(propertyID))
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetProperty(uint(propertyID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Property not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePropertyNotFound, "Property not found", err.Error())
 		return
 	}
 
-	// Verify ownership
-	if role != "admin" && property.OwnerID != userID {
+	if !canAccessProperty(c, property, userID, models.PermPropertyViewAll) {
 		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to view this property")
 		return
 	}
@@ -94,7 +107,7 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	
 	var req CreatePropertyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -115,9 +128,12 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 		return
 	}
 
-	property, err := h.propertyService.CreateProperty(userID, &req)
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).CreateProperty(userID, service.CreatePropertyInput(req))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create property", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to create property", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityProperty, property.ID, nil, property) {
 		return
 	}
 
@@ -126,21 +142,22 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 
 // UpdateProperty - Update property details (Admin only)
 func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
-	propertyID, err := strconv.Atoi(c.Param("propertyId"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+	before, ok := h.scopedProperty(c)
+	if !ok {
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	var patch service.PropertyPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	property, err := h.propertyService.UpdateProperty(uint(propertyID), updates)
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).UpdateProperty(before.ID, patch)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update property", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to update property", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityProperty, property.ID, before, property) {
 		return
 	}
 
@@ -149,14 +166,16 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 
 // DeleteProperty - Delete/deactivate property
 func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
-	propertyID, err := strconv.Atoi(c.Param("propertyId"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+	before, ok := h.scopedProperty(c)
+	if !ok {
 		return
 	}
 
-	if err := h.propertyService.DeleteProperty(uint(propertyID)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete property", err.Error())
+	if err := h.propertyService.ForTenant(middleware.Tenant(c)).DeleteProperty(before.ID); err != nil {
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to delete property", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionDelete, models.AuditEntityProperty, before.ID, before, nil) {
 		return
 	}
 
@@ -166,27 +185,23 @@ func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 // GetBills - Get all tax bills for a property
 func (h *PropertyHandler) GetBills(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	propertyID, err := strconv.Atoi(c.Param("propertyId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid property ID", err.Error())
 		return
 	}
 
-	// Verify ownership
-	if role != "admin" {
-		property, err := h.propertyService.GetProperty(uint(propertyID))
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusNotFound, "Property not found", err.Error())
-			return
-		}
-		if property.OwnerID != userID {
-			utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to view these bills")
-			return
-		}
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetProperty(uint(propertyID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePropertyNotFound, "Property not found", err.Error())
+		return
+	}
+	if !canAccessProperty(c, property, userID, models.PermPropertyViewAll) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to view these bills")
+		return
 	}
 
-	bills, err := h.propertyService.GetPropertyBills(uint(propertyID))
+	bills, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetPropertyBills(uint(propertyID))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch bills", err.Error())
 		return
@@ -197,9 +212,8 @@ func (h *PropertyHandler) GetBills(c *gin.Context) {
 
 // CreateBill - Generate a new tax bill (Admin only)
 func (h *PropertyHandler) CreateBill(c *gin.Context) {
-	propertyID, err := strconv.Atoi(c.Param("propertyId"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+	property, ok := h.scopedProperty(c)
+	if !ok {
 		return
 	}
 
@@ -211,13 +225,16 @@ func (h *PropertyHandler) CreateBill(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	bill, err := h.propertyService.CreateBill(uint(propertyID), &req)
+	bill, err := h.propertyService.ForTenant(middleware.Tenant(c)).CreateBill(property.ID, service.CreateBillInput(req))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create bill", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to create bill", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityTaxBill, bill.ID, nil, bill) {
 		return
 	}
 
@@ -227,10 +244,9 @@ func (h *PropertyHandler) CreateBill(c *gin.Context) {
 // MakePayment - Make a payment for a tax bill
 func (h *PropertyHandler) MakePayment(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	propertyID, err := strconv.Atoi(c.Param("propertyId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid property ID", err.Error())
 		return
 	}
 
@@ -242,7 +258,7 @@ func (h *PropertyHandler) MakePayment(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -264,93 +280,115 @@ func (h *PropertyHandler) MakePayment(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership
-	if role != "admin" {
-		property, err := h.propertyService.GetProperty(uint(propertyID))
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusNotFound, "Property not found", err.Error())
-			return
-		}
-		if property.OwnerID != userID {
-			utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to make payment for this property")
-			return
-		}
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetProperty(uint(propertyID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePropertyNotFound, "Property not found", err.Error())
+		return
+	}
+	if !canAccessProperty(c, property, userID, models.PermPaymentsRecord) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to make payment for this property")
+		return
 	}
 
-	payment, err := h.propertyService.MakePayment(req.BillID, req.Amount, req.PaymentMethod, req.TransactionID)
+	payment, err := h.propertyService.ForTenant(middleware.Tenant(c)).MakePayment(property.ID, userID, service.PaymentInput{
+		BillID:        req.BillID,
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		TransactionID: req.TransactionID,
+	})
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Payment failed", err.Error())
+		serviceErrorResponse(c, http.StatusBadRequest, "Payment failed", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityPayment, payment.ID, nil, payment) {
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Payment successful", payment)
 }
 
+// paymentListOptions are the sort orders GetPaymentHistory accepts. The
+// payment history can grow large, so it also supports ?cursor= pages, which
+// are always newest first.
+var paymentListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"paid_at": "paid_at",
+		"amount":  "amount",
+	},
+	DefaultSort: "-paid_at",
+	Keyset:      true,
+}
+
 // GetPaymentHistory - Get payment history
 func (h *PropertyHandler) GetPaymentHistory(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	propertyID := c.Query("property_id")
-	status := c.Query("status")
+	list, err := utils.ParseListQuery(c, paymentListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
 
-	var payments interface{}
-	var total int64
-	var err error
+	filters := map[string]interface{}{}
+	scope := middleware.Scope(c)
 
-	if role == "admin" {
-		filters := map[string]interface{}{}
-		if propertyID != "" {
+	// Staff see payments in their jurisdiction; citizens only those made on
+	// their own properties
+	if middleware.HasPermission(c, models.PermPaymentsViewAll) {
+		if propertyID := c.Query("property_id"); propertyID != "" {
 			filters["property_id"] = propertyID
 		}
-		if status != "" {
+		if status := c.Query("status"); status != "" {
 			filters["status"] = status
 		}
-		payments, total, err = h.propertyService.GetAllPayments(page, limit, filters)
 	} else {
-		payments, total, err = h.propertyService.GetUserPayments(userID, page, limit)
+		filters["owner_id"] = userID
+		scope = models.JurisdictionScope{All: true}
 	}
 
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch payment history", err.Error())
+	propertyService := h.propertyService.ForTenant(middleware.Tenant(c))
+	if list.Cursor != nil {
+		payments, next, err := propertyService.GetPaymentsAfter(*list.Cursor, list.Limit, filters, scope)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch payment history", err.Error())
+			return
+		}
+
+		pagination := utils.CursorPagination{Limit: list.Limit, HasMore: next != nil}
+		if next != nil {
+			pagination.NextCursor = utils.EncodeCursor(*next)
+		}
+		utils.CursorSuccessResponse(c, http.StatusOK, "Payment history retrieved successfully", payments, pagination)
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
+	payments, total, err := propertyService.GetAllPayments(list, filters, scope)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch payment history", err.Error())
+		return
 	}
 
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Payment history retrieved successfully", payments, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Payment history retrieved successfully", payments, list.Pagination(total))
 }
 
 // GetPayment - Get single payment details
 func (h *PropertyHandler) GetPayment(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	paymentID, err := strconv.Atoi(c.Param("paymentId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid payment ID", err.Error())
 		return
 	}
 
-	payment, err := h.propertyService.GetPayment(uint(paymentID))
+	payment, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetPayment(uint(paymentID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Payment not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePaymentNotFound, "Payment not found", err.Error())
 		return
 	}
 
-	// Verify ownership
-	if role != "admin" {
-		// Check if payment belongs to user's property
-		if payment.Bill.Property.OwnerID != userID {
-			utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to view this payment")
-			return
-		}
+	if !canAccessPayment(c, payment, userID) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "You don't have permission to view this payment")
+		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Payment details retrieved successfully", payment)
@@ -360,7 +398,7 @@ func (h *PropertyHandler) GetPayment(c *gin.Context) {
 func (h *PropertyHandler) GetDueBills(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	bills, err := h.propertyService.GetUserDueBills(userID)
+	bills, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetUserDueBills(userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch due bills", err.Error())
 		return
@@ -371,7 +409,7 @@ func (h *PropertyHandler) GetDueBills(c *gin.Context) {
 
 // GetPropertyStats - Get property statistics (Admin only)
 func (h *PropertyHandler) GetPropertyStats(c *gin.Context) {
-	stats, err := h.propertyService.GetPropertyStats()
+	stats, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetPropertyStats()
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch statistics", err.Error())
 		return
@@ -386,7 +424,7 @@ func (h *PropertyHandler) GetRevenueReport(c *gin.Context) {
 	endDate := c.Query("end_date")
 	groupBy := c.DefaultQuery("group_by", "month") // month, quarter, year
 
-	report, err := h.propertyService.GetRevenueReport(startDate, endDate, groupBy)
+	report, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetRevenueReport(startDate, endDate, groupBy)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate report", err.Error())
 		return
@@ -398,50 +436,79 @@ func (h *PropertyHandler) GetRevenueReport(c *gin.Context) {
 // DownloadReceipt - Download payment receipt
 func (h *PropertyHandler) DownloadReceipt(c *gin.Context) {
 	userID := c.GetUint("userID")
-	role := c.GetString("role")
 	paymentID, err := strconv.Atoi(c.Param("paymentId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid payment ID", err.Error())
 		return
 	}
 
-	// Verify ownership
-	if role != "admin" {
-		payment, err := h.propertyService.GetPayment(uint(paymentID))
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusNotFound, "Payment not found", err.Error())
-			return
-		}
-		if payment.Bill.Property.OwnerID != userID {
-			utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "")
-			return
-		}
+	payment, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetPayment(uint(paymentID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePaymentNotFound, "Payment not found", err.Error())
+		return
+	}
+	if !canAccessPayment(c, payment, userID) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Access denied", "")
+		return
 	}
 
-	// Generate receipt PDF (implementation depends on your PDF library)
-	receiptURL, err := h.propertyService.GenerateReceipt(uint(paymentID))
+	receipt, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetReceipt(payment.ID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate receipt", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Receipt generated successfully", gin.H{
-		"receipt_url": receiptURL,
-	})
+	utils.SuccessResponse(c, http.StatusOK, "Receipt generated successfully", receipt)
 }
 
 // SendPaymentReminder - Send payment reminder for due bills (Admin only)
 func (h *PropertyHandler) SendPaymentReminder(c *gin.Context) {
-	propertyID, err := strconv.Atoi(c.Param("propertyId"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid property ID", err.Error())
+	property, ok := h.scopedProperty(c)
+	if !ok {
 		return
 	}
 
-	if err := h.propertyService.SendPaymentReminder(uint(propertyID)); err != nil {
+	if err := h.propertyService.ForTenant(middleware.Tenant(c)).SendPaymentReminder(property.ID); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send reminder", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Payment reminder sent successfully", nil)
-}
\ No newline at end of file
+}
+
+// scopedProperty loads the property named in the path for a staff action,
+// answering 404 when it does not exist or lies outside the staff member's
+// jurisdiction
+func (h *PropertyHandler) scopedProperty(c *gin.Context) (*models.Property, bool) {
+	propertyID, err := strconv.Atoi(c.Param("propertyId"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid property ID", err.Error())
+		return nil, false
+	}
+
+	property, err := h.propertyService.ForTenant(middleware.Tenant(c)).GetProperty(uint(propertyID))
+	if err == nil && !middleware.Scope(c).Allows(property.Village, property.Taluka) {
+		err = service.ErrPropertyNotFound
+	}
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePropertyNotFound, "Property not found", err.Error())
+		return nil, false
+	}
+	return property, true
+}
+
+// canAccessProperty allows the owner, and staff holding the permission whose
+// jurisdiction covers the property
+func canAccessProperty(c *gin.Context, property *models.Property, userID uint, permission string) bool {
+	if property.OwnerID == userID {
+		return true
+	}
+	return middleware.HasPermission(c, permission) && middleware.Scope(c).Allows(property.Village, property.Taluka)
+}
+
+func canAccessPayment(c *gin.Context, payment *models.Payment, userID uint) bool {
+	if payment.Bill == nil || payment.Bill.Property == nil {
+		return false
+	}
+	return canAccessProperty(c, payment.Bill.Property, userID, models.PermPaymentsViewAll)
+}
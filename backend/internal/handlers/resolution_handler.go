@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResolutionHandler struct {
+	resolutionService *service.ResolutionService
+	auditService      *service.AuditService
+}
+
+func NewResolutionHandler(resolutionService *service.ResolutionService, auditService *service.AuditService) *ResolutionHandler {
+	return &ResolutionHandler{
+		resolutionService: resolutionService,
+		auditService:      auditService,
+	}
+}
+
+type CreateResolutionRequest struct {
+	Title      string `json:"title" binding:"required"`
+	Text       string `json:"text" binding:"required"`
+	MoverID    uint   `json:"mover_id" binding:"required"`
+	SeconderID uint   `json:"seconder_id" binding:"required"`
+}
+
+// resolutionListOptions are the sort orders GetResolutions accepts
+var resolutionListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"financial_year": "financial_year",
+		"number":         "number",
+		"created_at":     "created_at",
+		"status":         "status",
+	},
+	DefaultSort: "-financial_year,-number",
+}
+
+// GetResolutions - Search resolutions across meetings
+func (h *ResolutionHandler) GetResolutions(c *gin.Context) {
+	list, err := utils.ParseListQuery(c, resolutionListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if financialYear := c.Query("financial_year"); financialYear != "" {
+		filters["financial_year"] = financialYear
+	}
+	if number := c.Query("number"); number != "" {
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid resolution number", err.Error())
+			return
+		}
+		filters["number"] = n
+	}
+	if meetingID := c.Query("meeting_id"); meetingID != "" {
+		id, err := strconv.Atoi(meetingID)
+		if err != nil {
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+			return
+		}
+		filters["meeting_id"] = uint(id)
+	}
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+
+	resolutions, total, err := h.resolutionService.ForTenant(middleware.Tenant(c)).GetResolutions(list, filters)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch resolutions", err.Error())
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Resolutions retrieved", resolutions, list.Pagination(total))
+}
+
+// GetResolution - Get a resolution with its votes
+func (h *ResolutionHandler) GetResolution(c *gin.Context) {
+	resolutionID, err := strconv.Atoi(c.Param("resolutionId"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid resolution ID", err.Error())
+		return
+	}
+
+	resolution, err := h.resolutionService.ForTenant(middleware.Tenant(c)).GetResolution(uint(resolutionID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeResolutionNotFound, "Resolution not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Resolution retrieved", resolution)
+}
+
+// CreateResolution - Record a resolution moved in a meeting (Admin)
+func (h *ResolutionHandler) CreateResolution(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	meetingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid meeting ID", err.Error())
+		return
+	}
+
+	var req CreateResolutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	resolution, err := h.resolutionService.ForTenant(middleware.Tenant(c)).CreateResolution(uint(meetingID), adminID, service.CreateResolutionInput{
+		Title:      req.Title,
+		Text:       req.Text,
+		MoverID:    req.MoverID,
+		SeconderID: req.SeconderID,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create resolution", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityResolution, resolution.ID, nil, resolution) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Resolution recorded", resolution)
+}
+
+// RecordVotes - Record the vote of each attending member (Admin)
+func (h *ResolutionHandler) RecordVotes(c *gin.Context) {
+	resolutionID, err := strconv.Atoi(c.Param("resolutionId"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid resolution ID", err.Error())
+		return
+	}
+
+	var req struct {
+		Votes []struct {
+			UserID uint   `json:"user_id" binding:"required"`
+			Vote   string `json:"vote" binding:"required"` // for, against, abstain
+		} `json:"votes" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	votes := make([]service.VoteInput, 0, len(req.Votes))
+	for _, v := range req.Votes {
+		votes = append(votes, service.VoteInput{UserID: v.UserID, Vote: v.Vote})
+	}
+
+	before, err := h.resolutionService.ForTenant(middleware.Tenant(c)).GetResolution(uint(resolutionID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeResolutionNotFound, "Resolution not found", err.Error())
+		return
+	}
+
+	resolution, err := h.resolutionService.ForTenant(middleware.Tenant(c)).RecordVotes(uint(resolutionID), votes)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to record votes", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityResolution, resolution.ID, before, resolution) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Votes recorded", resolution)
+}
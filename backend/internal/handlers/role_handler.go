@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService  *service.RoleService
+	auditService *service.AuditService
+}
+
+func NewRoleHandler(roleService *service.RoleService, auditService *service.AuditService) *RoleHandler {
+	return &RoleHandler{
+		roleService:  roleService,
+		auditService: auditService,
+	}
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// GetPermissions - List every permission a role can grant (Admin)
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Permissions retrieved successfully", h.roleService.GetPermissionCatalog())
+}
+
+// GetRoles - List roles with their permissions (Admin)
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.ForTenant(middleware.Tenant(c)).GetRoles()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch roles", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Roles retrieved successfully", roles)
+}
+
+// CreateRole - Create a role (Admin)
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	role, err := h.roleService.ForTenant(middleware.Tenant(c)).CreateRole(service.RoleInput(req))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create role", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityRole, role.ID, nil, role) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Role created successfully", role)
+}
+
+// UpdateRole - Change a role's description and permissions (Admin)
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	before, err := h.roleService.ForTenant(middleware.Tenant(c)).GetRole(c.Param("name"))
+	if err != nil {
+		serviceErrorResponse(c, roleErrorStatus(err), "Failed to update role", err)
+		return
+	}
+
+	role, err := h.roleService.ForTenant(middleware.Tenant(c)).UpdateRole(c.Param("name"), service.RoleInput(req))
+	if err != nil {
+		serviceErrorResponse(c, roleErrorStatus(err), "Failed to update role", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityRole, role.ID, before, role) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", role)
+}
+
+// DeleteRole - Delete an unused role (Admin)
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	before, err := h.roleService.ForTenant(middleware.Tenant(c)).GetRole(c.Param("name"))
+	if err != nil {
+		serviceErrorResponse(c, roleErrorStatus(err), "Failed to delete role", err)
+		return
+	}
+
+	if err := h.roleService.ForTenant(middleware.Tenant(c)).DeleteRole(c.Param("name")); err != nil {
+		serviceErrorResponse(c, roleErrorStatus(err), "Failed to delete role", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionDelete, models.AuditEntityRole, before.ID, before, nil) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role deleted successfully", nil)
+}
+
+func roleErrorStatus(err error) int {
+	if errors.Is(err, service.ErrRoleNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
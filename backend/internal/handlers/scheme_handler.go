@@ -24,10 +24,23 @@ type CreateSchemeRequest struct {
 	EndDate     string `json:"end_date"`
 }
 
+// schemeListOptions are the sort orders GetSchemes accepts
+var schemeListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+		"name":       "name",
+	},
+	DefaultSort: "-created_at",
+}
+
 // GetSchemes - List all active schemes
 func (h *SchemeHandler) GetSchemes(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	list, err := utils.ParseListQuery(c, schemeListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
 	category := c.Query("category")
 	isActive := c.DefaultQuery("is_active", "true")
 
@@ -38,33 +51,26 @@ func (h *SchemeHandler) GetSchemes(c *gin.Context) {
 		filters["category"] = category
 	}
 
-	schemes, total, err := h.schemeService.GetSchemes(page, limit, filters)
+	schemes, total, err := h.schemeService.GetSchemes(list, filters)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch schemes", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Schemes retrieved successfully", schemes, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Schemes retrieved successfully", schemes, list.Pagination(total))
 }
 
 // GetScheme - Get single scheme
 func (h *SchemeHandler) GetScheme(c *gin.Context) {
 	schemeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scheme ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid scheme ID", err.Error())
 		return
 	}
 
 	scheme, err := h.schemeService.GetScheme(uint(schemeID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Scheme not found", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeSchemeNotFound, "Scheme not found", err.Error())
 		return
 	}
 
@@ -75,7 +81,7 @@ func (h *SchemeHandler) GetScheme(c *gin.Context) {
 func (h *SchemeHandler) CreateScheme(c *gin.Context) {
 	var req CreateSchemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -92,13 +98,17 @@ func (h *SchemeHandler) CreateScheme(c *gin.Context) {
 func (h *SchemeHandler) UpdateScheme(c *gin.Context) {
 	schemeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scheme ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid scheme ID", err.Error())
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	var patch service.SchemePatch
+	if !bindPatch(c, &patch) {
+		return
+	}
+	updates := patch.Updates()
+	if len(updates) == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update scheme", "no updatable fields provided")
 		return
 	}
 
@@ -115,7 +125,7 @@ func (h *SchemeHandler) UpdateScheme(c *gin.Context) {
 func (h *SchemeHandler) DeleteScheme(c *gin.Context) {
 	schemeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scheme ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid scheme ID", err.Error())
 		return
 	}
 
@@ -132,7 +142,7 @@ func (h *SchemeHandler) ApplyForScheme(c *gin.Context) {
 	userID := c.GetUint("userID")
 	schemeID, err := strconv.Atoi(c.Param("schemeId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scheme ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid scheme ID", err.Error())
 		return
 	}
 
@@ -141,7 +151,7 @@ func (h *SchemeHandler) ApplyForScheme(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -172,7 +182,7 @@ func (h *SchemeHandler) UpdateSchemeApplication(c *gin.Context) {
 	adminID := c.GetUint("userID")
 	applicationID, err := strconv.Atoi(c.Param("applicationId"))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid application ID", err.Error())
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid application ID", err.Error())
 		return
 	}
 
@@ -182,7 +192,7 @@ func (h *SchemeHandler) UpdateSchemeApplication(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
@@ -1,25 +1,49 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/service"
 	"gram-panchayat/internal/utils"
 	
 	"github.com/gin-gonic/gin"
 )
 type UserHandler struct {
-	userService *service.UserService
+	userService         *service.UserService
+	jurisdictionService *service.JurisdictionService
+	auditService        *service.AuditService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, jurisdictionService *service.JurisdictionService, auditService *service.AuditService) *UserHandler {
+	return &UserHandler{
+		userService:         userService,
+		jurisdictionService: jurisdictionService,
+		auditService:        auditService,
+	}
+}
+
+// userListOptions are the sort orders GetUsers accepts
+var userListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at": "created_at",
+		"first_name": "first_name",
+		"last_name":  "last_name",
+		"village":    "village",
+	},
+	DefaultSort: "-created_at",
 }
 
 // GetUsers - List all users (Admin)
 func (h *UserHandler) GetUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	list, err := utils.ParseListQuery(c, userListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
 	role := c.Query("role")
 	isActive := c.Query("is_active")
 	search := c.Query("search")
@@ -32,33 +56,19 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		filters["is_active"] = isActive == "true"
 	}
 
-	users, total, err := h.userService.GetUsers(page, limit, filters, search)
+	users, total, err := h.userService.ForTenant(middleware.Tenant(c)).GetUsers(list, filters, search, middleware.Scope(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch users", err.Error())
 		return
 	}
 
-	pagination := utils.Pagination{
-		Page:       page,
-		Limit:      limit,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		TotalItems: total,
-	}
-
-	utils.PaginatedSuccessResponse(c, http.StatusOK, "Users retrieved successfully", users, pagination)
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Users retrieved successfully", users, list.Pagination(total))
 }
 
 // GetUser - Get single user (Admin)
 func (h *UserHandler) GetUser(c *gin.Context) {
-	userID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error())
-		return
-	}
-
-	user, err := h.userService.GetUser(uint(userID))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err.Error())
+	user, ok := h.scopedUser(c)
+	if !ok {
 		return
 	}
 
@@ -67,47 +77,48 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 // UpdateUser - Update user details (Admin)
 func (h *UserHandler) UpdateUser(c *gin.Context) {
-	userID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+	before, ok := h.scopedUser(c)
+	if !ok {
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+	// Role, status and password have their own endpoints and are rejected here
+	var patch service.UserPatch
+	if !bindPatch(c, &patch) {
 		return
 	}
 
-	// Don't allow password updates through this endpoint
-	delete(updates, "password")
-
-	user, err := h.userService.UpdateUser(uint(userID), updates)
+	user, err := h.userService.ForTenant(middleware.Tenant(c)).UpdateUser(before.ID, patch)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update user", err.Error())
 		return
 	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityUser, user.ID, before, user) {
+		return
+	}
 
 	utils.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
 }
 
 // DeleteUser - Delete user (Admin)
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	userID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+	before, ok := h.scopedUser(c)
+	if !ok {
 		return
 	}
 
 	// Prevent self-deletion
 	currentUserID := c.GetUint("userID")
-	if uint(userID) == currentUserID {
+	if before.ID == currentUserID {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Cannot delete your own account", "")
 		return
 	}
 
-	if err := h.userService.DeleteUser(uint(userID)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete user", err.Error())
+	if err := h.userService.ForTenant(middleware.Tenant(c)).DeleteUser(c.GetString("role"), before.ID); err != nil {
+		serviceErrorResponse(c, userErrorStatus(err), "Failed to delete user", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionDelete, models.AuditEntityUser, before.ID, before, nil) {
 		return
 	}
 
@@ -116,19 +127,26 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // ActivateDeactivateUser - Activate or deactivate user account (Admin)
 func (h *UserHandler) ActivateDeactivateUser(c *gin.Context) {
-	userID, _ := strconv.Atoi(c.Param("id"))
+	before, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		IsActive bool `json:"is_active"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	if err := h.userService.SetUserActiveStatus(uint(userID), req.IsActive); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update user status", err.Error())
+	if err := h.userService.ForTenant(middleware.Tenant(c)).SetUserActiveStatus(c.GetString("role"), before.ID, req.IsActive); err != nil {
+		serviceErrorResponse(c, userErrorStatus(err), "Failed to update user status", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityUser, before.ID,
+		map[string]interface{}{"is_active": before.IsActive}, map[string]interface{}{"is_active": req.IsActive}) {
 		return
 	}
 
@@ -142,30 +160,26 @@ func (h *UserHandler) ActivateDeactivateUser(c *gin.Context) {
 
 // ChangeUserRole - Change user role (Admin)
 func (h *UserHandler) ChangeUserRole(c *gin.Context) {
-	userID, _ := strconv.Atoi(c.Param("id"))
+	before, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Role string `json:"role" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	// Validate role
-	validRoles := map[string]bool{
-		"admin":   true,
-		"citizen": true,
-		"staff":   true,
-	}
-	if !validRoles[req.Role] {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid role", "Role must be admin, citizen, or staff")
+	if err := h.userService.ForTenant(middleware.Tenant(c)).ChangeUserRole(c.GetString("role"), before.ID, req.Role); err != nil {
+		serviceErrorResponse(c, userErrorStatus(err), "Failed to change user role", err)
 		return
 	}
-
-	if err := h.userService.ChangeUserRole(uint(userID), req.Role); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to change user role", err.Error())
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityUser, before.ID,
+		map[string]interface{}{"role": before.Role}, map[string]interface{}{"role": req.Role}) {
 		return
 	}
 
@@ -174,7 +188,7 @@ func (h *UserHandler) ChangeUserRole(c *gin.Context) {
 
 // GetUserStats - Get user statistics (Admin)
 func (h *UserHandler) GetUserStats(c *gin.Context) {
-	stats, err := h.userService.GetUserStats()
+	stats, err := h.userService.ForTenant(middleware.Tenant(c)).GetUserStats()
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch statistics", err.Error())
 		return
@@ -183,11 +197,14 @@ func (h *UserHandler) GetUserStats(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Statistics retrieved successfully", stats)
 }
 
-// GetUserActivity - Get user activity log (Admin)
+// GetUserActivity - Get the changes a user made or that were made to them (Admin)
 func (h *UserHandler) GetUserActivity(c *gin.Context) {
-	userID, _ := strconv.Atoi(c.Param("id"))
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
 
-	activities, err := h.userService.GetUserActivity(uint(userID))
+	activities, err := h.auditService.ForTenant(middleware.Tenant(c)).GetUserActivity(user.ID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch activity", err.Error())
 		return
@@ -198,21 +215,158 @@ func (h *UserHandler) GetUserActivity(c *gin.Context) {
 
 // ResetUserPassword - Reset user password (Admin)
 func (h *UserHandler) ResetUserPassword(c *gin.Context) {
-	userID, _ := strconv.Atoi(c.Param("id"))
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		NewPassword string `json:"new_password" binding:"required,min=6"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	if err := h.userService.ResetPassword(uint(userID), req.NewPassword); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to reset password", err.Error())
+	if err := h.userService.ForTenant(middleware.Tenant(c)).ResetPassword(c.GetString("role"), user.ID, req.NewPassword); err != nil {
+		serviceErrorResponse(c, userErrorStatus(err), "Failed to reset password", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityUser, user.ID,
+		nil, map[string]interface{}{"password_reset": true}) {
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Password reset successfully", nil)
-}
\ No newline at end of file
+}
+
+// GetUserSessions - List the devices a user is signed in on (Admin)
+func (h *UserHandler) GetUserSessions(c *gin.Context) {
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.userService.ForTenant(middleware.Tenant(c)).GetActiveSessions(user.ID)
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeUserNotFound, "User not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeUserSession - Sign a user out of one device (Admin)
+func (h *UserHandler) RevokeUserSession(c *gin.Context) {
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
+	sessionID, err := strconv.Atoi(c.Param("sessionId"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid session ID", err.Error())
+		return
+	}
+
+	if err := h.userService.ForTenant(middleware.Tenant(c)).RevokeSession(user.ID, uint(sessionID)); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to revoke session", err.Error())
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionDelete, models.AuditEntitySession, uint(sessionID),
+		map[string]interface{}{"user_id": user.ID}, nil) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// GetUserJurisdictions - List the villages and talukas a staff member covers (Admin)
+func (h *UserHandler) GetUserJurisdictions(c *gin.Context) {
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
+
+	jurisdictions, err := h.jurisdictionService.ForTenant(middleware.Tenant(c)).GetJurisdictions(user.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch jurisdictions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Jurisdictions retrieved successfully", jurisdictions)
+}
+
+// SetUserJurisdictions - Replace the villages and talukas a staff member covers (Admin)
+func (h *UserHandler) SetUserJurisdictions(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	user, ok := h.scopedUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Jurisdictions []struct {
+			Level    string `json:"level" binding:"required,oneof=village taluka"`
+			Name     string `json:"name" binding:"required"`
+			District string `json:"district"`
+		} `json:"jurisdictions" binding:"dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	inputs := make([]service.JurisdictionInput, 0, len(req.Jurisdictions))
+	for _, j := range req.Jurisdictions {
+		inputs = append(inputs, service.JurisdictionInput(j))
+	}
+
+	before, err := h.jurisdictionService.ForTenant(middleware.Tenant(c)).GetJurisdictions(user.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch jurisdictions", err.Error())
+		return
+	}
+
+	jurisdictions, err := h.jurisdictionService.ForTenant(middleware.Tenant(c)).SetJurisdictions(adminID, middleware.Scope(c), user.ID, inputs)
+	if err != nil {
+		serviceErrorResponse(c, userErrorStatus(err), "Failed to update jurisdictions", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityJurisdiction, user.ID, before, jurisdictions) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Jurisdictions updated successfully", jurisdictions)
+}
+
+// scopedUser loads the user named in the path, answering 404 when they do
+// not exist or live outside the staff member's jurisdiction
+func (h *UserHandler) scopedUser(c *gin.Context) (*models.User, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid user ID", err.Error())
+		return nil, false
+	}
+
+	user, err := h.userService.ForTenant(middleware.Tenant(c)).GetUser(uint(userID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeUserNotFound, "User not found", err.Error())
+		return nil, false
+	}
+	if !middleware.Scope(c).Allows(user.Village, user.Taluka) {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeUserNotFound, "User not found", "user not found")
+		return nil, false
+	}
+	return user, true
+}
+
+// userErrorStatus maps user management errors to HTTP statuses
+func userErrorStatus(err error) int {
+	if errors.Is(err, service.ErrPermissionsNotHeld) || errors.Is(err, service.ErrOwnJurisdictions) ||
+		errors.Is(err, service.ErrJurisdictionOutOfScope) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
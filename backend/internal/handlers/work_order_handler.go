@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"gram-panchayat/internal/middleware"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkOrderHandler struct {
+	workOrderService *service.WorkOrderService
+	auditService     *service.AuditService
+}
+
+func NewWorkOrderHandler(workOrderService *service.WorkOrderService, auditService *service.AuditService) *WorkOrderHandler {
+	return &WorkOrderHandler{
+		workOrderService: workOrderService,
+		auditService:     auditService,
+	}
+}
+
+type CreateWorkOrderRequest struct {
+	Title        string     `json:"title" binding:"required,max=255"`
+	Description  string     `json:"description" binding:"max=5000"`
+	ComplaintIDs []uint     `json:"complaint_ids" binding:"required,min=1,max=20"`
+	Crew         []uint     `json:"crew" binding:"max=20"`
+	PlannedDate  *time.Time `json:"planned_date"`
+	LaborCost    float64    `json:"labor_cost" binding:"min=0"`
+	Ward         string     `json:"ward" binding:"max=100"`
+}
+
+type IssueMaterialRequest struct {
+	MaterialID uint    `json:"material_id" binding:"required"`
+	Quantity   float64 `json:"quantity" binding:"required,gt=0"`
+}
+
+type WorkOrderPhotoRequest struct {
+	Stage   string `json:"stage" binding:"required,oneof=before after"`
+	URL     string `json:"url" binding:"required,url,max=2048"`
+	Caption string `json:"caption" binding:"max=255"`
+}
+
+type CreateMaterialRequest struct {
+	Name     string  `json:"name" binding:"required,max=255"`
+	Unit     string  `json:"unit" binding:"required,max=50"`
+	UnitCost float64 `json:"unit_cost" binding:"min=0"`
+	Quantity float64 `json:"quantity" binding:"min=0"`
+}
+
+// workOrderListOptions are the sort orders GetWorkOrders accepts
+var workOrderListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"created_at":   "created_at",
+		"planned_date": "planned_date",
+		"completed_at": "completed_at",
+		"status":       "status",
+		"category":     "category",
+	},
+	DefaultSort: "-created_at",
+}
+
+// materialListOptions are the sort orders GetMaterials accepts
+var materialListOptions = utils.ListOptions{
+	Sortable: map[string]string{
+		"name":     "name",
+		"quantity": "quantity",
+	},
+	DefaultSort: "name",
+}
+
+// workOrderErrorStatus maps work order service errors to HTTP statuses
+func workOrderErrorStatus(err error) int {
+	if errors.Is(err, service.ErrWorkOrderNotFound) || errors.Is(err, service.ErrMaterialNotFound) ||
+		errors.Is(err, service.ErrComplaintNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// CreateWorkOrder - Plan the field work for one or more complaints (Admin)
+func (h *WorkOrderHandler) CreateWorkOrder(c *gin.Context) {
+	var req CreateWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	workOrder, err := h.workOrderService.ForTenant(middleware.Tenant(c)).CreateWorkOrder(c.GetUint("userID"),
+		service.CreateWorkOrderInput(req), middleware.Scope(c))
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to create work order", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityWorkOrder, workOrder.ID, nil, workOrder) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Work order created successfully", workOrder)
+}
+
+// GetWorkOrders - List work orders, filterable by status, category and ward (Admin)
+func (h *WorkOrderHandler) GetWorkOrders(c *gin.Context) {
+	list, err := utils.ParseListQuery(c, workOrderListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	filters := map[string]interface{}{}
+	for _, key := range []string{"status", "category", "ward"} {
+		if value := c.Query(key); value != "" {
+			filters[key] = value
+		}
+	}
+
+	workOrders, total, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetWorkOrders(list, filters, middleware.Scope(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch work orders", err.Error())
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Work orders retrieved successfully", workOrders, list.Pagination(total))
+}
+
+// GetWorkOrder - Get a work order with its complaints, materials and photos (Admin)
+func (h *WorkOrderHandler) GetWorkOrder(c *gin.Context) {
+	workOrder, ok := h.scopedWorkOrder(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Work order retrieved successfully", workOrder)
+}
+
+// UpdateWorkOrder - Edit, start or cancel a work order (Admin)
+func (h *WorkOrderHandler) UpdateWorkOrder(c *gin.Context) {
+	before, ok := h.scopedWorkOrder(c)
+	if !ok {
+		return
+	}
+
+	var patch service.WorkOrderPatch
+	if !bindPatch(c, &patch) {
+		return
+	}
+
+	workOrder, err := h.workOrderService.ForTenant(middleware.Tenant(c)).UpdateWorkOrder(before.ID, c.GetUint("userID"), patch)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to update work order", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityWorkOrder, workOrder.ID, before, workOrder) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Work order updated successfully", workOrder)
+}
+
+// CompleteWorkOrder - Complete a work order and resolve its complaints (Admin)
+func (h *WorkOrderHandler) CompleteWorkOrder(c *gin.Context) {
+	before, ok := h.scopedWorkOrder(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Note string `json:"note" binding:"required,max=2000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	workOrder, err := h.workOrderService.ForTenant(middleware.Tenant(c)).CompleteWorkOrder(before.ID, c.GetUint("userID"), req.Note)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to complete work order", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityWorkOrder, workOrder.ID, before, workOrder) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Work order completed successfully", workOrder)
+}
+
+// IssueMaterial - Draw material from stock for a work order (Admin)
+func (h *WorkOrderHandler) IssueMaterial(c *gin.Context) {
+	workOrder, ok := h.scopedWorkOrder(c)
+	if !ok {
+		return
+	}
+
+	var req IssueMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	line, err := h.workOrderService.ForTenant(middleware.Tenant(c)).IssueMaterial(workOrder.ID, c.GetUint("userID"), req.MaterialID, req.Quantity)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to issue material", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityWorkOrder, workOrder.ID, nil, line) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Material issued successfully", line)
+}
+
+// AddWorkOrderPhoto - Add a before or after photo of the site (Admin)
+func (h *WorkOrderHandler) AddWorkOrderPhoto(c *gin.Context) {
+	workOrder, ok := h.scopedWorkOrder(c)
+	if !ok {
+		return
+	}
+
+	var req WorkOrderPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	photo, err := h.workOrderService.ForTenant(middleware.Tenant(c)).AddPhoto(workOrder.ID, c.GetUint("userID"), req.Stage, req.URL, req.Caption)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to add photo", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityWorkOrder, workOrder.ID, nil, photo) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Photo added successfully", photo)
+}
+
+// GetWorkOrderCostReport - Cost of completed work orders per category and per ward (Admin)
+func (h *WorkOrderHandler) GetWorkOrderCostReport(c *gin.Context) {
+	report, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetCostReport(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to generate report", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Work order cost report generated successfully", report)
+}
+
+// GetMaterials - List materials in stock (Admin)
+func (h *WorkOrderHandler) GetMaterials(c *gin.Context) {
+	list, err := utils.ParseListQuery(c, materialListOptions)
+	if err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	materials, total, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetMaterials(list, c.Query("search"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch materials", err.Error())
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, http.StatusOK, "Materials retrieved successfully", materials, list.Pagination(total))
+}
+
+// CreateMaterial - Add a material to stock (Admin)
+func (h *WorkOrderHandler) CreateMaterial(c *gin.Context) {
+	var req CreateMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	material, err := h.workOrderService.ForTenant(middleware.Tenant(c)).CreateMaterial(service.MaterialInput(req))
+	if err != nil {
+		serviceErrorResponse(c, http.StatusBadRequest, "Failed to create material", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionCreate, models.AuditEntityMaterial, material.ID, nil, material) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Material created successfully", material)
+}
+
+// UpdateMaterial - Edit a material's name, unit or unit cost (Admin)
+func (h *WorkOrderHandler) UpdateMaterial(c *gin.Context) {
+	materialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid material ID", err.Error())
+		return
+	}
+
+	var patch service.MaterialPatch
+	if !bindPatch(c, &patch) {
+		return
+	}
+
+	before, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetMaterial(uint(materialID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMaterialNotFound, "Material not found", err.Error())
+		return
+	}
+
+	material, err := h.workOrderService.ForTenant(middleware.Tenant(c)).UpdateMaterial(before.ID, patch)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to update material", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityMaterial, material.ID, before, material) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Material updated successfully", material)
+}
+
+// RestockMaterial - Add a delivery to a material's stock (Admin)
+func (h *WorkOrderHandler) RestockMaterial(c *gin.Context) {
+	materialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid material ID", err.Error())
+		return
+	}
+
+	var req struct {
+		Quantity float64 `json:"quantity" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	before, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetMaterial(uint(materialID))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodeMaterialNotFound, "Material not found", err.Error())
+		return
+	}
+
+	material, err := h.workOrderService.ForTenant(middleware.Tenant(c)).RestockMaterial(before.ID, req.Quantity)
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Failed to restock material", err)
+		return
+	}
+	if !recordAudit(c, h.auditService, models.AuditActionUpdate, models.AuditEntityMaterial, material.ID, before, material) {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Material restocked successfully", material)
+}
+
+// scopedWorkOrder loads the work order named in the path, answering 404 when
+// it does not exist or lies outside the staff member's jurisdiction
+func (h *WorkOrderHandler) scopedWorkOrder(c *gin.Context) (*models.WorkOrder, bool) {
+	workOrderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeInvalidID, "Invalid work order ID", err.Error())
+		return nil, false
+	}
+
+	workOrder, err := h.workOrderService.ForTenant(middleware.Tenant(c)).GetWorkOrder(uint(workOrderID))
+	if err == nil && !middleware.Scope(c).Allows(workOrder.Village, workOrder.Taluka) {
+		err = service.ErrWorkOrderNotFound
+	}
+	if err != nil {
+		serviceErrorResponse(c, workOrderErrorStatus(err), "Work order not found", err)
+		return nil, false
+	}
+	return workOrder, true
+}
@@ -1,6 +1,59 @@
 package middleware
 
-// TODO: restore original contents of auth_middleware.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"net/http"
+	"strings"
 
+	"github.com/gin-gonic/gin"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+)
+
+// AuthMiddleware validates the bearer access token and sets "userID", "role",
+// "permissions" and "sessionID" on the context. The user is loaded on every
+// request so that deactivated accounts and role changes take effect before the
+// token expires.
+// Tokens only authenticate against the panchayat the user belongs to, so it
+// must run after TenantMiddleware.
+func AuthMiddleware(authService *service.AuthService, roleService *service.RoleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			abortUnauthorized(c, utils.CodeTokenRequired, "Authorization token required")
+			return
+		}
+
+		user, session, err := authService.ForTenant(Tenant(c)).Authenticate(token)
+		if err != nil {
+			switch {
+			case errors.Is(err, utils.ErrExpiredToken):
+				abortUnauthorized(c, utils.CodeTokenExpired, "Token has expired")
+			case errors.Is(err, service.ErrSessionRevoked):
+				abortUnauthorized(c, utils.CodeSessionRevoked, "Session has been signed out")
+			case errors.Is(err, service.ErrAccountDeactivated):
+				utils.ErrorResponseWithCode(c, http.StatusForbidden, utils.CodeAccountDeactivated, "Account is deactivated", "")
+			default:
+				abortUnauthorized(c, utils.CodeTokenInvalid, "Invalid token")
+			}
+			return
+		}
+
+		permissions, err := roleService.ForTenant(Tenant(c)).Permissions(user.Role)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load permissions", err.Error())
+			return
+		}
+
+		c.Set("userID", user.ID)
+		c.Set("role", user.Role)
+		c.Set("permissions", permissions)
+		c.Set("sessionID", session.ID)
+		c.Next()
+	}
+}
+
+func abortUnauthorized(c *gin.Context, code, message string) {
+	utils.ErrorResponseWithCode(c, http.StatusUnauthorized, code, message, "")
+}
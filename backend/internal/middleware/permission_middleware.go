@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gram-panchayat/internal/utils"
+)
+
+// RequirePermission allows the request only if the authenticated user's role
+// grants every given permission. It must run after AuthMiddleware.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, permission := range permissions {
+			if !HasPermission(c, permission) {
+				utils.ErrorResponseWithCode(c, http.StatusForbidden, utils.CodePermissionDenied, "Access denied", "Missing permission "+permission)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// HasPermission reports whether the authenticated user holds a permission,
+// for handlers that widen what a citizen can see rather than deny access.
+func HasPermission(c *gin.Context, permission string) bool {
+	granted, ok := c.Get("permissions")
+	if !ok {
+		return false
+	}
+	permissions, ok := granted.(map[string]bool)
+	return ok && permissions[permission]
+}
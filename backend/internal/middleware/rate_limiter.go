@@ -1,6 +1,72 @@
 package middleware
 
-// TODO: restore original contents of rate_limiter.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"gram-panchayat/internal/utils"
+)
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// rateLimiter counts requests per key in fixed windows
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*rateWindow
+}
+
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	// Drop finished windows once the map grows, to bound memory
+	if len(l.windows) > 10000 {
+		for k, w := range l.windows {
+			if now.After(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.After(w.resetAt) {
+		w = &rateWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// RateLimit allows at most limit requests per client IP within each window.
+// Each call creates its own counter, so limits apply per route group.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: map[string]*rateWindow{},
+	}
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.allow(c.ClientIP())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many requests, please try again later", "")
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,6 +0,0 @@
-package middleware
-
-// TODO: restore original contents of role_middleware.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
-
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+)
+
+// ScopeMiddleware sets "scope" to the villages and talukas the authenticated
+// user may see. It must run after AuthMiddleware.
+func ScopeMiddleware(jurisdictionService *service.JurisdictionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, _ := c.Get("permissions")
+		granted, _ := permissions.(map[string]bool)
+
+		scope, err := jurisdictionService.ForTenant(Tenant(c)).ScopeFor(c.GetUint("userID"), granted)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load jurisdictions", err.Error())
+			return
+		}
+
+		c.Set("scope", scope)
+		c.Next()
+	}
+}
+
+// Scope returns the authenticated user's jurisdiction scope. Without
+// ScopeMiddleware it is the empty scope, which allows nothing.
+func Scope(c *gin.Context) models.JurisdictionScope {
+	value, _ := c.Get("scope")
+	scope, _ := value.(models.JurisdictionScope)
+	return scope
+}
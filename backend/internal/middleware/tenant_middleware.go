@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/service"
+	"gram-panchayat/internal/utils"
+)
+
+// TenantHeader selects a panchayat by slug when the API is not reached
+// through the panchayat's subdomain
+const TenantHeader = "X-Panchayat"
+
+// TenantMiddleware resolves the panchayat a request belongs to and sets
+// "tenant". The X-Panchayat header wins over the subdomain of baseDomain;
+// defaultSlug is used when neither is present.
+func TenantMiddleware(panchayatService *service.PanchayatService, baseDomain, defaultSlug string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.GetHeader(TenantHeader)
+		if slug == "" {
+			slug = subdomainOf(c.Request.Host, baseDomain)
+		}
+		if slug == "" {
+			slug = defaultSlug
+		}
+		if slug == "" {
+			abortTenantNotFound(c)
+			return
+		}
+
+		tenant, err := panchayatService.Resolve(slug)
+		if err != nil {
+			if errors.Is(err, service.ErrPanchayatNotFound) {
+				abortTenantNotFound(c)
+				return
+			}
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load panchayat", err.Error())
+			return
+		}
+
+		c.Set("tenant", tenant)
+		c.Next()
+	}
+}
+
+// Tenant returns the panchayat resolved by TenantMiddleware
+func Tenant(c *gin.Context) *models.Panchayat {
+	value, _ := c.Get("tenant")
+	tenant, _ := value.(*models.Panchayat)
+	return tenant
+}
+
+// subdomainOf returns "nandgaon" for host "nandgaon.example.gov.in" and base
+// domain "example.gov.in", and "" for the base domain itself or other hosts
+func subdomainOf(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	prefix, found := strings.CutSuffix(host, "."+strings.ToLower(baseDomain))
+	if !found || prefix == "" || strings.Contains(prefix, ".") {
+		return ""
+	}
+	return prefix
+}
+
+func abortTenantNotFound(c *gin.Context) {
+	utils.ErrorResponseWithCode(c, http.StatusNotFound, utils.CodePanchayatNotFound, "Panchayat not found", "")
+}
@@ -2,12 +2,306 @@ package models
 
 import "time"
 
-// Application is a minimal model used by user relations.
-// Extend fields as needed (status, attachments, etc).
+// Application statuses. A submitted application waits at its workflow's
+// first stage; it is under review once a stage has passed it on, and
+// returned while it waits for the applicant's corrections.
+const (
+	ApplicationStatusSubmitted   = "submitted"
+	ApplicationStatusUnderReview = "under_review"
+	ApplicationStatusReturned    = "returned"
+	ApplicationStatusApproved    = "approved"
+	ApplicationStatusRejected    = "rejected"
+)
+
+// Workflow actions. Reviewers approve, reject, return an application to the
+// applicant or send it back to the previous stage; applicants submit and
+// resubmit.
+const (
+	ApplicationActionSubmit   = "submit"
+	ApplicationActionApprove  = "approve"
+	ApplicationActionReject   = "reject"
+	ApplicationActionReturn   = "return"
+	ApplicationActionSendBack = "send_back"
+	ApplicationActionResubmit = "resubmit"
+)
+
+// ApplicationReviewActions are the actions a workflow stage may allow
+var ApplicationReviewActions = []string{
+	ApplicationActionApprove,
+	ApplicationActionReject,
+	ApplicationActionReturn,
+	ApplicationActionSendBack,
+}
+
+// Application priorities; urgent is the paid fast-track (tatkal) service
+const (
+	ApplicationPriorityNormal = "normal"
+	ApplicationPriorityUrgent = "urgent"
+)
+
+// Application is a citizen's request for a certificate or service. FormData
+// holds the answers to its type's form, as validated when it was submitted.
 type Application struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `json:"user_id"`
-	Title     string    `json:"title,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint                   `gorm:"primaryKey" json:"id"`
+	PanchayatID uint                   `gorm:"index" json:"panchayat_id"`
+	UserID      uint                   `gorm:"index" json:"user_id"` // the applicant
+	Type        string                 `gorm:"index" json:"type"`    // an ApplicationType code, e.g. birth
+	Title       string                 `json:"title,omitempty"`      // the type's name when it was submitted
+	FormData    map[string]interface{} `gorm:"serializer:json;type:jsonb" json:"form_data"`
+	FormVersion int                    `gorm:"not null;default:1" json:"form_version"` // of the type's form
+	Priority    string                 `gorm:"index;not null;default:'normal'" json:"priority"`
+	Status      string                 `gorm:"index;not null;default:'submitted'" json:"status"`
+	Remarks     string                 `json:"remarks,omitempty"`
+	ReviewedBy  *uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time             `json:"reviewed_at,omitempty"`
+	Village     string                 `gorm:"index" json:"village"` // from the applicant; decides which staff can see it
+	Taluka      string                 `gorm:"index" json:"taluka"`
+	Workflow    []WorkflowStage        `gorm:"serializer:json;type:jsonb" json:"workflow"` // the type's stages when it was submitted
+	StageIndex  int                    `gorm:"not null;default:0" json:"stage_index"`
+	StageRole   string                 `gorm:"index" json:"stage_role,omitempty"` // role that acts next; empty once decided or returned
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+
+	History []ApplicationAction `gorm:"foreignKey:ApplicationID" json:"history,omitempty"`
+}
+
+// CurrentStage returns the workflow stage the application is at, or nil if
+// it has no workflow
+func (a *Application) CurrentStage() *WorkflowStage {
+	if a.StageIndex < 0 || a.StageIndex >= len(a.Workflow) {
+		return nil
+	}
+	return &a.Workflow[a.StageIndex]
+}
+
+// ApplicationAction is one step in an application's history, by the
+// applicant or a reviewer
+type ApplicationAction struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ApplicationID uint      `gorm:"index;not null" json:"application_id"`
+	Action        string    `gorm:"not null" json:"action"`
+	Stage         string    `json:"stage,omitempty"` // key of the stage acted at
+	FromStatus    string    `json:"from_status,omitempty"`
+	ToStatus      string    `json:"to_status"`
+	Remarks       string    `json:"remarks,omitempty"`
+	ActorID       uint      `json:"actor_id"`
+	ActorRole     string    `json:"actor_role"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (ApplicationAction) TableName() string {
+	return "application_actions"
+}
+
+// WorkflowStage is one step of an application type's approval workflow.
+// Only users with Role, or admins, act at the stage, and only with Actions;
+// approving the last stage approves the application.
+type WorkflowStage struct {
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Role    string   `json:"role"`
+	Actions []string `json:"actions"`
+}
+
+// Allows reports whether the stage permits an action
+func (s WorkflowStage) Allows(action string) bool {
+	for _, allowed := range s.Actions {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName overrides the table name
+func (Application) TableName() string {
+	return "applications"
+}
+
+// Form field types
+const (
+	FormTypeString  = "string"
+	FormTypeNumber  = "number"
+	FormTypeInteger = "integer"
+	FormTypeBoolean = "boolean"
+)
+
+// Form string formats
+const (
+	FormFormatDate  = "date" // YYYY-MM-DD
+	FormFormatEmail = "email"
+	FormFormatPhone = "phone" // 10-digit Indian mobile number
+)
+
+// FormSchema declares the fields of an application form. It follows the
+// shape of a JSON Schema object, limited to the keywords below.
+type FormSchema struct {
+	Required   []string             `json:"required,omitempty"`
+	Properties map[string]FormField `json:"properties"`
+}
+
+// FormField is one field of a form. Length, format, pattern and enum apply to
+// strings; minimum and maximum to numbers and integers.
+type FormField struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	MinLength   *int     `json:"minLength,omitempty"`
+	MaxLength   *int     `json:"maxLength,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"`
+	Maximum     *float64 `json:"maximum,omitempty"`
+}
+
+// ApplicationType is a kind of application a panchayat accepts, with the
+// form applicants fill in. Version goes up each time the form is edited.
+type ApplicationType struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	PanchayatID uint            `gorm:"uniqueIndex:idx_application_types_tenant_code" json:"panchayat_id"`
+	Code        string          `gorm:"not null;uniqueIndex:idx_application_types_tenant_code" json:"code"`
+	Name        string          `gorm:"not null" json:"name"`
+	Description string          `json:"description,omitempty"`
+	Form        FormSchema      `gorm:"serializer:json;type:jsonb" json:"form"`
+	Workflow    []WorkflowStage `gorm:"serializer:json;type:jsonb" json:"workflow"`
+	Version     int             `gorm:"not null;default:1" json:"version"`
+	IsActive    bool            `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ApplicationType) TableName() string {
+	return "application_types"
+}
+
+func intPtr(n int) *int { return &n }
+
+func floatPtr(f float64) *float64 { return &f }
+
+// nameField is a person's name
+func nameField(title string) FormField {
+	return FormField{Type: FormTypeString, Title: title, MinLength: intPtr(2), MaxLength: intPtr(100)}
+}
+
+// dateField is a YYYY-MM-DD date
+func dateField(title string) FormField {
+	return FormField{Type: FormTypeString, Title: title, Format: FormFormatDate}
+}
+
+// textField is free text of up to maxLength characters
+func textField(title string, maxLength int) FormField {
+	return FormField{Type: FormTypeString, Title: title, MinLength: intPtr(1), MaxLength: intPtr(maxLength)}
+}
+
+// DefaultApplicationWorkflow is the approval path of certificates unless a
+// panchayat sets its own: the clerk verifies the documents, the Gram Sevak
+// recommends and the Sarpanch approves
+var DefaultApplicationWorkflow = []WorkflowStage{
+	{
+		Key: "verification", Name: "Clerk verification", Role: RoleStaff,
+		Actions: []string{ApplicationActionApprove, ApplicationActionReject, ApplicationActionReturn},
+	},
+	{
+		Key: "recommendation", Name: "Gram Sevak recommendation", Role: RoleGramSevak,
+		Actions: []string{ApplicationActionApprove, ApplicationActionReject, ApplicationActionReturn, ApplicationActionSendBack},
+	},
+	{
+		Key: "approval", Name: "Sarpanch approval", Role: RoleSarpanch,
+		Actions: []string{ApplicationActionApprove, ApplicationActionReject, ApplicationActionReturn, ApplicationActionSendBack},
+	},
+}
+
+// DefaultApplicationTypes are offered by every panchayat until it saves its
+// own version of a type under the same code
+var DefaultApplicationTypes = []ApplicationType{
+	{
+		Code: "birth", Name: "Birth certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"child_name", "date_of_birth", "gender", "place_of_birth", "father_name", "mother_name"},
+			Properties: map[string]FormField{
+				"child_name":     nameField("Child's name"),
+				"date_of_birth":  dateField("Date of birth"),
+				"gender":         {Type: FormTypeString, Title: "Gender", Enum: []string{"male", "female", "other"}},
+				"place_of_birth": textField("Place of birth", 200),
+				"father_name":    nameField("Father's name"),
+				"mother_name":    nameField("Mother's name"),
+				"hospital_name":  textField("Hospital", 200),
+			},
+		},
+	},
+	{
+		Code: "death", Name: "Death certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"deceased_name", "date_of_death", "place_of_death", "informant_name", "relation_to_deceased"},
+			Properties: map[string]FormField{
+				"deceased_name":        nameField("Name of the deceased"),
+				"date_of_death":        dateField("Date of death"),
+				"place_of_death":       textField("Place of death", 200),
+				"cause_of_death":       textField("Cause of death", 500),
+				"informant_name":       nameField("Informant's name"),
+				"relation_to_deceased": textField("Informant's relation to the deceased", 50),
+			},
+		},
+	},
+	{
+		Code: "income", Name: "Income certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"applicant_name", "annual_income", "income_source", "purpose"},
+			Properties: map[string]FormField{
+				"applicant_name": nameField("Applicant's name"),
+				"annual_income":  {Type: FormTypeNumber, Title: "Annual family income (₹)", Minimum: floatPtr(0)},
+				"income_source":  textField("Source of income", 200),
+				"purpose":        textField("Purpose", 200),
+			},
+		},
+	},
+	{
+		Code: "caste", Name: "Caste certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"applicant_name", "father_name", "caste", "religion", "purpose"},
+			Properties: map[string]FormField{
+				"applicant_name": nameField("Applicant's name"),
+				"father_name":    nameField("Father's name"),
+				"caste":          textField("Caste", 100),
+				"sub_caste":      textField("Sub-caste", 100),
+				"religion":       textField("Religion", 50),
+				"purpose":        textField("Purpose", 200),
+			},
+		},
+	},
+	{
+		Code: "residence", Name: "Residence certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"applicant_name", "address", "residing_since", "purpose"},
+			Properties: map[string]FormField{
+				"applicant_name": nameField("Applicant's name"),
+				"address":        textField("Address", 500),
+				"residing_since": dateField("Residing at this address since"),
+				"purpose":        textField("Purpose", 200),
+			},
+		},
+	},
+	{
+		Code: "marriage", Name: "Marriage certificate", Version: 1, IsActive: true,
+		Workflow: DefaultApplicationWorkflow,
+		Form: FormSchema{
+			Required: []string{"groom_name", "groom_date_of_birth", "bride_name", "bride_date_of_birth", "date_of_marriage", "place_of_marriage"},
+			Properties: map[string]FormField{
+				"groom_name":          nameField("Groom's name"),
+				"groom_date_of_birth": dateField("Groom's date of birth"),
+				"bride_name":          nameField("Bride's name"),
+				"bride_date_of_birth": dateField("Bride's date of birth"),
+				"date_of_marriage":    dateField("Date of marriage"),
+				"place_of_marriage":   textField("Place of marriage", 200),
+			},
+		},
+	},
 }
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Audited entity types
+const (
+	AuditEntityUser            = "user"
+	AuditEntityJurisdiction    = "jurisdiction"
+	AuditEntitySession         = "session"
+	AuditEntityRole            = "role"
+	AuditEntityPanchayat       = "panchayat"
+	AuditEntityProperty        = "property"
+	AuditEntityTaxBill         = "tax_bill"
+	AuditEntityPayment         = "payment"
+	AuditEntityComplaint       = "complaint"
+	AuditEntityWorkOrder       = "work_order"
+	AuditEntityMaterial        = "material"
+	AuditEntityApplication     = "application"
+	AuditEntityApplicationType = "application_type"
+	AuditEntityMeeting         = "meeting"
+	AuditEntityResolution      = "resolution"
+	AuditEntityNotice          = "notice"
+)
+
+// AuditChange is the value of one field before and after a mutation
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLog records one mutation. Records are append-only and each one
+// carries the hash of the previous record of its panchayat, so editing or
+// removing a record breaks the chain from that point on.
+type AuditLog struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	PanchayatID uint   `gorm:"index" json:"panchayat_id"`
+	ActorID     uint   `gorm:"index" json:"actor_id"` // 0 for system actions
+	ActorRole   string `json:"actor_role"`
+	Action      string `gorm:"not null" json:"action"`
+	EntityType  string `gorm:"index:idx_audit_entity,priority:1;not null" json:"entity_type"`
+	EntityID    uint   `gorm:"index:idx_audit_entity,priority:2" json:"entity_id"`
+
+	// Changes maps field names to AuditChange. It is stored as json rather
+	// than jsonb so the hashed bytes are kept exactly.
+	Changes json.RawMessage `gorm:"type:json" json:"changes"`
+
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `gorm:"uniqueIndex;not null" json:"hash"`
+}
+
+// TableName overrides the table name
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ComputeHash returns the SHA-256 of the record's content and PrevHash.
+// CreatedAt must already be truncated to the database's precision.
+func (l *AuditLog) ComputeHash() string {
+	fields := []string{
+		l.PrevHash,
+		strconv.FormatUint(uint64(l.PanchayatID), 10),
+		strconv.FormatUint(uint64(l.ActorID), 10),
+		l.ActorRole,
+		l.Action,
+		l.EntityType,
+		strconv.FormatUint(uint64(l.EntityID), 10),
+		string(l.Changes),
+		l.IPAddress,
+		l.UserAgent,
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,13 +2,171 @@ package models
 
 import "time"
 
-// Complaint is a minimal model used by user relations.
-// Add fields as your original schema requires.
+// Complaint categories
+const (
+	ComplaintCategoryInfrastructure = "infrastructure"
+	ComplaintCategoryWater          = "water"
+	ComplaintCategoryElectricity    = "electricity"
+	ComplaintCategorySanitation     = "sanitation"
+	ComplaintCategoryRoad           = "road"
+	ComplaintCategoryStreetLight    = "street_light"
+	ComplaintCategoryGarbage        = "garbage"
+	ComplaintCategoryOther          = "other"
+)
+
+// Complaint priorities
+const (
+	ComplaintPriorityLow    = "low"
+	ComplaintPriorityMedium = "medium"
+	ComplaintPriorityHigh   = "high"
+	ComplaintPriorityUrgent = "urgent"
+)
+
+// Complaint statuses
+const (
+	ComplaintStatusOpen         = "open"
+	ComplaintStatusAcknowledged = "acknowledged"
+	ComplaintStatusInProgress   = "in_progress"
+	ComplaintStatusResolved     = "resolved"
+	ComplaintStatusClosed       = "closed"
+	ComplaintStatusReopened     = "reopened"
+)
+
 type Complaint struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID uint      `gorm:"index" json:"panchayat_id"`
+	UserID      uint      `gorm:"index" json:"user_id"` // the reporter
+	Title       string    `json:"title"`
+	Description string    `gorm:"type:text" json:"description"`
+	Category    string    `gorm:"index;not null;default:'other'" json:"category"`
+	Priority    string    `gorm:"index;not null;default:'medium'" json:"priority"`
+	Status      string    `gorm:"index;not null;default:'open'" json:"status"`
+	Location    string    `json:"location,omitempty"`
+	Latitude    *float64  `gorm:"index:idx_complaints_location,priority:1" json:"latitude,omitempty"`
+	Longitude   *float64  `gorm:"index:idx_complaints_location,priority:2" json:"longitude,omitempty"`
+	Village     string    `gorm:"index" json:"village"` // from the reporter; decides which staff can see it
+	Taluka      string    `gorm:"index" json:"taluka"`
+	Ward        string    `gorm:"index" json:"ward,omitempty"`
+	AssignedTo  *uint     `gorm:"index" json:"assigned_to,omitempty"`
+	WorkOrderID *uint     `gorm:"index" json:"work_order_id,omitempty"` // the field work fixing it
+	Resolution  string    `json:"resolution,omitempty"`                 // what was done, shown to the reporter
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Lifecycle: when the complaint last entered each status
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	ReopenedAt     *time.Time `json:"reopened_at,omitempty"`
+
+	// SLA: the complaint should be resolved by SLADueAt. Breached is set once
+	// that passes unresolved; EscalationLevel counts the escalation steps
+	// notified so far.
+	SLADueAt        *time.Time `gorm:"index" json:"sla_due_at,omitempty"`
+	Breached        bool       `gorm:"index;not null;default:false" json:"breached"`
+	EscalationLevel int        `gorm:"not null;default:0" json:"escalation_level"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"`
+
+	// Duplicates: a complaint merged into another is closed and points at
+	// it; the parent counts the reports merged into it
+	MergedInto          *uint `gorm:"index" json:"merged_into,omitempty"`
+	DuplicateCount      int   `gorm:"not null;default:0" json:"duplicate_count"`
+	PossibleDuplicateOf *uint `json:"possible_duplicate_of,omitempty"` // best match found when it was filed
+
+	// Feedback: after resolution the reporter either rates the fix or
+	// reopens the complaint, within the panchayat's reopen window.
+	// ReopenCount counts every reopen, by the reporter or by staff.
+	FeedbackRating  *int       `gorm:"index" json:"feedback_rating,omitempty"` // 1-5
+	FeedbackComment string     `json:"feedback_comment,omitempty"`
+	FeedbackAt      *time.Time `json:"feedback_at,omitempty"`
+	ReopenCount     int        `gorm:"not null;default:0" json:"reopen_count"`
+
+	// Relations
+	StatusHistory []ComplaintStatusChange `gorm:"foreignKey:ComplaintID" json:"status_history,omitempty"`
+	Comments      []ComplaintComment      `gorm:"foreignKey:ComplaintID" json:"comments,omitempty"`
+}
+
+// Complaint attachment kinds
+const (
+	AttachmentKindPhoto    = "photo"
+	AttachmentKindDocument = "document"
+)
+
+// ComplaintComment is one message in a complaint's thread. Internal notes
+// are between staff and never shown to the reporter.
+type ComplaintComment struct {
+	ID          uint                  `gorm:"primaryKey" json:"id"`
+	PanchayatID uint                  `gorm:"index" json:"panchayat_id"`
+	ComplaintID uint                  `gorm:"index;not null" json:"complaint_id"`
+	AuthorID    uint                  `gorm:"index;not null" json:"author_id"`
+	AuthorName  string                `json:"author_name"`                            // as it was when the comment was written
+	IsStaff     bool                  `gorm:"not null;default:false" json:"is_staff"` // written on behalf of the panchayat
+	Body        string                `gorm:"type:text;not null" json:"body"`
+	IsInternal  bool                  `gorm:"index;not null;default:false" json:"is_internal"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Attachments []ComplaintAttachment `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"attachments,omitempty"`
+}
+
+// ComplaintAttachment is a photo or document added to a comment, stored
+// elsewhere and referenced by URL
+type ComplaintAttachment struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `json:"user_id"`
-	Subject   string    `json:"subject,omitempty"`
-	Body      string    `json:"body,omitempty"`
+	CommentID uint      `gorm:"index;not null" json:"comment_id"`
+	Kind      string    `gorm:"not null" json:"kind"` // photo, document
+	URL       string    `gorm:"not null" json:"url"`
+	Name      string    `json:"name,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ComplaintOpenStatuses are the statuses in which a complaint still runs
+// against its SLA
+var ComplaintOpenStatuses = []string{
+	ComplaintStatusOpen,
+	ComplaintStatusAcknowledged,
+	ComplaintStatusInProgress,
+	ComplaintStatusReopened,
+}
+
+// ComplaintStatusChange records a single lifecycle transition of a complaint.
+type ComplaintStatusChange struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ComplaintID uint      `gorm:"index;not null" json:"complaint_id"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Note        string    `json:"note,omitempty"`
+	ChangedBy   uint      `json:"changed_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Complaint) TableName() string {
+	return "complaints"
+}
+
+// TableName overrides the table name
+func (ComplaintStatusChange) TableName() string {
+	return "complaint_status_changes"
+}
+
+// TableName overrides the table name
+func (ComplaintComment) TableName() string {
+	return "complaint_comments"
+}
+
+// TableName overrides the table name
+func (ComplaintAttachment) TableName() string {
+	return "complaint_attachments"
+}
+
+// HideInternalComments drops staff-only notes from a complaint about to be
+// shown to someone who is not staff
+func (c *Complaint) HideInternalComments() {
+	visible := c.Comments[:0]
+	for _, comment := range c.Comments {
+		if !comment.IsInternal {
+			visible = append(visible, comment)
+		}
+	}
+	c.Comments = visible
 }
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Jurisdiction levels
+const (
+	JurisdictionVillage = "village"
+	JurisdictionTaluka  = "taluka"
+)
+
+// StaffJurisdiction binds a staff account to a village or a whole taluka.
+// Names are matched case-insensitively against the village/taluka recorded on
+// users, properties and complaints.
+type StaffJurisdiction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_staff_jurisdiction;not null" json:"user_id"`
+	Level     string    `gorm:"uniqueIndex:idx_staff_jurisdiction;not null" json:"level"` // village, taluka
+	Name      string    `gorm:"uniqueIndex:idx_staff_jurisdiction;not null" json:"name"`
+	District  string    `json:"district"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (StaffJurisdiction) TableName() string {
+	return "staff_jurisdictions"
+}
+
+// JurisdictionScope is the set of places a staff member may see. The zero
+// value allows nothing; All lifts the restriction.
+type JurisdictionScope struct {
+	All      bool     `json:"all"`
+	Villages []string `json:"villages,omitempty"`
+	Talukas  []string `json:"talukas,omitempty"`
+}
+
+// NewJurisdictionScope builds a scope from a staff member's jurisdictions
+func NewJurisdictionScope(jurisdictions []StaffJurisdiction) JurisdictionScope {
+	var scope JurisdictionScope
+	for _, j := range jurisdictions {
+		name := strings.ToLower(strings.TrimSpace(j.Name))
+		switch j.Level {
+		case JurisdictionVillage:
+			scope.Villages = append(scope.Villages, name)
+		case JurisdictionTaluka:
+			scope.Talukas = append(scope.Talukas, name)
+		}
+	}
+	return scope
+}
+
+// Allows reports whether a record in the given village and taluka is inside
+// the scope
+func (s JurisdictionScope) Allows(village, taluka string) bool {
+	if s.All {
+		return true
+	}
+	village = strings.ToLower(strings.TrimSpace(village))
+	taluka = strings.ToLower(strings.TrimSpace(taluka))
+	for _, v := range s.Villages {
+		if v == village && village != "" {
+			return true
+		}
+	}
+	for _, t := range s.Talukas {
+		if t == taluka && taluka != "" {
+			return true
+		}
+	}
+	return false
+}
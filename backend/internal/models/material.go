@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Material is an item the panchayat keeps in stock for field work, such as
+// hand pump washers or LED street light fittings
+type Material struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID uint      `gorm:"uniqueIndex:idx_materials_tenant_name" json:"panchayat_id"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_materials_tenant_name" json:"name"`
+	Unit        string    `gorm:"not null" json:"unit"` // e.g. piece, metre, kg
+	UnitCost    float64   `gorm:"not null;default:0" json:"unit_cost"`
+	Quantity    float64   `gorm:"not null;default:0" json:"quantity"` // in stock
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Material) TableName() string {
+	return "materials"
+}
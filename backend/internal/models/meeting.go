@@ -1,6 +1,129 @@
 package models
 
-// TODO: restore original contents of meeting.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import "time"
 
+// Meeting types
+const (
+	MeetingTypeGramSabha  = "gram_sabha"
+	MeetingTypeMasikSabha = "masik_sabha"
+	MeetingTypeWardSabha  = "ward_sabha"
+	MeetingTypeSpecial    = "special"
+	MeetingTypeCommittee  = "committee"
+)
+
+// Meeting statuses
+const (
+	MeetingStatusScheduled  = "scheduled"
+	MeetingStatusInProgress = "in_progress"
+	MeetingStatusCompleted  = "completed"
+	MeetingStatusPostponed  = "postponed"
+	MeetingStatusCancelled  = "cancelled"
+)
+
+type Meeting struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID uint      `gorm:"index" json:"panchayat_id"`
+	Title       string    `gorm:"not null" json:"title"`
+	Description string    `json:"description,omitempty"`
+	MeetingType string    `gorm:"index" json:"meeting_type,omitempty"`
+	ScheduledAt time.Time `gorm:"index;not null" json:"scheduled_at"`
+	Location    string    `json:"location,omitempty"`
+	Agenda      string    `json:"agenda,omitempty"`
+	Status      string    `gorm:"index;default:'scheduled'" json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Lifecycle
+	StatusReason string     `json:"status_reason,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+
+	// Relations
+	Minutes       []MeetingMinutes      `gorm:"foreignKey:MeetingID" json:"minutes,omitempty"`
+	StatusHistory []MeetingStatusChange `gorm:"foreignKey:MeetingID" json:"status_history,omitempty"`
+}
+
+// MeetingMinutes is append-only: every edit of the minutes is stored as a
+// new row with the next revision number for the meeting.
+type MeetingMinutes struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	MeetingID uint   `gorm:"not null;uniqueIndex:idx_meeting_minutes_revision" json:"meeting_id"`
+	Revision  int    `gorm:"not null;uniqueIndex:idx_meeting_minutes_revision" json:"revision"`
+	Attendees string `json:"attendees,omitempty"`
+	// AttendeeCount is the head count noted in the minutes. It is only used
+	// for the quorum check when the meeting has no attendance register.
+	AttendeeCount int       `json:"attendee_count"`
+	Decisions     string    `json:"decisions,omitempty"`
+	RecordedBy    uint      `json:"recorded_by,omitempty"`
+	Content       string    `json:"content,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MeetingStatusChange records a single lifecycle transition of a meeting.
+type MeetingStatusChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	MeetingID  uint      `gorm:"index;not null" json:"meeting_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason,omitempty"`
+	ChangedBy  uint      `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Meeting) TableName() string {
+	return "meetings"
+}
+
+// TableName overrides the table name
+func (MeetingMinutes) TableName() string {
+	return "meeting_minutes"
+}
+
+// TableName overrides the table name
+func (MeetingStatusChange) TableName() string {
+	return "meeting_status_changes"
+}
+
+// Attendance statuses
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceExcused = "excused"
+)
+
+// MeetingAttendance is one member's entry in a meeting's attendance register.
+type MeetingAttendance struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PanchayatID uint       `gorm:"index" json:"panchayat_id"`
+	MeetingID   uint       `gorm:"not null;uniqueIndex:idx_meeting_attendance_member" json:"meeting_id"`
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_meeting_attendance_member;index" json:"user_id"`
+	Status      string     `gorm:"not null" json:"status"` // present, absent, excused
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+	Remarks     string     `json:"remarks,omitempty"`
+	RecordedBy  uint       `json:"recorded_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relations
+	Meeting *Meeting `gorm:"foreignKey:MeetingID" json:"-"`
+	User    *User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName overrides the table name
+func (MeetingAttendance) TableName() string {
+	return "meeting_attendances"
+}
+
+// MemberAttendance summarises how often a member attended meetings.
+type MemberAttendance struct {
+	UserID     uint    `json:"user_id"`
+	FirstName  string  `json:"first_name"`
+	LastName   string  `json:"last_name"`
+	Village    string  `json:"village"`
+	Meetings   int64   `json:"meetings"`
+	Present    int64   `json:"present"`
+	Absent     int64   `json:"absent"`
+	Excused    int64   `json:"excused"`
+	Percentage float64 `json:"attendance_percentage"`
+}
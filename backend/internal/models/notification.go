@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Notification is an in-app message to one user, e.g. that a complaint
+// assigned to them has breached its SLA
+type Notification struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PanchayatID uint       `gorm:"index" json:"panchayat_id"`
+	UserID      uint       `gorm:"index;not null" json:"user_id"`
+	Title       string     `json:"title"`
+	Message     string     `gorm:"type:text" json:"message"`
+	EntityType  string     `json:"entity_type,omitempty"` // what the notification is about, e.g. "complaint"
+	EntityID    uint       `json:"entity_id,omitempty"`
+	Read        bool       `gorm:"index;not null;default:false" json:"read"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Notification) TableName() string {
+	return "notifications"
+}
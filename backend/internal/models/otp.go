@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// OTP purposes
+const (
+	OTPPurposeRegistration  = "registration"
+	OTPPurposePasswordReset = "password_reset"
+	OTPPurposePhoneChange   = "phone_change"
+	OTPPurposeLogin         = "login"
+)
+
+// OTP delivery channels
+const (
+	OTPChannelEmail = "email"
+	OTPChannelSMS   = "sms"
+)
+
+// OTPCode is a one-time code sent to an email address or phone number for a
+// single purpose. Only a hash of the code is stored, and the code stops
+// working after MaxAttempts wrong guesses. A code only works in the
+// panchayat that issued it.
+type OTPCode struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PanchayatID uint       `gorm:"index" json:"panchayat_id"`
+	UserID      *uint      `gorm:"index" json:"user_id,omitempty"`
+	Purpose     string     `gorm:"not null;index:idx_otp_lookup" json:"purpose"`
+	Channel     string     `gorm:"not null" json:"channel"`
+	Destination string     `gorm:"not null;index:idx_otp_lookup" json:"destination"`
+	CodeHash    string     `gorm:"not null" json:"-"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	ConsumedAt  *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (OTPCode) TableName() string {
+	return "otp_codes"
+}
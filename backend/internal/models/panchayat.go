@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Panchayat is a tenant. Citizens, properties, notices, meetings and schemes
+// each belong to exactly one panchayat.
+type Panchayat struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Slug    string `gorm:"uniqueIndex;not null" json:"slug"` // subdomain and X-Panchayat header value
+	Name    string `gorm:"not null" json:"name"`
+	LGDCode string `gorm:"uniqueIndex:idx_panchayats_lgd_code,where:lgd_code <> ''" json:"lgd_code"` // Local Government Directory code
+	LogoURL string `json:"logo_url"`
+
+	// FinancialYearStartMonth is 1-12; Indian panchayats use April
+	FinancialYearStartMonth int `gorm:"default:4" json:"financial_year_start_month"`
+
+	// TaxRates is the annual property tax per unit of area, by property type
+	TaxRates map[string]float64 `gorm:"serializer:json;type:jsonb" json:"tax_rates"`
+
+	// ComplaintSLAHours is the resolution target per complaint category;
+	// categories left out use DefaultComplaintSLAHours
+	ComplaintSLAHours map[string]int `gorm:"serializer:json;type:jsonb" json:"complaint_sla_hours"`
+	// ComplaintEscalation is who is notified, and when, as a complaint stays
+	// unresolved past its target; empty uses DefaultComplaintEscalation
+	ComplaintEscalation []EscalationStep `gorm:"serializer:json;type:jsonb" json:"complaint_escalation"`
+	// ComplaintReopenDays is how long after resolution the reporter may rate
+	// or reopen a complaint; 0 uses DefaultComplaintReopenDays
+	ComplaintReopenDays int `gorm:"not null;default:0" json:"complaint_reopen_days"`
+
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EscalationAssignee stands for the complaint's assignee in an escalation chain
+const EscalationAssignee = "assignee"
+
+// EscalationStep notifies the holders of a role, or the assignee, once a
+// complaint has been past its SLA target for AfterHours
+type EscalationStep struct {
+	Role       string `json:"role"`
+	AfterHours int    `json:"after_hours"`
+}
+
+// DefaultComplaintSLAHours applies to categories a panchayat has not set a
+// target for
+var DefaultComplaintSLAHours = map[string]int{
+	ComplaintCategoryWater:          24,
+	ComplaintCategoryElectricity:    48,
+	ComplaintCategorySanitation:     48,
+	ComplaintCategoryGarbage:        48,
+	ComplaintCategoryStreetLight:    72,
+	ComplaintCategoryRoad:           168,
+	ComplaintCategoryInfrastructure: 168,
+	ComplaintCategoryOther:          72,
+}
+
+// DefaultComplaintEscalation goes from the assignee to the Gram Sevak after a
+// day and to the Sarpanch after two
+var DefaultComplaintEscalation = []EscalationStep{
+	{Role: EscalationAssignee, AfterHours: 0},
+	{Role: RoleGramSevak, AfterHours: 24},
+	{Role: RoleSarpanch, AfterHours: 48},
+}
+
+// DefaultComplaintReopenDays is the reopen window of panchayats that have not
+// set their own
+const DefaultComplaintReopenDays = 7
+
+// TableName overrides the table name
+func (Panchayat) TableName() string {
+	return "panchayats"
+}
+
+// FinancialYearOf returns the panchayat's financial year containing t, e.g.
+// "2024-25"
+func (p *Panchayat) FinancialYearOf(t time.Time) string {
+	startMonth := time.April
+	if p != nil && p.FinancialYearStartMonth >= 1 && p.FinancialYearStartMonth <= 12 {
+		startMonth = time.Month(p.FinancialYearStartMonth)
+	}
+
+	start := t.Year()
+	if t.Month() < startMonth {
+		start--
+	}
+	if startMonth == time.January {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// ComplaintSLATarget returns how long the panchayat allows for resolving a
+// complaint of a category
+func (p *Panchayat) ComplaintSLATarget(category string) time.Duration {
+	hours, ok := 0, false
+	if p != nil {
+		hours, ok = p.ComplaintSLAHours[category]
+	}
+	if !ok || hours <= 0 {
+		hours = DefaultComplaintSLAHours[category]
+	}
+	if hours <= 0 {
+		hours = DefaultComplaintSLAHours[ComplaintCategoryOther]
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// ComplaintEscalationChain returns the panchayat's escalation steps in order
+func (p *Panchayat) ComplaintEscalationChain() []EscalationStep {
+	if p == nil || len(p.ComplaintEscalation) == 0 {
+		return DefaultComplaintEscalation
+	}
+	return p.ComplaintEscalation
+}
+
+// ComplaintReopenWindow returns how long after resolution the reporter may
+// rate or reopen a complaint
+func (p *Panchayat) ComplaintReopenWindow() time.Duration {
+	days := DefaultComplaintReopenDays
+	if p != nil && p.ComplaintReopenDays > 0 {
+		days = p.ComplaintReopenDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
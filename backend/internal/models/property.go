@@ -2,12 +2,92 @@ package models
 
 import "time"
 
-// Property is a minimal model representing a tax/property record.
+// Property types
+const (
+	PropertyTypeResidential  = "residential"
+	PropertyTypeCommercial   = "commercial"
+	PropertyTypeAgricultural = "agricultural"
+)
+
+// Tax bill statuses
+const (
+	BillStatusPending       = "pending"
+	BillStatusPartiallyPaid = "partially_paid"
+	BillStatusPaid          = "paid"
+)
+
+// Payment statuses
+const (
+	PaymentStatusSuccess = "success"
+)
+
+// Property is a taxable property. Village and taluka are copied from the
+// owner when the property is registered and decide which staff can see it.
 type Property struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	OwnerID    uint      `json:"owner_id"`
-	Address    string    `json:"address,omitempty"`
-	AssessedAt time.Time `json:"assessed_at,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID     uint      `gorm:"index" json:"panchayat_id"`
+	OwnerID         uint      `gorm:"index;not null" json:"owner_id"`
+	PropertyType    string    `gorm:"index" json:"property_type"` // residential, commercial, agricultural
+	Address         string    `json:"address,omitempty"`
+	Village         string    `gorm:"index" json:"village"`
+	Taluka          string    `gorm:"index" json:"taluka"`
+	Area            float64   `json:"area"`
+	AnnualTaxAmount float64   `json:"annual_tax_amount"`
+	IsActive        bool      `gorm:"default:true" json:"is_active"`
+	AssessedAt      time.Time `json:"assessed_at,omitempty"`
+	Bills           []TaxBill `gorm:"foreignKey:PropertyID" json:"bills,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Property) TableName() string {
+	return "properties"
+}
+
+// TaxBill is the tax due on a property for one quarter of a financial year
+type TaxBill struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID   uint      `gorm:"index" json:"panchayat_id"`
+	PropertyID    uint      `gorm:"uniqueIndex:idx_tax_bill_period;not null" json:"property_id"`
+	FinancialYear string    `gorm:"uniqueIndex:idx_tax_bill_period;not null" json:"financial_year"` // e.g. 2024-25
+	Quarter       string    `gorm:"uniqueIndex:idx_tax_bill_period;not null" json:"quarter"`        // Q1-Q4
+	Amount        float64   `json:"amount"`
+	PaidAmount    float64   `gorm:"default:0" json:"paid_amount"`
+	Status        string    `gorm:"index;default:'pending'" json:"status"`
+	DueDate       time.Time `json:"due_date,omitempty"`
+	Property      *Property `gorm:"foreignKey:PropertyID" json:"property,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (TaxBill) TableName() string {
+	return "tax_bills"
+}
+
+// Balance is the amount still due on the bill
+func (b TaxBill) Balance() float64 {
+	return b.Amount - b.PaidAmount
+}
+
+// Payment is money received against a tax bill
+type Payment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID   uint      `gorm:"index" json:"panchayat_id"`
+	UserID        uint      `gorm:"index" json:"user_id"` // who paid or recorded the payment
+	BillID        uint      `gorm:"index;not null" json:"bill_id"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"` // online, cash, cheque, upi
+	TransactionID string    `json:"transaction_id,omitempty"`
+	ReceiptNumber string    `gorm:"uniqueIndex" json:"receipt_number"`
+	Status        string    `gorm:"index;default:'success'" json:"status"`
+	PaidAt        time.Time `gorm:"index" json:"paid_at,omitempty"`
+	Bill          *TaxBill  `gorm:"foreignKey:BillID" json:"bill,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Payment) TableName() string {
+	return "payments"
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RefreshToken is a single-use refresh token. Every token issued for one
+// session shares its SessionID; when a token is rotated it is marked used and
+// a new token for the same session is issued. Presenting a used token again
+// means it was stolen, and the whole session is revoked.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	SessionID uint       `gorm:"index;not null" json:"session_id"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolution statuses
+const (
+	ResolutionStatusPending  = "pending"
+	ResolutionStatusPassed   = "passed"
+	ResolutionStatusRejected = "rejected"
+)
+
+// Votes
+const (
+	VoteFor     = "for"
+	VoteAgainst = "against"
+	VoteAbstain = "abstain"
+)
+
+// Resolution is a formal decision taken in a meeting. Resolutions are numbered
+// sequentially within a financial year, e.g. resolution 14 of 2024-25.
+type Resolution struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PanchayatID   uint      `gorm:"uniqueIndex:idx_resolution_tenant_number" json:"panchayat_id"`
+	MeetingID     uint      `gorm:"index;not null" json:"meeting_id"`
+	FinancialYear string    `gorm:"not null;uniqueIndex:idx_resolution_tenant_number" json:"financial_year"`
+	Number        int       `gorm:"not null;uniqueIndex:idx_resolution_tenant_number" json:"number"`
+	Title         string    `gorm:"not null" json:"title"`
+	Text          string    `gorm:"type:text" json:"text"`
+	MoverID       uint      `json:"mover_id"`
+	SeconderID    uint      `json:"seconder_id"`
+	Status        string    `gorm:"index;default:'pending'" json:"status"`
+	VotesFor      int       `json:"votes_for"`
+	VotesAgainst  int       `json:"votes_against"`
+	VotesAbstain  int       `json:"votes_abstain"`
+	CreatedBy     uint      `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relations
+	Meeting  *Meeting         `gorm:"foreignKey:MeetingID" json:"meeting,omitempty"`
+	Mover    *User            `gorm:"foreignKey:MoverID" json:"mover,omitempty"`
+	Seconder *User            `gorm:"foreignKey:SeconderID" json:"seconder,omitempty"`
+	Votes    []ResolutionVote `gorm:"foreignKey:ResolutionID" json:"votes,omitempty"`
+}
+
+// Reference returns the citation form of the resolution, e.g. "14/2024-25"
+func (r Resolution) Reference() string {
+	return fmt.Sprintf("%d/%s", r.Number, r.FinancialYear)
+}
+
+// ResolutionVote is one member's vote on a resolution.
+type ResolutionVote struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ResolutionID uint      `gorm:"not null;uniqueIndex:idx_resolution_voter" json:"resolution_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_resolution_voter" json:"user_id"`
+	Vote         string    `gorm:"not null" json:"vote"` // for, against, abstain
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// ResolutionSequence holds the last resolution number a panchayat issued in a
+// financial year.
+type ResolutionSequence struct {
+	PanchayatID   uint   `gorm:"primaryKey;autoIncrement:false" json:"panchayat_id"`
+	FinancialYear string `gorm:"primaryKey" json:"financial_year"`
+	LastNumber    int    `gorm:"not null" json:"last_number"`
+}
+
+// TableName overrides the table name
+func (Resolution) TableName() string {
+	return "resolutions"
+}
+
+// TableName overrides the table name
+func (ResolutionVote) TableName() string {
+	return "resolution_votes"
+}
+
+// TableName overrides the table name
+func (ResolutionSequence) TableName() string {
+	return "resolution_sequences"
+}
@@ -0,0 +1,197 @@
+package models
+
+import "time"
+
+// Permissions checked by the API. Roles grant any subset of these.
+const (
+	PermDashboardAdminView = "dashboard.admin.view"
+
+	PermPanchayatSettingsManage = "panchayat.settings.manage"
+
+	PermUsersView       = "users.view"
+	PermUsersManage     = "users.manage"
+	PermUsersRoleAssign = "users.role.assign"
+	PermRolesManage     = "roles.manage"
+	PermJurisdictionAll = "jurisdiction.all"
+	PermAuditView       = "audit.view"
+
+	PermApplicationsViewAll      = "applications.view_all"
+	PermApplicationsStatusUpdate = "applications.status.update"
+	PermApplicationTypesManage   = "applications.types.manage"
+
+	PermComplaintsViewAll = "complaints.view_all"
+	PermComplaintsUpdate  = "complaints.update"
+	PermComplaintsAssign  = "complaints.assign"
+
+	PermWorkOrdersManage      = "work_orders.manage"
+	PermWorkOrdersReportsView = "work_orders.reports.view"
+	PermMaterialsManage       = "materials.manage"
+
+	PermPropertyViewAll      = "property.view_all"
+	PermPropertyManage       = "property.manage"
+	PermPropertyBillCreate   = "property.bill.create"
+	PermPropertyReportsView  = "property.reports.view"
+	PermPropertyReminderSend = "property.reminder.send"
+	PermPaymentsViewAll      = "payments.view_all"
+	PermPaymentsRecord       = "payments.record"
+
+	PermNoticesManage = "notices.manage"
+
+	PermMeetingsManage           = "meetings.manage"
+	PermMeetingsMinutesWrite     = "meetings.minutes.write"
+	PermMeetingsAttendanceRecord = "meetings.attendance.record"
+	PermMeetingsAttendanceReport = "meetings.attendance.report"
+	PermMeetingsResolutionsWrite = "meetings.resolutions.write"
+	PermMeetingsAttend           = "meetings.attend"
+)
+
+// PermissionCatalog lists every permission with a short description for the
+// role editor
+var PermissionCatalog = map[string]string{
+	PermDashboardAdminView: "View the administration dashboard",
+
+	PermPanchayatSettingsManage: "Edit the panchayat's name, LGD code, logo, financial year and tax rates",
+
+	PermUsersView:       "View user accounts",
+	PermUsersManage:     "Edit, deactivate and delete user accounts, reset passwords and revoke sessions",
+	PermUsersRoleAssign: "Change a user's role",
+	PermRolesManage:     "Create roles and edit their permissions",
+	PermJurisdictionAll: "See records from every village and taluka, not only assigned jurisdictions",
+	PermAuditView:       "View the audit trail and verify it has not been tampered with",
+
+	PermApplicationsViewAll:      "View all service applications",
+	PermApplicationsStatusUpdate: "Review service applications at their role's workflow stages",
+	PermApplicationTypesManage:   "Add application types and edit their forms",
+
+	PermComplaintsViewAll: "View all complaints",
+	PermComplaintsUpdate:  "Update complaint status",
+	PermComplaintsAssign:  "Assign complaints to staff",
+
+	PermWorkOrdersManage:      "Create work orders for complaints, issue materials to them and mark them complete",
+	PermWorkOrdersReportsView: "View work order cost reports",
+	PermMaterialsManage:       "Add materials to stock and restock them",
+
+	PermPropertyViewAll:      "View all properties and their bills",
+	PermPropertyManage:       "Edit and delete properties",
+	PermPropertyBillCreate:   "Create property tax bills",
+	PermPropertyReportsView:  "View property statistics and revenue reports",
+	PermPropertyReminderSend: "Send payment reminders",
+	PermPaymentsViewAll:      "View all payments and receipts",
+	PermPaymentsRecord:       "Record payments on behalf of property owners",
+
+	PermNoticesManage: "Create, edit and delete notices",
+
+	PermMeetingsManage:           "Schedule meetings and change their status",
+	PermMeetingsMinutesWrite:     "Record meeting minutes",
+	PermMeetingsAttendanceRecord: "Record meeting attendance",
+	PermMeetingsAttendanceReport: "View member attendance reports",
+	PermMeetingsResolutionsWrite: "Record resolutions and votes",
+	PermMeetingsAttend:           "Check in at meetings as a member",
+}
+
+// Built-in role names. RoleAdmin always holds every permission and
+// RoleCitizen is the default for self-registered accounts; neither can be
+// deleted.
+const (
+	RoleAdmin    = "admin"
+	RoleCitizen  = "citizen"
+	RoleStaff    = "staff"
+	RoleTaxClerk = "tax_clerk"
+
+	// Panchayat office holders, the later steps of complaint escalation
+	RoleGramSevak = "gram_sevak"
+	RoleSarpanch  = "sarpanch"
+)
+
+// DefaultRoles are created on first start. Existing roles are left as they
+// are, except that the admin role is kept in sync with the catalog.
+var DefaultRoles = map[string][]string{
+	RoleAdmin:   nil, // every permission
+	RoleCitizen: {},
+	RoleStaff: {
+		PermApplicationsViewAll,
+		PermApplicationsStatusUpdate,
+		PermComplaintsViewAll,
+		PermComplaintsUpdate,
+		PermComplaintsAssign,
+		PermWorkOrdersManage,
+		PermNoticesManage,
+		PermMeetingsMinutesWrite,
+		PermMeetingsAttendanceRecord,
+		PermMeetingsAttend,
+	},
+	RoleGramSevak: {
+		PermApplicationsViewAll,
+		PermApplicationsStatusUpdate,
+		PermApplicationTypesManage,
+		PermComplaintsViewAll,
+		PermComplaintsUpdate,
+		PermComplaintsAssign,
+		PermWorkOrdersManage,
+		PermWorkOrdersReportsView,
+		PermMaterialsManage,
+		PermNoticesManage,
+		PermMeetingsMinutesWrite,
+		PermMeetingsAttendanceRecord,
+		PermMeetingsAttendanceReport,
+		PermMeetingsAttend,
+	},
+	RoleSarpanch: {
+		PermApplicationsViewAll,
+		PermApplicationsStatusUpdate,
+		PermComplaintsViewAll,
+		PermComplaintsUpdate,
+		PermComplaintsAssign,
+		PermWorkOrdersReportsView,
+		PermMeetingsAttendanceReport,
+		PermMeetingsResolutionsWrite,
+		PermMeetingsAttend,
+	},
+	RoleTaxClerk: {
+		PermPropertyViewAll,
+		PermPropertyBillCreate,
+		PermPropertyReportsView,
+		PermPropertyReminderSend,
+		PermPaymentsViewAll,
+		PermPaymentsRecord,
+	},
+}
+
+// Role is a named set of permissions assigned to users through User.Role.
+// Each panchayat has its own roles, so one panchayat's edits do not change
+// what another's staff can do.
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	PanchayatID uint             `gorm:"uniqueIndex:idx_roles_tenant_name,priority:1" json:"panchayat_id"`
+	Name        string           `gorm:"uniqueIndex:idx_roles_tenant_name,priority:2;not null" json:"name"`
+	Description string           `json:"description"`
+	IsSystem    bool             `gorm:"default:false" json:"is_system"`
+	Grants      []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"-"`
+	Permissions []string         `gorm:"-" json:"permissions"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionNames returns the role's permissions as a list
+func (r Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Grants))
+	for _, p := range r.Grants {
+		names = append(names, p.Permission)
+	}
+	return names
+}
+
+type RolePermission struct {
+	RoleID     uint   `gorm:"primaryKey" json:"role_id"`
+	Permission string `gorm:"primaryKey;size:100" json:"permission"`
+}
+
+// TableName overrides the table name
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Session is one signed-in device. Access tokens carry the session ID and
+// refresh tokens belong to a session, so revoking the session signs the
+// device out immediately.
+type Session struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	PanchayatID   uint       `gorm:"index" json:"panchayat_id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`
+	DeviceID      string     `gorm:"index" json:"device_id"`
+	UserAgent     string     `json:"user_agent"`
+	IPAddress     string     `json:"ip_address"`
+	LastSeenAt    time.Time  `json:"last_seen_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the session can still be used
+func (s Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// TableName overrides the table name
+func (Session) TableName() string {
+	return "sessions"
+}
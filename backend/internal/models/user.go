@@ -11,16 +11,22 @@ type User struct {
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Accounts belong to one panchayat; email, phone and Aadhaar are unique
+	// within it
+	PanchayatID       uint           `gorm:"index:idx_users_tenant_email,unique,priority:1;uniqueIndex:idx_users_tenant_phone,priority:1;uniqueIndex:idx_users_tenant_aadhar,priority:1" json:"panchayat_id"`
 	
-	Email             string         `gorm:"uniqueIndex;not null" json:"email"`
+	// Email is optional for citizens who register with a verified phone
+	// number, so uniqueness only applies to non-empty addresses.
+	Email             string         `gorm:"index:idx_users_tenant_email,unique,where:email <> '',priority:2" json:"email"`
 	Password          string         `gorm:"not null" json:"-"`
 	Role              string         `gorm:"default:'citizen'" json:"role"` // admin, citizen
 	
 	// Personal Information
 	FirstName         string         `json:"first_name"`
 	LastName          string         `json:"last_name"`
-	PhoneNumber       string         `gorm:"uniqueIndex" json:"phone_number"`
-	AadharNumber      string         `gorm:"uniqueIndex" json:"aadhar_number"`
+	PhoneNumber       string         `gorm:"uniqueIndex:idx_users_tenant_phone,priority:2" json:"phone_number"`
+	AadharNumber      string         `gorm:"uniqueIndex:idx_users_tenant_aadhar,priority:2" json:"aadhar_number"`
 	DateOfBirth       *time.Time     `json:"date_of_birth"`
 	Gender            string         `json:"gender"`
 	
@@ -34,13 +40,10 @@ type User struct {
 	
 	// Account Status
 	IsVerified        bool           `gorm:"default:false" json:"is_verified"`
+	PhoneVerified     bool           `gorm:"default:false" json:"phone_verified"`
 	IsActive          bool           `gorm:"default:true" json:"is_active"`
 	ProfileImage      string         `json:"profile_image"`
 	
-	// OTP for verification
-	OTP               string         `json:"-"`
-	OTPExpiry         *time.Time     `json:"-"`
-	
 	// Relations
 	Applications      []Application  `gorm:"foreignKey:UserID" json:"applications,omitempty"`
 	Complaints        []Complaint    `gorm:"foreignKey:UserID" json:"complaints,omitempty"`
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// Work order statuses
+const (
+	WorkOrderStatusPlanned    = "planned"
+	WorkOrderStatusInProgress = "in_progress"
+	WorkOrderStatusCompleted  = "completed"
+	WorkOrderStatusCancelled  = "cancelled"
+)
+
+// WorkOrderActiveStatuses are the statuses in which a work order still holds
+// its complaints. A complaint reopened after its work order is done can go on
+// a new one.
+var WorkOrderActiveStatuses = []string{
+	WorkOrderStatusPlanned,
+	WorkOrderStatusInProgress,
+}
+
+// Work order photo stages
+const (
+	PhotoStageBefore = "before"
+	PhotoStageAfter  = "after"
+)
+
+// WorkOrder is the field work that fixes one or more complaints: who does
+// it, when, and what it costs. Completing it resolves its complaints.
+type WorkOrder struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	PanchayatID    uint       `gorm:"index" json:"panchayat_id"`
+	Title          string     `gorm:"not null" json:"title"`
+	Description    string     `gorm:"type:text" json:"description,omitempty"`
+	Category       string     `gorm:"index;not null" json:"category"` // of its complaints, for cost reports
+	Ward           string     `gorm:"index" json:"ward,omitempty"`
+	Village        string     `gorm:"index" json:"village"`
+	Taluka         string     `gorm:"index" json:"taluka"`
+	Status         string     `gorm:"index;not null;default:'planned'" json:"status"`
+	Crew           []uint     `gorm:"serializer:json;type:jsonb" json:"crew"` // user IDs of the staff doing the work
+	PlannedDate    *time.Time `gorm:"index" json:"planned_date,omitempty"`
+	LaborCost      float64    `gorm:"not null;default:0" json:"labor_cost"`
+	MaterialCost   float64    `gorm:"not null;default:0" json:"material_cost"` // sum of the materials issued
+	CompletionNote string     `json:"completion_note,omitempty"`
+	CreatedBy      uint       `json:"created_by"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `gorm:"index" json:"completed_at,omitempty"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relations
+	Complaints []Complaint         `gorm:"foreignKey:WorkOrderID" json:"complaints,omitempty"`
+	Materials  []WorkOrderMaterial `gorm:"foreignKey:WorkOrderID" json:"materials,omitempty"`
+	Photos     []WorkOrderPhoto    `gorm:"foreignKey:WorkOrderID" json:"photos,omitempty"`
+}
+
+// TotalCost is the labour and materials spent on the work order
+func (w WorkOrder) TotalCost() float64 {
+	return w.LaborCost + w.MaterialCost
+}
+
+// WorkOrderMaterial is stock issued to a work order, priced at the unit cost
+// of the day it was issued
+type WorkOrderMaterial struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkOrderID uint      `gorm:"index;not null" json:"work_order_id"`
+	MaterialID  uint      `gorm:"index;not null" json:"material_id"`
+	Name        string    `json:"name"`
+	Unit        string    `json:"unit"`
+	Quantity    float64   `gorm:"not null" json:"quantity"`
+	UnitCost    float64   `gorm:"not null" json:"unit_cost"`
+	Cost        float64   `gorm:"not null" json:"cost"`
+	IssuedBy    uint      `json:"issued_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkOrderPhoto shows the site before or after the work, stored elsewhere
+// and referenced by URL
+type WorkOrderPhoto struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkOrderID uint      `gorm:"index;not null" json:"work_order_id"`
+	Stage       string    `gorm:"not null" json:"stage"` // before, after
+	URL         string    `gorm:"not null" json:"url"`
+	Caption     string    `json:"caption,omitempty"`
+	UploadedBy  uint      `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (WorkOrder) TableName() string {
+	return "work_orders"
+}
+
+// TableName overrides the table name
+func (WorkOrderMaterial) TableName() string {
+	return "work_order_materials"
+}
+
+// TableName overrides the table name
+func (WorkOrderPhoto) TableName() string {
+	return "work_order_photos"
+}
@@ -1,6 +1,150 @@
 package repository
 
-// TODO: restore original contents of application_repository.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
 
+type ApplicationRepository struct {
+	db *gorm.DB
+}
+
+func NewApplicationRepository(db *gorm.DB) *ApplicationRepository {
+	return &ApplicationRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *ApplicationRepository) ForTenant(panchayatID uint) *ApplicationRepository {
+	return &ApplicationRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// List returns a page of applications matching the filters within the scope
+func (r *ApplicationRepository) List(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Application, int64, error) {
+	var applications []models.Application
+	var total int64
+
+	query := applyScope(r.db.Model(&models.Application{}).Where(filters), scope, "village", "taluka")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&applications).Error
+	return applications, total, err
+}
+
+// ListByUser returns every application of one applicant, newest first
+func (r *ApplicationRepository) ListByUser(userID uint) ([]models.Application, error) {
+	var applications []models.Application
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&applications).Error
+	return applications, err
+}
+
+// GetByID returns an application with its history, oldest first
+func (r *ApplicationRepository) GetByID(id uint) (*models.Application, error) {
+	var application models.Application
+	err := r.db.
+		Preload("History", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		First(&application, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// Create stores an application and the first entry of its history
+func (r *ApplicationRepository) Create(application *models.Application, action *models.ApplicationAction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(application).Error; err != nil {
+			return err
+		}
+		action.ApplicationID = application.ID
+		return tx.Create(action).Error
+	})
+}
+
+func (r *ApplicationRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Application{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Advance applies a workflow step and records it in the history. The
+// update only applies while the application is still at the expected status
+// and stage, so two reviewers acting at once cannot both move it.
+func (r *ApplicationRepository) Advance(id uint, fromStatus string, fromStage int, fields map[string]interface{}, action *models.ApplicationAction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Application{}).
+			Where("id = ? AND status = ? AND stage_index = ?", id, fromStatus, fromStage).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		return tx.Create(action).Error
+	})
+}
+
+// CountBy returns the number of applications matching the filters grouped
+// by a column
+func (r *ApplicationRepository) CountBy(column string, filters map[string]interface{}) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	err := r.db.Model(&models.Application{}).
+		Select(column + " AS key, COUNT(*) AS count").
+		Where(filters).
+		Group(column).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// ListTypes returns the application types the panchayat has saved, by code
+func (r *ApplicationRepository) ListTypes() ([]models.ApplicationType, error) {
+	var types []models.ApplicationType
+	err := r.db.Order("code").Find(&types).Error
+	return types, err
+}
+
+func (r *ApplicationRepository) GetType(code string) (*models.ApplicationType, error) {
+	var applicationType models.ApplicationType
+	if err := r.db.Where("code = ?", code).First(&applicationType).Error; err != nil {
+		return nil, err
+	}
+	return &applicationType, nil
+}
+
+func (r *ApplicationRepository) CreateType(applicationType *models.ApplicationType) error {
+	return r.db.Create(applicationType).Error
+}
+
+// UpdateType saves an edited type only if it is still at fromVersion, so
+// two admins editing the same form cannot overwrite each other
+func (r *ApplicationRepository) UpdateType(id uint, fromVersion int, fields map[string]interface{}) error {
+	result := r.db.Model(&models.ApplicationType{}).Where("id = ? AND version = ?", id, fromVersion).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleStatus
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// internal/repository/attendance_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gram-panchayat/internal/models"
+)
+
+type AttendanceRepository struct {
+	db *gorm.DB
+}
+
+func NewAttendanceRepository(db *gorm.DB) *AttendanceRepository {
+	return &AttendanceRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *AttendanceRepository) ForTenant(panchayatID uint) *AttendanceRepository {
+	return &AttendanceRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// Upsert records attendance entries, replacing any existing entry for the
+// same member of the same meeting.
+func (r *AttendanceRepository) Upsert(records []models.MeetingAttendance) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "meeting_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "checked_in_at", "remarks", "recorded_by", "updated_at"}),
+	}).Create(&records).Error
+}
+
+// Insert records a member's attendance unless the meeting already has an
+// entry for them, and reports whether it did
+func (r *AttendanceRepository) Insert(record *models.MeetingAttendance) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "meeting_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetForMember returns a member's attendance entry for a meeting
+func (r *AttendanceRepository) GetForMember(meetingID, userID uint) (*models.MeetingAttendance, error) {
+	var record models.MeetingAttendance
+	if err := r.db.Where("meeting_id = ? AND user_id = ?", meetingID, userID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *AttendanceRepository) ListByMeeting(meetingID uint) ([]models.MeetingAttendance, error) {
+	var records []models.MeetingAttendance
+	err := r.db.Preload("User").
+		Where("meeting_id = ?", meetingID).
+		Order("checked_in_at ASC NULLS LAST, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *AttendanceRepository) CountPresent(meetingID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.MeetingAttendance{}).
+		Where("meeting_id = ? AND status = ?", meetingID, models.AttendancePresent).
+		Count(&count).Error
+	return count, err
+}
+
+// MemberSummary aggregates attendance per member for meetings held in
+// [from, to); cancelled and postponed meetings are left out. Members are
+// those in the register of any of these meetings, and every meeting counts
+// for each of them: a meeting without an entry for a member counts as an
+// absence. Optional filters are "meeting_type" and "user_id".
+func (r *AttendanceRepository) MemberSummary(from, to time.Time, filters map[string]interface{}) ([]models.MemberAttendance, error) {
+	var summary []models.MemberAttendance
+
+	panchayatID, err := requireTenant(r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	held := r.db.Table("meetings").Select("id").
+		Where("panchayat_id = ?", panchayatID).
+		Where("scheduled_at >= ? AND scheduled_at < ?", from, to).
+		Where("status IN ?", []string{models.MeetingStatusInProgress, models.MeetingStatusCompleted})
+	if meetingType, ok := filters["meeting_type"]; ok {
+		held = held.Where("meeting_type = ?", meetingType)
+	}
+	members := r.db.Table("meeting_attendances").Select("DISTINCT user_id").Where("meeting_id IN (?)", held)
+	if userID, ok := filters["user_id"]; ok {
+		members = members.Where("user_id = ?", userID)
+	}
+
+	err = r.db.Table("(?) AS m", held).
+		Select(`mb.user_id, u.first_name, u.last_name, u.village,
+			COUNT(*) AS meetings,
+			COUNT(*) FILTER (WHERE a.status = ?) AS present,
+			COUNT(*) FILTER (WHERE a.status = ? OR a.id IS NULL) AS absent,
+			COUNT(*) FILTER (WHERE a.status = ?) AS excused`,
+			models.AttendancePresent, models.AttendanceAbsent, models.AttendanceExcused).
+		Joins("CROSS JOIN (?) AS mb", members).
+		Joins("LEFT JOIN meeting_attendances a ON a.meeting_id = m.id AND a.user_id = mb.user_id").
+		Joins("JOIN users u ON u.id = mb.user_id").
+		Group("mb.user_id, u.first_name, u.last_name, u.village").
+		Order("u.first_name, u.last_name").
+		Find(&summary).Error
+	return summary, err
+}
+
+// PresentMembers returns the IDs of members marked present at a meeting
+func (r *AttendanceRepository) PresentMembers(meetingID uint) (map[uint]bool, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.MeetingAttendance{}).
+		Where("meeting_id = ? AND status = ?", meetingID, models.AttendancePresent).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		present[id] = true
+	}
+	return present, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gram-panchayat/internal/models"
+)
+
+func TestMemberSummaryCountsMissingEntriesAsAbsent(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewAttendanceRepository(db).ForTenant(2)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.MemberSummary(from, from.AddDate(0, 1, 0), map[string]interface{}{"meeting_type": "masik_sabha"}); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	for _, want := range []string{
+		`FROM (SELECT id FROM "meetings" WHERE panchayat_id = 2`,
+		"meeting_type = 'masik_sabha'",
+		"CROSS JOIN (SELECT DISTINCT user_id FROM \"meeting_attendances\"",
+		"LEFT JOIN meeting_attendances a ON a.meeting_id = m.id AND a.user_id = mb.user_id",
+		"a.status = 'absent' OR a.id IS NULL",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %q", want, sql)
+		}
+	}
+}
+
+func TestAttendanceInsertKeepsRecordedEntries(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewAttendanceRepository(db).ForTenant(2)
+
+	if _, err := repo.Insert(&models.MeetingAttendance{MeetingID: 5, UserID: 9, Status: models.AttendancePresent}); err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); !strings.Contains(sql, `ON CONFLICT ("meeting_id","user_id") DO NOTHING`) {
+		t.Errorf("expected a check-in to leave an existing entry alone, got %q", sql)
+	}
+}
@@ -0,0 +1,107 @@
+// internal/repository/audit_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// auditChainLock is the first key of the advisory lock that serialises
+// appends to one panchayat's chain; the panchayat ID is the second
+const auditChainLock = 7301
+
+// AuditRepository only appends and reads; audit_logs also rejects UPDATE and
+// DELETE at the database level.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *AuditRepository) ForTenant(panchayatID uint) *AuditRepository {
+	return &AuditRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// Append links the record to the end of its panchayat's chain and stores it
+func (r *AuditRepository) Append(entry *models.AuditLog) error {
+	panchayatID, err := requireTenant(r.db)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", auditChainLock, panchayatID).Error; err != nil {
+			return err
+		}
+
+		var last models.AuditLog
+		err := tx.Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		entry.PanchayatID = panchayatID
+		entry.PrevHash = last.Hash
+		// PostgreSQL keeps microseconds; hash what will be read back
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+// List returns a page of records, newest first. Supported filters are
+// "entity_type", "entity_id", "actor_id", "action", "created_from" and
+// "created_to" (time.Time).
+func (r *AuditRepository) List(list utils.ListQuery, filters map[string]interface{}) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	query := r.db.Model(&models.AuditLog{})
+	for key, value := range filters {
+		switch key {
+		case "entity_type", "entity_id", "actor_id", "action":
+			query = query.Where(key+" = ?", value)
+		case "created_from":
+			if from, ok := value.(time.Time); ok {
+				query = query.Where("created_at >= ?", from)
+			}
+		case "created_to":
+			if to, ok := value.(time.Time); ok {
+				query = query.Where("created_at < ?", to)
+			}
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&logs).Error
+	return logs, total, err
+}
+
+// ListByUser returns the newest records where the user is the actor or the
+// entity changed
+func (r *AuditRepository) ListByUser(userID uint, limit int) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := r.db.Where("actor_id = ? OR (entity_type = ? AND entity_id = ?)", userID, models.AuditEntityUser, userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// Walk passes the chain to fn in order, batch by batch. fn returning an error
+// stops the walk.
+func (r *AuditRepository) Walk(batchSize int, fn func([]models.AuditLog) error) error {
+	var logs []models.AuditLog
+	return r.db.Order("id ASC").FindInBatches(&logs, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(logs)
+	}).Error
+}
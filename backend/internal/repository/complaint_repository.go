@@ -1,6 +1,398 @@
+// internal/repository/complaint_repository.go
 package repository
 
-// TODO: restore original contents of complaint_repository.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+type ComplaintRepository struct {
+	db      *gorm.DB
+	postgis bool
+}
+
+func NewComplaintRepository(db *gorm.DB) *ComplaintRepository {
+	return &ComplaintRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *ComplaintRepository) ForTenant(panchayatID uint) *ComplaintRepository {
+	return &ComplaintRepository{db: WithTenant(r.db, panchayatID), postgis: r.postgis}
+}
+
+// AllTenants returns a copy of the repository that reads every panchayat,
+// for background jobs
+func (r *ComplaintRepository) AllTenants() *ComplaintRepository {
+	return &ComplaintRepository{db: WithAllTenants(r.db), postgis: r.postgis}
+}
+
+// SetPostGIS makes distance searches and hotspot clustering use PostGIS
+// functions instead of plain SQL. Only enable it where HasPostGIS is true.
+func (r *ComplaintRepository) SetPostGIS(enabled bool) {
+	r.postgis = enabled
+}
+
+// HasPostGIS reports whether the PostGIS extension is installed
+func HasPostGIS(db *gorm.DB) bool {
+	var installed bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&installed).Error
+	return err == nil && installed
+}
+
+// Hotspot is a cluster of open complaints of one category
+type Hotspot struct {
+	Category     string  `json:"category"`
+	Count        int64   `json:"count"`
+	Latitude     float64 `json:"latitude"` // centre of the cluster
+	Longitude    float64 `json:"longitude"`
+	ComplaintIDs []uint  `json:"complaint_ids" gorm:"-"`
+	IDs          string  `json:"-" gorm:"column:ids"`
+}
+
+// haversineMeters is the great-circle distance in meters from
+// (latitude, longitude) to a point given as (lat, lat, lng) arguments
+const haversineMeters = "6371000 * 2 * ASIN(SQRT(POWER(SIN(RADIANS(latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2)))"
+
+// SLARow counts the complaints of one group whose SLA outcome is known:
+// those resolved or closed, and those still open past their target
+type SLARow struct {
+	Key      string `json:"key"`
+	Total    int64  `json:"total"`
+	Breached int64  `json:"breached"`
+}
+
+// SatisfactionRow sums the reporter feedback of one group of complaints
+// that have been resolved at least once
+type SatisfactionRow struct {
+	Key       string `json:"key"`
+	Resolved  int64  `json:"resolved"`
+	Rated     int64  `json:"rated"`
+	RatingSum int64  `json:"rating_sum"`
+	Reopened  int64  `json:"reopened"`
+}
+
+// List returns a page of complaints matching the filters within the scope,
+// and within the area when geo is set. Filters are matched for equality, e.g.
+// "status", "category", "priority", "user_id" and "assigned_to".
+func (r *ComplaintRepository) List(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope, geo *utils.GeoFilter) ([]models.Complaint, int64, error) {
+	var complaints []models.Complaint
+	var total int64
+
+	query := r.withinArea(applyScope(r.db.Model(&models.Complaint{}).Where(filters), scope, "village", "taluka"), geo)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&complaints).Error
+	return complaints, total, err
+}
+
+// ListLocated returns up to limit of the newest complaints with coordinates
+// that match the filters, scope and area, for map exports
+func (r *ComplaintRepository) ListLocated(filters map[string]interface{}, scope models.JurisdictionScope, geo *utils.GeoFilter, limit int) ([]models.Complaint, error) {
+	var complaints []models.Complaint
+	query := applyScope(r.db.Model(&models.Complaint{}).Where(filters), scope, "village", "taluka").
+		Where("latitude IS NOT NULL AND longitude IS NOT NULL")
+	err := r.withinArea(query, geo).Order("created_at DESC, id DESC").Limit(limit).Find(&complaints).Error
+	return complaints, err
+}
+
+// Hotspots clusters the open complaints with coordinates that match the
+// filters and scope. Complaints of the same category within cellMeters of
+// each other are grouped; clusters smaller than minCount are left out. With
+// PostGIS this is DBSCAN clustering, otherwise a grid of cellMeters squares.
+func (r *ComplaintRepository) Hotspots(filters map[string]interface{}, scope models.JurisdictionScope, cellMeters float64, minCount int) ([]Hotspot, error) {
+	points := applyScope(r.db.Model(&models.Complaint{}).Where(filters), scope, "village", "taluka").
+		Where("status IN ? AND latitude IS NOT NULL AND longitude IS NOT NULL", models.ComplaintOpenStatuses)
+
+	var hotspots []Hotspot
+	var err error
+	if r.postgis {
+		points = points.Select("id, category, latitude, longitude, "+
+			"ST_ClusterDBSCAN(ST_Transform(ST_SetSRID(ST_MakePoint(longitude, latitude), 4326), 3857), eps => ?, minpoints => ?) "+
+			"OVER (PARTITION BY category) AS cluster", cellMeters, minCount)
+		err = r.db.Table("(?) AS points", points).
+			Select("category, COUNT(*) AS count, AVG(latitude) AS latitude, AVG(longitude) AS longitude, " +
+				"STRING_AGG(CAST(id AS TEXT), ',' ORDER BY id) AS ids").
+			Where("cluster IS NOT NULL").
+			Group("category, cluster").
+			Order("count DESC, category").
+			Find(&hotspots).Error
+	} else {
+		// Cells are cellMeters on a side: longitude is scaled by the
+		// latitude's circle so cells stay square away from the equator
+		step := cellMeters / utils.MetersPerDegree
+		err = points.
+			Select("category, COUNT(*) AS count, AVG(latitude) AS latitude, AVG(longitude) AS longitude, "+
+				"STRING_AGG(CAST(id AS TEXT), ',' ORDER BY id) AS ids").
+			Group(fmt.Sprintf("category, FLOOR(latitude / %[1]g), FLOOR(longitude * COS(RADIANS(latitude)) / %[1]g)", step)).
+			Having("COUNT(*) >= ?", minCount).
+			Order("count DESC, category").
+			Find(&hotspots).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hotspots {
+		for _, id := range strings.Split(hotspots[i].IDs, ",") {
+			if value, err := strconv.ParseUint(id, 10, 0); err == nil {
+				hotspots[i].ComplaintIDs = append(hotspots[i].ComplaintIDs, uint(value))
+			}
+		}
+	}
+	return hotspots, nil
+}
+
+// withinArea limits a complaint query to the area of a geo filter
+func (r *ComplaintRepository) withinArea(query *gorm.DB, geo *utils.GeoFilter) *gorm.DB {
+	if geo == nil {
+		return query
+	}
+	query = query.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+		geo.Box.MinLatitude, geo.Box.MaxLatitude, geo.Box.MinLongitude, geo.Box.MaxLongitude)
+	if geo.RadiusMeters <= 0 {
+		return query
+	}
+	if r.postgis {
+		return query.Where("ST_DWithin(geography(ST_MakePoint(longitude, latitude)), geography(ST_MakePoint(?, ?)), ?)",
+			geo.Longitude, geo.Latitude, geo.RadiusMeters)
+	}
+	return query.Where(haversineMeters+" <= ?", geo.Latitude, geo.Latitude, geo.Longitude, geo.RadiusMeters)
+}
+
+// GetByID returns a complaint with its status history and comment thread,
+// oldest first
+func (r *ComplaintRepository) GetByID(id uint) (*models.Complaint, error) {
+	var complaint models.Complaint
+	oldestFirst := func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}
+	err := r.db.
+		Preload("StatusHistory", oldestFirst).
+		Preload("Comments", oldestFirst).
+		Preload("Comments.Attachments", oldestFirst).
+		First(&complaint, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &complaint, nil
+}
+
+func (r *ComplaintRepository) Create(complaint *models.Complaint) error {
+	return r.db.Create(complaint).Error
+}
+
+func (r *ComplaintRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Complaint{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AddComment stores a comment with its attachments
+func (r *ComplaintRepository) AddComment(comment *models.ComplaintComment) error {
+	return r.db.Create(comment).Error
+}
+
+// FindDuplicateCandidates returns recent unresolved complaints of the same
+// category within the scope that could be the same problem: within
+// radiusMeters when the complaint has coordinates, otherwise in the same
+// village
+func (r *ComplaintRepository) FindDuplicateCandidates(complaint *models.Complaint, scope models.JurisdictionScope, radiusMeters float64, since time.Time, limit int) ([]models.Complaint, error) {
+	var candidates []models.Complaint
+	query := applyScope(r.db.Model(&models.Complaint{}), scope, "village", "taluka").
+		Where("id <> ? AND category = ? AND merged_into IS NULL AND status IN ? AND created_at >= ?",
+			complaint.ID, complaint.Category, models.ComplaintOpenStatuses, since)
+	if complaint.Latitude != nil && complaint.Longitude != nil {
+		query = r.withinArea(query, utils.CircleFilter(*complaint.Latitude, *complaint.Longitude, radiusMeters))
+	} else {
+		query = query.Where("village = ?", complaint.Village)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&candidates).Error
+	return candidates, err
+}
+
+// Merge closes a complaint as a duplicate of its parent and records the
+// transition. Complaints already merged into the child move to the parent,
+// and the parent counts every report merged into it. The merge only applies
+// while the child is in the expected status and not merged yet.
+func (r *ComplaintRepository) Merge(childID, parentID uint, fromStatus string, fields map[string]interface{}, change *models.ComplaintStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var child models.Complaint
+		if err := tx.Select("id", "duplicate_count").First(&child, childID).Error; err != nil {
+			return err
+		}
+
+		fields["merged_into"] = parentID
+		fields["duplicate_count"] = 0
+		result := tx.Model(&models.Complaint{}).
+			Where("id = ? AND status = ? AND merged_into IS NULL", childID, fromStatus).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+
+		if err := tx.Model(&models.Complaint{}).Where("merged_into = ?", childID).Update("merged_into", parentID).Error; err != nil {
+			return err
+		}
+		result = tx.Model(&models.Complaint{}).
+			Where("id = ? AND merged_into IS NULL", parentID).
+			Update("duplicate_count", gorm.Expr("duplicate_count + ?", child.DuplicateCount+1))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// ListReporters returns everyone who reported a complaint, directly or
+// through a duplicate merged into it
+func (r *ComplaintRepository) ListReporters(id uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.Complaint{}).
+		Where("id = ? OR merged_into = ?", id, id).
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// ChangeStatus moves a complaint from one status to another, applies the
+// other field changes and records the transition. The update only applies
+// while the complaint is still in the expected status, so two concurrent
+// transitions cannot both succeed.
+func (r *ComplaintRepository) ChangeStatus(id uint, fromStatus string, fields map[string]interface{}, change *models.ComplaintStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Complaint{}).
+			Where("id = ? AND status = ?", id, fromStatus).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// ListOverdue returns the unresolved complaints whose SLA target has passed
+func (r *ComplaintRepository) ListOverdue(now time.Time) ([]models.Complaint, error) {
+	var complaints []models.Complaint
+	err := r.db.
+		Where("status IN ? AND sla_due_at <= ?", models.ComplaintOpenStatuses, now).
+		Order("panchayat_id, sla_due_at").
+		Find(&complaints).Error
+	return complaints, err
+}
+
+// Escalate marks a complaint breached and records the escalation steps
+// notified. It only applies while the complaint is unresolved and still at
+// the expected escalation level, so a step is never notified twice.
+func (r *ComplaintRepository) Escalate(id uint, fromLevel int, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Complaint{}).
+		Where("id = ? AND escalation_level = ? AND status IN ?", id, fromLevel, models.ComplaintOpenStatuses).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleStatus
+	}
+	return nil
+}
+
+// SLACompliance groups the complaints with a decided SLA outcome by an
+// expression, e.g. "category"
+func (r *ComplaintRepository) SLACompliance(group string) ([]SLARow, error) {
+	var rows []SLARow
+	err := r.db.Model(&models.Complaint{}).
+		Select(group + " AS key, COUNT(*) AS total, COUNT(*) FILTER (WHERE breached) AS breached").
+		Where("merged_into IS NULL AND sla_due_at IS NOT NULL AND (breached OR resolved_at IS NOT NULL)").
+		Group("key").
+		Order("key").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// SaveFeedback records the reporter's rating of a resolution. It only
+// applies while the complaint is in the status it was read in and not yet
+// rated, so a rating is never overwritten.
+func (r *ComplaintRepository) SaveFeedback(id uint, fromStatus string, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Complaint{}).
+		Where("id = ? AND status = ? AND feedback_rating IS NULL", id, fromStatus).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleStatus
+	}
+	return nil
+}
+
+// Satisfaction sums reporter ratings and reopens of complaints resolved at
+// least once, grouped by a SQL expression such as "category"
+func (r *ComplaintRepository) Satisfaction(group string) ([]SatisfactionRow, error) {
+	var rows []SatisfactionRow
+	err := r.db.Model(&models.Complaint{}).
+		Select(group + " AS key, COUNT(*) AS resolved, COUNT(feedback_rating) AS rated, " +
+			"COALESCE(SUM(feedback_rating), 0) AS rating_sum, COUNT(*) FILTER (WHERE reopen_count > 0) AS reopened").
+		Where("merged_into IS NULL AND (resolved_at IS NOT NULL OR reopen_count > 0)").
+		Group("key").
+		Order("key").
+		Find(&rows).Error
+	return rows, err
+}
+
+// CountBy returns the number of complaints grouped by a column. Complaints
+// merged into another are counted once, as their parent.
+func (r *ComplaintRepository) CountBy(column string) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	err := r.db.Model(&models.Complaint{}).
+		Select(column + " AS key, COUNT(*) AS count").
+		Where("merged_into IS NULL").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// AverageResolutionHours returns the mean time from filing to resolution of
+// complaints that are currently resolved or closed
+func (r *ComplaintRepository) AverageResolutionHours() (float64, error) {
+	var hours float64
+	err := r.db.Model(&models.Complaint{}).
+		Where("resolved_at IS NOT NULL AND merged_into IS NULL").
+		Select("COALESCE(AVG(EXTRACT(EPOCH FROM resolved_at - created_at)) / 3600, 0)").
+		Scan(&hours).Error
+	return hours, err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+func TestComplaintRadiusSearch(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	geo := &utils.GeoFilter{
+		Latitude:     18.52,
+		Longitude:    73.85,
+		RadiusMeters: 500,
+		Box:          utils.BoundingBox{MinLatitude: 18.51, MaxLatitude: 18.53, MinLongitude: 73.84, MaxLongitude: 73.86},
+	}
+
+	repo := NewComplaintRepository(db).ForTenant(3)
+	if _, _, err := repo.List(utils.ListQuery{Page: 1, Limit: 10}, map[string]interface{}{"category": "water"}, models.JurisdictionScope{All: true}, geo); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	for _, want := range []string{`"complaints"."panchayat_id" = 3`, "latitude BETWEEN 18.51 AND 18.53", "ASIN(SQRT(", "<= 500"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %q", want, sql)
+		}
+	}
+	if strings.Contains(sql, "ST_DWithin") {
+		t.Errorf("expected plain SQL without PostGIS, got %q", sql)
+	}
+
+	repo.SetPostGIS(true)
+	if _, _, err := repo.List(utils.ListQuery{Page: 1, Limit: 10}, nil, models.JurisdictionScope{All: true}, geo); err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); !strings.Contains(sql, "ST_DWithin(") || strings.Contains(sql, "ASIN(SQRT(") {
+		t.Errorf("expected a PostGIS distance search, got %q", sql)
+	}
+}
+
+func TestComplaintBoundingBoxSearch(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	geo := &utils.GeoFilter{Box: utils.BoundingBox{MinLatitude: 18, MaxLatitude: 19, MinLongitude: 73, MaxLongitude: 74}}
+
+	if _, err := NewComplaintRepository(db).ForTenant(3).ListLocated(nil, models.JurisdictionScope{All: true}, geo, 100); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "latitude BETWEEN 18 AND 19 AND longitude BETWEEN 73 AND 74") {
+		t.Errorf("expected a bounding box, got %q", sql)
+	}
+	if strings.Contains(sql, "ASIN(SQRT(") {
+		t.Errorf("a bounding box needs no distance, got %q", sql)
+	}
+}
+
+func TestHotspotsStayWithinTenant(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewComplaintRepository(db).ForTenant(6)
+
+	for _, postgis := range []bool{false, true} {
+		repo.SetPostGIS(postgis)
+		if _, err := repo.Hotspots(nil, models.JurisdictionScope{All: true}, 250, 3); err != nil {
+			t.Fatal(err)
+		}
+		sql := recorder.last(t)
+		if !strings.Contains(sql, `"complaints"."panchayat_id" = 6`) {
+			t.Errorf("postgis=%v: expected the tenant filter in %q", postgis, sql)
+		}
+		if postgis != strings.Contains(sql, "ST_ClusterDBSCAN") {
+			t.Errorf("postgis=%v: unexpected clustering in %q", postgis, sql)
+		}
+	}
+}
+
+func TestComplaintSatisfactionSkipsMergedComplaints(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewComplaintRepository(db).ForTenant(4)
+
+	if _, err := repo.Satisfaction("category"); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	for _, want := range []string{`"complaints"."panchayat_id" = 4`, "merged_into IS NULL", "COUNT(feedback_rating)", "GROUP BY"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %q", want, sql)
+		}
+	}
+}
+
+func TestDuplicateCandidatesStayInScope(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewComplaintRepository(db).ForTenant(3)
+
+	latitude, longitude := 18.52, 73.85
+	complaint := &models.Complaint{ID: 7, Category: "water", Village: "Shirur", Latitude: &latitude, Longitude: &longitude}
+	scope := models.JurisdictionScope{Villages: []string{"shirur"}}
+	if _, err := repo.FindDuplicateCandidates(complaint, scope, 300, time.Now().AddDate(0, 0, -14), 20); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	for _, want := range []string{`"complaints"."panchayat_id" = 3`, "LOWER(village) IN ('shirur')", "ASIN(SQRT("} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %q", want, sql)
+		}
+	}
+}
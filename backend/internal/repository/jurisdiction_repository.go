@@ -0,0 +1,36 @@
+// internal/repository/jurisdiction_repository.go
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+type JurisdictionRepository struct {
+	db *gorm.DB
+}
+
+func NewJurisdictionRepository(db *gorm.DB) *JurisdictionRepository {
+	return &JurisdictionRepository{db: db}
+}
+
+func (r *JurisdictionRepository) ListByUser(userID uint) ([]models.StaffJurisdiction, error) {
+	var jurisdictions []models.StaffJurisdiction
+	err := r.db.Where("user_id = ?", userID).
+		Order("level ASC, name ASC").
+		Find(&jurisdictions).Error
+	return jurisdictions, err
+}
+
+// Replace swaps a user's jurisdictions for the given list
+func (r *JurisdictionRepository) Replace(userID uint, jurisdictions []models.StaffJurisdiction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.StaffJurisdiction{}).Error; err != nil {
+			return err
+		}
+		if len(jurisdictions) == 0 {
+			return nil
+		}
+		return tx.Create(&jurisdictions).Error
+	})
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// ErrInsufficientStock is returned when more of a material is issued than is
+// in stock
+var ErrInsufficientStock = errors.New("not enough of the material in stock")
+
+type MaterialRepository struct {
+	db *gorm.DB
+}
+
+func NewMaterialRepository(db *gorm.DB) *MaterialRepository {
+	return &MaterialRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *MaterialRepository) ForTenant(panchayatID uint) *MaterialRepository {
+	return &MaterialRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// List returns a page of materials, optionally only those whose name
+// matches search
+func (r *MaterialRepository) List(list utils.ListQuery, search string) ([]models.Material, int64, error) {
+	var materials []models.Material
+	var total int64
+
+	query := r.db.Model(&models.Material{})
+	if search != "" {
+		query = query.Where(`name ILIKE ? ESCAPE '\'`, containsPattern(search))
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&materials).Error
+	return materials, total, err
+}
+
+func (r *MaterialRepository) GetByID(id uint) (*models.Material, error) {
+	var material models.Material
+	if err := r.db.First(&material, id).Error; err != nil {
+		return nil, err
+	}
+	return &material, nil
+}
+
+func (r *MaterialRepository) Create(material *models.Material) error {
+	return r.db.Create(material).Error
+}
+
+func (r *MaterialRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Material{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Restock adds quantity to the stock of a material
+func (r *MaterialRepository) Restock(id uint, quantity float64) error {
+	return r.Update(id, map[string]interface{}{"quantity": gorm.Expr("quantity + ?", quantity)})
+}
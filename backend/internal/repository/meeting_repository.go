@@ -1,6 +1,171 @@
+// internal/repository/meeting_repository.go
 package repository
 
-// TODO: restore original contents of meeting_repository.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// ErrStaleStatus is returned when a status transition is attempted on a
+// record whose status changed since it was read.
+var ErrStaleStatus = errors.New("record status has changed, reload and try again")
+
+type MeetingRepository struct {
+	db *gorm.DB
+}
+
+func NewMeetingRepository(db *gorm.DB) *MeetingRepository {
+	return &MeetingRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *MeetingRepository) ForTenant(panchayatID uint) *MeetingRepository {
+	return &MeetingRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// List returns a page of meetings ordered by schedule together with the total
+// number of meetings matching the filters. Supported filters are
+// "meeting_type", "status", "scheduled_from" and "scheduled_to" (time.Time).
+func (r *MeetingRepository) List(list utils.ListQuery, filters map[string]interface{}) ([]models.Meeting, int64, error) {
+	var meetings []models.Meeting
+	var total int64
+
+	query := r.applyFilters(r.db.Model(&models.Meeting{}), filters)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&meetings).Error
+	return meetings, total, err
+}
+
+func (r *MeetingRepository) applyFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	for key, value := range filters {
+		switch key {
+		case "meeting_type", "status":
+			query = query.Where(key+" = ?", value)
+		case "scheduled_from":
+			if from, ok := value.(time.Time); ok {
+				query = query.Where("scheduled_at >= ?", from)
+			}
+		case "scheduled_to":
+			if to, ok := value.(time.Time); ok {
+				query = query.Where("scheduled_at < ?", to)
+			}
+		}
+	}
+	return query
+}
+
+func (r *MeetingRepository) GetByID(id uint) (*models.Meeting, error) {
+	var meeting models.Meeting
+	err := r.db.Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).First(&meeting, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &meeting, nil
+}
+
+// ListForCalendar returns every meeting scheduled at or after since, in any
+// status, with its status history so calendar entries can be versioned.
+func (r *MeetingRepository) ListForCalendar(since time.Time, filters map[string]interface{}) ([]models.Meeting, error) {
+	var meetings []models.Meeting
+	err := r.applyFilters(r.db.Model(&models.Meeting{}), filters).
+		Preload("StatusHistory").
+		Where("scheduled_at >= ?", since).
+		Order("scheduled_at ASC").
+		Find(&meetings).Error
+	return meetings, err
+}
+
+func (r *MeetingRepository) Create(meeting *models.Meeting) error {
+	return r.db.Create(meeting).Error
+}
+
+func (r *MeetingRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.Meeting{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ChangeStatus moves a meeting from one status to another and records the
+// transition. The update only applies while the meeting is still in the
+// expected status, so two concurrent transitions cannot both succeed.
+func (r *MeetingRepository) ChangeStatus(id uint, fromStatus string, fields map[string]interface{}, change *models.MeetingStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Meeting{}).
+			Where("id = ? AND status = ?", id, fromStatus).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// minutesRevisionAttempts bounds the retries of CreateMinutes when another
+// writer takes the same revision number
+const minutesRevisionAttempts = 3
+
+// CreateMinutes stores a new revision of the minutes for a meeting, numbered
+// one past the latest. Two concurrent writers can both read the same latest
+// revision; the idx_meeting_minutes_revision unique index makes the second
+// insert fail, and it is retried with the next number.
+func (r *MeetingRepository) CreateMinutes(minutes *models.MeetingMinutes) error {
+	var err error
+	for attempt := 0; attempt < minutesRevisionAttempts; attempt++ {
+		var latest int
+		err = r.db.Model(&models.MeetingMinutes{}).
+			Where("meeting_id = ?", minutes.MeetingID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		minutes.Revision = latest + 1
+		err = r.db.Create(minutes).Error
+		if !isMinutesRevisionConflict(err) {
+			return err
+		}
+		minutes.ID = 0
+	}
+	return err
+}
+
+func isMinutesRevisionConflict(err error) bool {
+	return err != nil && (errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "idx_meeting_minutes_revision"))
+}
+
+// GetMinutesHistory returns every revision of the minutes for a meeting,
+// oldest first.
+func (r *MeetingRepository) GetMinutesHistory(meetingID uint) ([]models.MeetingMinutes, error) {
+	var minutes []models.MeetingMinutes
+	err := r.db.Where("meeting_id = ?", meetingID).Order("revision ASC").Find(&minutes).Error
+	return minutes, err
+}
+
+// GetLatestMinutes returns the most recent revision of the minutes.
+func (r *MeetingRepository) GetLatestMinutes(meetingID uint) (*models.MeetingMinutes, error) {
+	var minutes models.MeetingMinutes
+	if err := r.db.Where("meeting_id = ?", meetingID).Order("revision DESC").First(&minutes).Error; err != nil {
+		return nil, err
+	}
+	return &minutes, nil
+}
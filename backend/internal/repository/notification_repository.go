@@ -0,0 +1,60 @@
+// internal/repository/notification_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *NotificationRepository) ForTenant(panchayatID uint) *NotificationRepository {
+	return &NotificationRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// ListByUser returns a page of a user's notifications, optionally only the
+// unread ones
+func (r *NotificationRepository) ListByUser(userID uint, list utils.ListQuery, unreadOnly bool) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read = ?", false)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// MarkRead marks one of a user's notifications read
+func (r *NotificationRepository) MarkRead(id, userID uint) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{"read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// internal/repository/otp_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+type OTPRepository struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) *OTPRepository {
+	return &OTPRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *OTPRepository) ForTenant(panchayatID uint) *OTPRepository {
+	return &OTPRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// Replace invalidates any unused code for the same purpose and destination
+// and stores the new one, so only the latest code works.
+func (r *OTPRepository) Replace(code *models.OTPCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OTPCode{}).
+			Where("purpose = ? AND destination = ? AND consumed_at IS NULL", code.Purpose, code.Destination).
+			Update("expires_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+// GetLatest returns the most recently issued code for a purpose and destination
+func (r *OTPRepository) GetLatest(purpose, destination string) (*models.OTPCode, error) {
+	var code models.OTPCode
+	err := r.db.Where("purpose = ? AND destination = ?", purpose, destination).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// RecordAttempt counts one verification attempt. It reports false once the
+// code has no attempts left, so parallel guesses cannot exceed the limit.
+func (r *OTPRepository) RecordAttempt(id uint) (bool, error) {
+	result := r.db.Model(&models.OTPCode{}).
+		Where("id = ? AND attempts < max_attempts", id).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// Consume marks a code as used. It reports false if it was already used.
+func (r *OTPRepository) Consume(id uint) (bool, error) {
+	result := r.db.Model(&models.OTPCode{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountIssuedSince counts codes sent to a destination since the given time,
+// across all purposes.
+func (r *OTPRepository) CountIssuedSince(destination string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OTPCode{}).
+		Where("destination = ? AND created_at >= ?", destination, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,53 @@
+// internal/repository/panchayat_repository.go
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+type PanchayatRepository struct {
+	db *gorm.DB
+}
+
+func NewPanchayatRepository(db *gorm.DB) *PanchayatRepository {
+	return &PanchayatRepository{db: db}
+}
+
+// List returns every panchayat, active or not
+func (r *PanchayatRepository) List() ([]models.Panchayat, error) {
+	var panchayats []models.Panchayat
+	err := r.db.Order("id").Find(&panchayats).Error
+	return panchayats, err
+}
+
+func (r *PanchayatRepository) GetByID(id uint) (*models.Panchayat, error) {
+	var panchayat models.Panchayat
+	if err := r.db.First(&panchayat, id).Error; err != nil {
+		return nil, err
+	}
+	return &panchayat, nil
+}
+
+func (r *PanchayatRepository) GetBySlug(slug string) (*models.Panchayat, error) {
+	var panchayat models.Panchayat
+	if err := r.db.Where("slug = ?", slug).First(&panchayat).Error; err != nil {
+		return nil, err
+	}
+	return &panchayat, nil
+}
+
+func (r *PanchayatRepository) Create(panchayat *models.Panchayat) error {
+	return r.db.Create(panchayat).Error
+}
+
+func (r *PanchayatRepository) Update(id uint, updates map[string]interface{}) error {
+	result := r.db.Model(&models.Panchayat{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
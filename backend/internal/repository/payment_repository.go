@@ -0,0 +1,147 @@
+// internal/repository/payment_repository.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+var ErrOverpayment = errors.New("amount exceeds the balance due on the bill")
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *PaymentRepository) ForTenant(panchayatID uint) *PaymentRepository {
+	return &PaymentRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// RevenueRow is the money collected in one reporting period
+type RevenueRow struct {
+	Period   time.Time `json:"period"`
+	Total    float64   `json:"total"`
+	Payments int64     `json:"payments"`
+}
+
+// Record stores a payment and applies it to its bill. The bill row is locked
+// so concurrent payments cannot overpay it.
+func (r *PaymentRepository) Record(payment *models.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bill models.TaxBill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bill, payment.BillID).Error; err != nil {
+			return err
+		}
+		if payment.Amount > bill.Balance()+0.005 {
+			return ErrOverpayment
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		receipt := fmt.Sprintf("RCPT-%s-%06d", payment.PaidAt.Format("20060102"), payment.ID)
+		if err := tx.Model(payment).Update("receipt_number", receipt).Error; err != nil {
+			return err
+		}
+		payment.ReceiptNumber = receipt
+
+		paid := bill.PaidAmount + payment.Amount
+		status := models.BillStatusPartiallyPaid
+		if paid >= bill.Amount-0.005 {
+			status = models.BillStatusPaid
+		}
+		return tx.Model(&bill).Updates(map[string]interface{}{
+			"paid_amount": paid,
+			"status":      status,
+		}).Error
+	})
+}
+
+func (r *PaymentRepository) GetByID(id uint) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.db.Preload("Bill.Property").First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// List returns a page of payments whose property lies within the scope.
+// Supported filters are property_id, owner_id and status.
+func (r *PaymentRepository) List(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Payment, int64, error) {
+	var payments []models.Payment
+	var total int64
+
+	query := r.filtered(filters, scope)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Bill.Property").Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&payments).Error
+	return payments, total, err
+}
+
+// ListAfter returns up to limit payments paid before the cursor, newest
+// first. Unlike List it does not count or skip rows, so it stays fast deep
+// into large histories.
+func (r *PaymentRepository) ListAfter(cursor utils.Cursor, limit int, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Payment, error) {
+	var payments []models.Payment
+
+	query := r.filtered(filters, scope)
+	if cursor.ID != 0 {
+		query = query.Where("(paid_at, id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+
+	err := query.Preload("Bill.Property").Order("paid_at DESC, id DESC").Limit(limit).Find(&payments).Error
+	return payments, err
+}
+
+func (r *PaymentRepository) filtered(filters map[string]interface{}, scope models.JurisdictionScope) *gorm.DB {
+	query := r.db.Model(&models.Payment{})
+	if propertyID, ok := filters["property_id"]; ok {
+		query = query.Where("bill_id IN (SELECT id FROM tax_bills WHERE property_id = ?)", propertyID)
+	}
+	if ownerID, ok := filters["owner_id"]; ok {
+		query = query.Where("bill_id IN (SELECT tb.id FROM tax_bills tb JOIN properties p ON p.id = tb.property_id WHERE p.owner_id = ?)", ownerID)
+	}
+	if status, ok := filters["status"]; ok {
+		query = query.Where("status = ?", status)
+	}
+
+	if cond, args, ok := scopeCondition(scope, "p.village", "p.taluka"); ok {
+		query = query.Where("bill_id IN (SELECT tb.id FROM tax_bills tb JOIN properties p ON p.id = tb.property_id WHERE "+cond+")", args...)
+	}
+	return query
+}
+
+// Revenue totals successful payments per period ("month", "quarter" or
+// "year") between from (inclusive) and to (exclusive)
+func (r *PaymentRepository) Revenue(from, to time.Time, period string) ([]RevenueRow, error) {
+	var rows []RevenueRow
+	err := r.db.Model(&models.Payment{}).
+		Select("date_trunc(?, paid_at) AS period, SUM(amount) AS total, COUNT(*) AS payments", period).
+		Where("status = ? AND paid_at >= ? AND paid_at < ?", models.PaymentStatusSuccess, from, to).
+		Group("period").
+		Order("period ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// SumSince returns the money collected since the given time
+func (r *PaymentRepository) SumSince(since time.Time) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.Payment{}).
+		Where("status = ? AND paid_at >= ?", models.PaymentStatusSuccess, since).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -1,6 +1,140 @@
+// internal/repository/property_repository.go
 package repository
 
-// TODO: restore original contents of property_repository.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+type PropertyRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyRepository(db *gorm.DB) *PropertyRepository {
+	return &PropertyRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *PropertyRepository) ForTenant(panchayatID uint) *PropertyRepository {
+	return &PropertyRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// List returns a page of properties matching the filters within the scope
+func (r *PropertyRepository) List(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Property, int64, error) {
+	var properties []models.Property
+	var total int64
+
+	query := applyScope(r.db.Model(&models.Property{}).Where(filters), scope, "village", "taluka")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&properties).Error
+	return properties, total, err
+}
+
+func (r *PropertyRepository) GetByID(id uint) (*models.Property, error) {
+	var property models.Property
+	if err := r.db.First(&property, id).Error; err != nil {
+		return nil, err
+	}
+	return &property, nil
+}
+
+func (r *PropertyRepository) Create(property *models.Property) error {
+	return r.db.Create(property).Error
+}
+
+func (r *PropertyRepository) Update(id uint, updates map[string]interface{}) error {
+	result := r.db.Model(&models.Property{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountBy returns the number of active properties grouped by a column
+func (r *PropertyRepository) CountBy(column string) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	err := r.db.Model(&models.Property{}).
+		Where("is_active = ?", true).
+		Select(column + " AS key, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// SumAnnualTax returns the total annual tax assessed on active properties
+func (r *PropertyRepository) SumAnnualTax() (float64, error) {
+	var total float64
+	err := r.db.Model(&models.Property{}).
+		Where("is_active = ?", true).
+		Select("COALESCE(SUM(annual_tax_amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *PropertyRepository) ListBills(propertyID uint) ([]models.TaxBill, error) {
+	var bills []models.TaxBill
+	err := r.db.Where("property_id = ?", propertyID).
+		Order("financial_year DESC, quarter DESC").
+		Find(&bills).Error
+	return bills, err
+}
+
+func (r *PropertyRepository) GetBill(id uint) (*models.TaxBill, error) {
+	var bill models.TaxBill
+	if err := r.db.Preload("Property").First(&bill, id).Error; err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
+
+func (r *PropertyRepository) CreateBill(bill *models.TaxBill) error {
+	return r.db.Create(bill).Error
+}
+
+// ListDueBills returns unpaid bills, optionally limited to one owner or one
+// property (zero means any)
+func (r *PropertyRepository) ListDueBills(ownerID, propertyID uint) ([]models.TaxBill, error) {
+	var bills []models.TaxBill
+	query := r.db.Preload("Property").
+		Joins("JOIN properties ON properties.id = tax_bills.property_id").
+		Where("tax_bills.status <> ?", models.BillStatusPaid)
+	if ownerID != 0 {
+		query = query.Where("properties.owner_id = ?", ownerID)
+	}
+	if propertyID != 0 {
+		query = query.Where("tax_bills.property_id = ?", propertyID)
+	}
+	err := query.Order("tax_bills.due_date ASC").Find(&bills).Error
+	return bills, err
+}
+
+// SumOutstanding returns the unpaid balance of bills due before the given time
+func (r *PropertyRepository) SumOutstanding(dueBefore time.Time) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.TaxBill{}).
+		Where("status <> ? AND due_date < ?", models.BillStatusPaid, dueBefore).
+		Select("COALESCE(SUM(amount - paid_amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,111 @@
+// internal/repository/resolution_repository.go
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+type ResolutionRepository struct {
+	db *gorm.DB
+}
+
+func NewResolutionRepository(db *gorm.DB) *ResolutionRepository {
+	return &ResolutionRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *ResolutionRepository) ForTenant(panchayatID uint) *ResolutionRepository {
+	return &ResolutionRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// Create stores a resolution with the next number of its financial year.
+// The per-year counter row is incremented atomically, so numbers are
+// gap-free and never reused even under concurrent inserts.
+func (r *ResolutionRepository) Create(resolution *models.Resolution) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		panchayatID, err := requireTenant(tx)
+		if err != nil {
+			return err
+		}
+
+		var number int
+		err = tx.Raw(`INSERT INTO resolution_sequences (panchayat_id, financial_year, last_number) VALUES (?, ?, 1)
+			ON CONFLICT (panchayat_id, financial_year) DO UPDATE SET last_number = resolution_sequences.last_number + 1
+			RETURNING last_number`, panchayatID, resolution.FinancialYear).Scan(&number).Error
+		if err != nil {
+			return err
+		}
+
+		resolution.Number = number
+		return tx.Create(resolution).Error
+	})
+}
+
+func (r *ResolutionRepository) GetByID(id uint) (*models.Resolution, error) {
+	var resolution models.Resolution
+	err := r.db.Preload("Meeting").Preload("Mover").Preload("Seconder").
+		Preload("Votes.User").
+		First(&resolution, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resolution, nil
+}
+
+// List returns a page of resolutions matching the filters. Supported
+// filters are "meeting_id", "financial_year", "number", "status" and
+// "search" (matched against title and text).
+func (r *ResolutionRepository) List(list utils.ListQuery, filters map[string]interface{}) ([]models.Resolution, int64, error) {
+	var resolutions []models.Resolution
+	var total int64
+
+	query := r.db.Model(&models.Resolution{})
+	for key, value := range filters {
+		switch key {
+		case "meeting_id", "financial_year", "number", "status":
+			query = query.Where(key+" = ?", value)
+		case "search":
+			pattern := containsPattern(value.(string))
+			query = query.Where(`title ILIKE ? ESCAPE '\' OR text ILIKE ? ESCAPE '\'`, pattern, pattern)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Mover").Preload("Seconder").
+		Order(list.OrderBy()).
+		Limit(list.Limit).Offset(list.Offset()).
+		Find(&resolutions).Error
+	return resolutions, total, err
+}
+
+// ReplaceVotes replaces every vote on a resolution and stores the new tally
+// and outcome in the same transaction. With fromStatus set, it only applies
+// while the resolution still has that status.
+func (r *ResolutionRepository) ReplaceVotes(resolutionID uint, fromStatus string, votes []models.ResolutionVote, fields map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Resolution{}).Where("id = ?", resolutionID)
+		if fromStatus != "" {
+			query = query.Where("status = ?", fromStatus)
+		}
+		result := query.Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+
+		if err := tx.Where("resolution_id = ?", resolutionID).Delete(&models.ResolutionVote{}).Error; err != nil {
+			return err
+		}
+		if len(votes) == 0 {
+			return nil
+		}
+		return tx.Create(&votes).Error
+	})
+}
@@ -0,0 +1,103 @@
+// internal/repository/role_repository.go
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gram-panchayat/internal/models"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *RoleRepository) ForTenant(panchayatID uint) *RoleRepository {
+	return &RoleRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+func (r *RoleRepository) List() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.Preload("Grants").Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].Permissions = roles[i].PermissionNames()
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) GetByName(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Grants").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	role.Permissions = role.PermissionNames()
+	return &role, nil
+}
+
+// Create inserts the role together with its permissions
+func (r *RoleRepository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+func (r *RoleRepository) Update(id uint, updates map[string]interface{}) error {
+	result := r.db.Model(&models.Role{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetPermissions replaces the role's permissions
+func (r *RoleRepository) SetPermissions(roleID uint, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+
+		rows := make([]models.RolePermission, 0, len(permissions))
+		for _, p := range permissions {
+			rows = append(rows, models.RolePermission{RoleID: roleID, Permission: p})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// AddPermissions grants permissions the role does not have yet
+func (r *RoleRepository) AddPermissions(roleID uint, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	rows := make([]models.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		rows = append(rows, models.RolePermission{RoleID: roleID, Permission: p})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func (r *RoleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, id).Error
+	})
+}
+
+// CountUsers returns how many accounts hold the role
+func (r *RoleRepository) CountUsers(name string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,38 @@
+// internal/repository/scope.go
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+// scopeCondition returns a WHERE fragment restricting rows to the scope's
+// villages and talukas. ok is false when the scope is unrestricted.
+func scopeCondition(scope models.JurisdictionScope, villageColumn, talukaColumn string) (query string, args []interface{}, ok bool) {
+	if scope.All {
+		return "", nil, false
+	}
+
+	// An empty scope must match nothing rather than everything
+	villages := scope.Villages
+	if len(villages) == 0 {
+		villages = []string{""}
+	}
+	talukas := scope.Talukas
+	if len(talukas) == 0 {
+		talukas = []string{""}
+	}
+
+	query = "(LOWER(" + villageColumn + ") IN ? AND " + villageColumn + " <> '') OR " +
+		"(LOWER(" + talukaColumn + ") IN ? AND " + talukaColumn + " <> '')"
+	return query, []interface{}{villages, talukas}, true
+}
+
+// applyScope restricts a query on a table with village and taluka columns
+func applyScope(db *gorm.DB, scope models.JurisdictionScope, villageColumn, talukaColumn string) *gorm.DB {
+	query, args, ok := scopeCondition(scope, villageColumn, talukaColumn)
+	if !ok {
+		return db
+	}
+	return db.Where(query, args...)
+}
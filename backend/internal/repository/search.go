@@ -0,0 +1,13 @@
+package repository
+
+import "strings"
+
+// likeEscaper escapes the characters LIKE treats specially, so user text
+// matches only itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns an ILIKE pattern matching values that contain
+// search. Use it with ESCAPE '\'.
+func containsPattern(search string) string {
+	return "%" + likeEscaper.Replace(search) + "%"
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "testing"
+
+func TestContainsPatternEscapesWildcards(t *testing.T) {
+	for search, want := range map[string]string{
+		"road":      "%road%",
+		"100%":      `%100\%%`,
+		"ward_2":    `%ward\_2%`,
+		`C:\panels`: `%C:\\panels%`,
+	} {
+		if got := containsPattern(search); got != want {
+			t.Errorf("containsPattern(%q) = %q, want %q", search, got, want)
+		}
+	}
+}
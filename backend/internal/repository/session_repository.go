@@ -0,0 +1,71 @@
+// internal/repository/session_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *SessionRepository) ForTenant(panchayatID uint) *SessionRepository {
+	return &SessionRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *SessionRepository) GetByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActive returns the sessions of a user that are neither revoked nor
+// expired, most recently used first.
+func (r *SessionRepository) ListActive(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records that the session was used from the given IP address
+func (r *SessionRepository) Touch(id uint, ipAddress string) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   ipAddress,
+	}).Error
+}
+
+func (r *SessionRepository) Revoke(id uint, reason string) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// RevokeDevice revokes the active sessions of a user on one device
+func (r *SessionRepository) RevokeDevice(userID uint, deviceID, reason string) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userID, deviceID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+func (r *SessionRepository) RevokeAllForUser(userID uint, reason string) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
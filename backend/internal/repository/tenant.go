@@ -0,0 +1,156 @@
+// internal/repository/tenant.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantField is the field that marks a model as owned by a panchayat
+const tenantField = "PanchayatID"
+
+var (
+	// ErrTenantRequired is returned when a panchayat-owned table is queried
+	// through a handle that is not bound to a panchayat
+	ErrTenantRequired = errors.New("no panchayat selected for this query")
+	// ErrTenantMismatch is returned when a record of one panchayat is saved
+	// through a handle bound to another
+	ErrTenantMismatch = errors.New("record belongs to another panchayat")
+)
+
+type tenantKey struct{}
+
+// tenantFilteredKey marks a statement that already carries the tenant filter
+const tenantFilteredKey = "tenant:filtered"
+
+// allTenants marks a handle that may deliberately read and write every
+// panchayat, e.g. for migrations and background jobs
+type allTenants struct{}
+
+// WithTenant binds a database handle to one panchayat. Queries on models with
+// a PanchayatID field are filtered to it and new records are stamped with it.
+func WithTenant(db *gorm.DB, panchayatID uint) *gorm.DB {
+	return db.WithContext(context.WithValue(statementContext(db), tenantKey{}, panchayatID))
+}
+
+// WithAllTenants returns a handle that is not restricted to one panchayat
+func WithAllTenants(db *gorm.DB) *gorm.DB {
+	return db.WithContext(context.WithValue(statementContext(db), tenantKey{}, allTenants{}))
+}
+
+// TenantOf returns the panchayat a handle is bound to
+func TenantOf(db *gorm.DB) (uint, bool) {
+	id, ok := statementContext(db).Value(tenantKey{}).(uint)
+	return id, ok
+}
+
+// requireTenant is for hand-written SQL the callbacks cannot rewrite
+func requireTenant(db *gorm.DB) (uint, error) {
+	id, ok := TenantOf(db)
+	if !ok {
+		return 0, ErrTenantRequired
+	}
+	return id, nil
+}
+
+func statementContext(db *gorm.DB) context.Context {
+	if db.Statement != nil && db.Statement.Context != nil {
+		return db.Statement.Context
+	}
+	return context.Background()
+}
+
+// RegisterTenantCallbacks installs the callbacks that keep panchayats apart.
+// It must be called once on the root handle before any repository is used.
+func RegisterTenantCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", stampTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", filterTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", filterTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", filterTenant); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("tenant:row", filterTenant)
+}
+
+func tenantOwned(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(tenantField)
+}
+
+// filterTenant adds "panchayat_id = ?" to statements on panchayat-owned tables
+func filterTenant(db *gorm.DB) {
+	field := tenantOwned(db)
+	if field == nil || db.Error != nil {
+		return
+	}
+
+	switch tenant := statementContext(db).Value(tenantKey{}).(type) {
+	case uint:
+		// A chained query executed twice (Count then Find) keeps its clauses
+		if _, filtered := db.Statement.Settings.LoadOrStore(tenantFilteredKey, true); filtered {
+			return
+		}
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: tenant},
+		}})
+	case allTenants:
+	default:
+		db.AddError(fmt.Errorf("%w: %s", ErrTenantRequired, db.Statement.Table))
+	}
+}
+
+// stampTenant sets PanchayatID on new records and refuses records that name
+// a different panchayat
+func stampTenant(db *gorm.DB) {
+	field := tenantOwned(db)
+	if field == nil || db.Error != nil {
+		return
+	}
+
+	switch tenant := statementContext(db).Value(tenantKey{}).(type) {
+	case uint:
+		ctx := db.Statement.Context
+		stamp := func(rv reflect.Value) {
+			current, zero := field.ValueOf(ctx, rv)
+			if !zero && current != tenant {
+				db.AddError(ErrTenantMismatch)
+				return
+			}
+			if err := field.Set(ctx, rv, tenant); err != nil {
+				db.AddError(err)
+			}
+		}
+
+		switch rv := db.Statement.ReflectValue; rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				stamp(reflect.Indirect(rv.Index(i)))
+			}
+		case reflect.Struct:
+			stamp(rv)
+		}
+	case allTenants:
+		// Records created across panchayats must name their panchayat
+		if rv := db.Statement.ReflectValue; rv.Kind() == reflect.Struct {
+			if _, zero := field.ValueOf(db.Statement.Context, rv); zero {
+				db.AddError(ErrTenantRequired)
+			}
+		}
+	default:
+		db.AddError(fmt.Errorf("%w: %s", ErrTenantRequired, db.Statement.Table))
+	}
+}
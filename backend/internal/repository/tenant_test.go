@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// sqlRecorder is a gorm logger that keeps the SQL of every statement
+type sqlRecorder struct {
+	mu   sync.Mutex
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	r.sqls = append(r.sqls, sql)
+	r.mu.Unlock()
+}
+
+func (r *sqlRecorder) last(t *testing.T) string {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.sqls) == 0 {
+		t.Fatal("no SQL was generated")
+	}
+	return r.sqls[len(r.sqls)-1]
+}
+
+// newDryRunDB returns a PostgreSQL handle that builds SQL without a server
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTenantCallbacks(db); err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+func TestTenantQueriesAreFiltered(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	tenant := WithTenant(db, 7)
+
+	for name, dest := range map[string]interface{}{
+		"users":      &[]models.User{},
+		"properties": &[]models.Property{},
+		"tax_bills":  &[]models.TaxBill{},
+		"payments":   &[]models.Payment{},
+		"complaints": &[]models.Complaint{},
+		"notices":    &[]models.Notice{},
+		"meetings":   &[]models.Meeting{},
+		"schemes":    &[]models.Scheme{},
+		"roles":      &[]models.Role{},
+		"sessions":   &[]models.Session{},
+		"otp_codes":  &[]models.OTPCode{},
+
+		"meeting_attendances": &[]models.MeetingAttendance{},
+	} {
+		if err := tenant.Find(dest).Error; err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := `"` + name + `"."panchayat_id" = 7`
+		if sql := recorder.last(t); !strings.Contains(sql, want) {
+			t.Errorf("%s: expected %s in %q", name, want, sql)
+		}
+	}
+}
+
+func TestTenantFilterAppliesToUpdatesAndDeletes(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	tenant := WithTenant(db, 3)
+
+	if err := tenant.Model(&models.Property{}).Where("id = ?", 1).Update("is_active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); !strings.Contains(sql, `"properties"."panchayat_id" = 3`) {
+		t.Errorf("update is not filtered: %q", sql)
+	}
+
+	if err := tenant.Delete(&models.Notice{}, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); !strings.Contains(sql, `"notices"."panchayat_id" = 3`) {
+		t.Errorf("delete is not filtered: %q", sql)
+	}
+}
+
+func TestCountThenFindFiltersOnce(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+
+	query := WithTenant(db, 4).Model(&models.Complaint{}).Where("status = ?", "open")
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	var complaints []models.Complaint
+	if err := query.Find(&complaints).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); strings.Count(sql, "panchayat_id") != 1 {
+		t.Errorf("expected a single tenant condition in %q", sql)
+	}
+}
+
+func TestCrossTenantReadsFail(t *testing.T) {
+	db, _ := newDryRunDB(t)
+
+	// A handle that names no panchayat cannot read panchayat-owned tables
+	var properties []models.Property
+	if err := db.Find(&properties).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got %v", err)
+	}
+
+	if _, err := NewPropertyRepository(db).GetByID(1); !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired from an unbound repository, got %v", err)
+	}
+	if _, _, err := NewComplaintRepository(db).List(utils.ListQuery{Page: 1, Limit: 10}, nil, models.JurisdictionScope{All: true}, nil); !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired from an unbound repository, got %v", err)
+	}
+
+	// Tables that are not panchayat-owned stay readable
+	var panchayats []models.Panchayat
+	if err := db.Find(&panchayats).Error; err != nil {
+		t.Fatalf("panchayats should not need a panchayat: %v", err)
+	}
+}
+
+func TestRepositoryForTenantReadsOnlyThatTenant(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewPropertyRepository(db)
+
+	repo.ForTenant(2).GetByID(10)
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `"properties"."panchayat_id" = 2`) || !strings.Contains(sql, `"properties"."id" = 10`) {
+		t.Errorf("expected a lookup of property 10 in panchayat 2, got %q", sql)
+	}
+
+	// Binding one copy does not leak into the shared repository
+	if _, err := repo.GetByID(10); !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected the original repository to stay unbound, got %v", err)
+	}
+
+	NewUserRepository(db).ForTenant(5).GetByPhone("9876543210")
+	if sql := recorder.last(t); !strings.Contains(sql, `"users"."panchayat_id" = 5`) {
+		t.Errorf("expected the phone lookup to be limited to panchayat 5, got %q", sql)
+	}
+}
+
+func TestCreateStampsTenant(t *testing.T) {
+	db, _ := newDryRunDB(t)
+
+	complaint := &models.Complaint{Title: "Street light"}
+	if err := WithTenant(db, 9).Create(complaint).Error; err != nil {
+		t.Fatal(err)
+	}
+	if complaint.PanchayatID != 9 {
+		t.Errorf("expected panchayat 9, got %d", complaint.PanchayatID)
+	}
+
+	bills := []models.TaxBill{{FinancialYear: "2024-25"}, {FinancialYear: "2025-26"}}
+	if err := WithTenant(db, 9).Create(&bills).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, bill := range bills {
+		if bill.PanchayatID != 9 {
+			t.Errorf("expected every bill in panchayat 9, got %d", bill.PanchayatID)
+		}
+	}
+}
+
+func TestCreateRejectsOtherTenantsRecords(t *testing.T) {
+	db, _ := newDryRunDB(t)
+
+	property := &models.Property{PanchayatID: 1}
+	if err := WithTenant(db, 2).Create(property).Error; !errors.Is(err, ErrTenantMismatch) {
+		t.Fatalf("expected ErrTenantMismatch, got %v", err)
+	}
+
+	if err := db.Create(&models.Property{}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got %v", err)
+	}
+	if err := WithAllTenants(db).Create(&models.Property{}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected records created across panchayats to name one, got %v", err)
+	}
+	if err := WithAllTenants(db).Create(&models.Property{PanchayatID: 1}).Error; err != nil {
+		t.Fatalf("expected a record naming its panchayat to be created, got %v", err)
+	}
+}
+
+func TestAllTenantsIsUnfiltered(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+
+	var complaints []models.Complaint
+	if err := WithAllTenants(db).Find(&complaints).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sql := recorder.last(t); strings.Contains(sql, "panchayat_id") {
+		t.Errorf("expected no tenant filter, got %q", sql)
+	}
+}
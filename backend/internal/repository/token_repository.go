@@ -0,0 +1,38 @@
+// internal/repository/token_repository.go
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *RefreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a token as used. It reports false when the token was already
+// used, which is how concurrent reuse of one token is detected.
+func (r *RefreshTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
@@ -1,6 +1,153 @@
+// internal/repository/user_repository.go
 package repository
 
-// TODO: restore original contents of user_repository.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *UserRepository) ForTenant(panchayatID uint) *UserRepository {
+	return &UserRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepository) GetByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByEmail never matches on an empty email, since accounts registered with
+// a verified phone number may not have one.
+func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+	if email == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByPhone(phoneNumber string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("phone_number = ?", phoneNumber).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List returns a page of users matching the filters within the scope. search
+// is matched against name, email and phone number.
+func (r *UserRepository) List(list utils.ListQuery, filters map[string]interface{}, search string, scope models.JurisdictionScope) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := applyScope(r.db.Model(&models.User{}).Where(filters), scope, "village", "taluka")
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("first_name ILIKE ? OR last_name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&users).Error
+	return users, total, err
+}
+
+func (r *UserRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountBy returns the number of users grouped by a column, e.g. "role"
+func (r *UserRepository) CountBy(column string) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	err := r.db.Model(&models.User{}).
+		Select(column + " AS key, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// ListActiveByRole returns the active users holding a role
+func (r *UserRepository) ListActiveByRole(role string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("role = ? AND is_active = ?", role, true).Order("id").Find(&users).Error
+	return users, err
+}
+
+// ExistingIDs returns which of the given user IDs belong to existing users
+func (r *UserRepository) ExistingIDs(ids []uint) (map[uint]bool, error) {
+	var found []uint
+	if err := r.db.Model(&models.User{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+func (r *UserRepository) Count(filters map[string]interface{}) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where(filters).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) CountCreatedSince(since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("created_at >= ?", since).Count(&count).Error
+	return count, err
+}
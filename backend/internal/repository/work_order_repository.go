@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// ErrComplaintUnavailable is returned when a complaint cannot be put on a work
+// order: it is resolved, merged or already on another work order
+var ErrComplaintUnavailable = errors.New("complaint is resolved, merged or already on a work order")
+
+type WorkOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkOrderRepository(db *gorm.DB) *WorkOrderRepository {
+	return &WorkOrderRepository{db: db}
+}
+
+// ForTenant returns a copy of the repository bound to one panchayat
+func (r *WorkOrderRepository) ForTenant(panchayatID uint) *WorkOrderRepository {
+	return &WorkOrderRepository{db: WithTenant(r.db, panchayatID)}
+}
+
+// WorkOrderCostRow totals the completed work orders of one group
+type WorkOrderCostRow struct {
+	Key          string  `json:"key"`
+	WorkOrders   int64   `json:"work_orders"`
+	Complaints   int64   `json:"complaints"`
+	LaborCost    float64 `json:"labor_cost"`
+	MaterialCost float64 `json:"material_cost"`
+	TotalCost    float64 `json:"total_cost"`
+}
+
+// List returns a page of work orders matching the filters within the scope.
+// Filters are matched for equality, e.g. "status", "category" and "ward".
+func (r *WorkOrderRepository) List(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.WorkOrder, int64, error) {
+	var workOrders []models.WorkOrder
+	var total int64
+
+	query := applyScope(r.db.Model(&models.WorkOrder{}).Where(filters), scope, "village", "taluka")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(list.OrderBy()).Limit(list.Limit).Offset(list.Offset()).Find(&workOrders).Error
+	return workOrders, total, err
+}
+
+// GetByID loads a work order with its complaints, materials and photos
+func (r *WorkOrderRepository) GetByID(id uint) (*models.WorkOrder, error) {
+	var workOrder models.WorkOrder
+	err := r.db.
+		Preload("Complaints", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Materials", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&workOrder, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workOrder, nil
+}
+
+// Create stores a work order and links its complaints to it. Every complaint
+// must still be unresolved, unmerged and on no other planned or in-progress
+// work order; one reopened after its work order was completed moves to the
+// new one.
+func (r *WorkOrderRepository) Create(workOrder *models.WorkOrder, complaintIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workOrder).Error; err != nil {
+			return err
+		}
+		active := tx.Model(&models.WorkOrder{}).Select("id").Where("status IN ?", models.WorkOrderActiveStatuses)
+		result := tx.Model(&models.Complaint{}).
+			Where("id IN ? AND merged_into IS NULL AND status IN ?", complaintIDs, models.ComplaintOpenStatuses).
+			Where("work_order_id IS NULL OR work_order_id NOT IN (?)", active).
+			Update("work_order_id", workOrder.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(complaintIDs)) {
+			return ErrComplaintUnavailable
+		}
+		return nil
+	})
+}
+
+func (r *WorkOrderRepository) Update(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.WorkOrder{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ChangeStatus moves a work order on from the status it was read in. A
+// cancelled work order releases its complaints for another.
+func (r *WorkOrderRepository) ChangeStatus(id uint, fromStatus string, fields map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WorkOrder{}).
+			Where("id = ? AND status = ?", id, fromStatus).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		if fields["status"] != models.WorkOrderStatusCancelled {
+			return nil
+		}
+		return tx.Model(&models.Complaint{}).Where("work_order_id = ?", id).Update("work_order_id", nil).Error
+	})
+}
+
+// IssueMaterial draws a quantity of a material from stock for a work order
+// while it is planned or in progress, and adds its cost to the work order
+func (r *WorkOrderRepository) IssueMaterial(line *models.WorkOrderMaterial) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var material models.Material
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&material, line.MaterialID).Error; err != nil {
+			return err
+		}
+		if line.Quantity > material.Quantity {
+			return ErrInsufficientStock
+		}
+		if err := tx.Model(&material).Update("quantity", gorm.Expr("quantity - ?", line.Quantity)).Error; err != nil {
+			return err
+		}
+
+		line.Name = material.Name
+		line.Unit = material.Unit
+		line.UnitCost = material.UnitCost
+		line.Cost = line.Quantity * material.UnitCost
+		result := tx.Model(&models.WorkOrder{}).
+			Where("id = ? AND status IN ?", line.WorkOrderID, []string{models.WorkOrderStatusPlanned, models.WorkOrderStatusInProgress}).
+			Update("material_cost", gorm.Expr("material_cost + ?", line.Cost))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleStatus
+		}
+		return tx.Create(line).Error
+	})
+}
+
+func (r *WorkOrderRepository) AddPhoto(photo *models.WorkOrderPhoto) error {
+	return r.db.Create(photo).Error
+}
+
+// CountPhotos counts a work order's photos of one stage
+func (r *WorkOrderRepository) CountPhotos(id uint, stage string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WorkOrderPhoto{}).Where("work_order_id = ? AND stage = ?", id, stage).Count(&count).Error
+	return count, err
+}
+
+// CostReport totals the work orders completed between from (inclusive) and
+// to (exclusive), grouped by a SQL expression such as "category"
+func (r *WorkOrderRepository) CostReport(group string, from, to time.Time) ([]WorkOrderCostRow, error) {
+	var rows []WorkOrderCostRow
+	err := r.db.Model(&models.WorkOrder{}).
+		Select(group+" AS key, COUNT(*) AS work_orders, COALESCE(SUM(linked.complaints), 0) AS complaints, "+
+			"SUM(labor_cost) AS labor_cost, SUM(material_cost) AS material_cost, SUM(labor_cost + material_cost) AS total_cost").
+		Joins("LEFT JOIN (SELECT work_order_id, COUNT(*) AS complaints FROM complaints WHERE work_order_id IS NOT NULL GROUP BY work_order_id) linked "+
+			"ON linked.work_order_id = work_orders.id").
+		Where("status = ? AND completed_at >= ? AND completed_at < ?", models.WorkOrderStatusCompleted, from, to).
+		Group("key").
+		Order("total_cost DESC, key").
+		Find(&rows).Error
+	return rows, err
+}
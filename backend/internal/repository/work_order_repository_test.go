@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkOrderCostReportCountsCompletedWork(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewWorkOrderRepository(db).ForTenant(2)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.CostReport("category", from, from.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	sql := recorder.last(t)
+	for _, want := range []string{`"work_orders"."panchayat_id" = 2`, "status = 'completed'", "LEFT JOIN (SELECT work_order_id", "SUM(labor_cost + material_cost)"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %q", want, sql)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+// maxFormFields bounds the number of fields on one application form
+const maxFormFields = 50
+
+var (
+	formFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+	formPhonePattern     = regexp.MustCompile(`^[6-9][0-9]{9}$`)
+)
+
+var formFieldTypes = map[string]bool{
+	models.FormTypeString:  true,
+	models.FormTypeNumber:  true,
+	models.FormTypeInteger: true,
+	models.FormTypeBoolean: true,
+}
+
+var formFormats = map[string]bool{
+	models.FormFormatDate:  true,
+	models.FormFormatEmail: true,
+	models.FormFormatPhone: true,
+}
+
+// formErrors collects the rejected fields of a form, named by their path in
+// the request body
+type formErrors struct {
+	prefix string
+	fields []utils.FieldError
+}
+
+func (e *formErrors) add(field, message string) {
+	e.fields = append(e.fields, utils.FieldError{Field: e.prefix + field, Message: message})
+}
+
+// err returns the collected fields sorted by name, or nil if there are none
+func (e *formErrors) err() error {
+	if len(e.fields) == 0 {
+		return nil
+	}
+	sort.Slice(e.fields, func(i, j int) bool { return e.fields[i].Field < e.fields[j].Field })
+	return &utils.ValidationError{Fields: e.fields}
+}
+
+// validateFormData checks an applicant's answers against a form and returns
+// them cleaned up, strings trimmed and integers stored as such. Fields the
+// form does not declare are rejected.
+func validateFormData(schema models.FormSchema, data map[string]interface{}) (map[string]interface{}, error) {
+	errs := &formErrors{prefix: "form_data."}
+	cleaned := make(map[string]interface{}, len(data))
+
+	for name, value := range data {
+		field, ok := schema.Properties[name]
+		if !ok {
+			errs.add(name, "is not a field of this form")
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+			continue
+		}
+		clean, message := checkFormValue(field, value)
+		if message != "" {
+			errs.add(name, message)
+			continue
+		}
+		cleaned[name] = clean
+	}
+	for _, name := range schema.Required {
+		if _, ok := cleaned[name]; !ok && !hasFieldError(errs.fields, errs.prefix+name) {
+			errs.add(name, "is required")
+		}
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return cleaned, nil
+}
+
+func hasFieldError(fields []utils.FieldError, name string) bool {
+	for _, field := range fields {
+		if field.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormValue returns the cleaned value, or why it does not fit the field
+func checkFormValue(field models.FormField, value interface{}) (interface{}, string) {
+	switch field.Type {
+	case models.FormTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		return checkFormString(field, strings.TrimSpace(s))
+
+	case models.FormTypeNumber, models.FormTypeInteger:
+		n, ok := formNumber(value)
+		if !ok {
+			return nil, "must be a number"
+		}
+		if field.Type == models.FormTypeInteger && n != math.Trunc(n) {
+			return nil, "must be a whole number"
+		}
+		if field.Minimum != nil && n < *field.Minimum {
+			return nil, fmt.Sprintf("must be at least %g", *field.Minimum)
+		}
+		if field.Maximum != nil && n > *field.Maximum {
+			return nil, fmt.Sprintf("must be at most %g", *field.Maximum)
+		}
+		if field.Type == models.FormTypeInteger {
+			return int64(n), ""
+		}
+		return n, ""
+
+	case models.FormTypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, "must be true or false"
+		}
+		return b, ""
+	}
+	return nil, "has an unsupported type"
+}
+
+func checkFormString(field models.FormField, s string) (interface{}, string) {
+	length := utf8.RuneCountInString(s)
+	if field.MinLength != nil && length < *field.MinLength {
+		return nil, fmt.Sprintf("must be at least %d characters", *field.MinLength)
+	}
+	if field.MaxLength != nil && length > *field.MaxLength {
+		return nil, fmt.Sprintf("must be at most %d characters", *field.MaxLength)
+	}
+	if len(field.Enum) > 0 && !containsString(field.Enum, s) {
+		return nil, "must be one of: " + strings.Join(field.Enum, ", ")
+	}
+
+	switch field.Format {
+	case models.FormFormatDate:
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return nil, "must be a date in YYYY-MM-DD format"
+		}
+	case models.FormFormatEmail:
+		if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+			return nil, "must be a valid email address"
+		}
+	case models.FormFormatPhone:
+		if !formPhonePattern.MatchString(s) {
+			return nil, "must be a 10-digit mobile number"
+		}
+	}
+
+	if field.Pattern != "" {
+		// Patterns are compiled when the form is saved, so this cannot fail
+		if pattern, err := regexp.Compile(field.Pattern); err == nil && !pattern.MatchString(s) {
+			return nil, "is not in the expected format"
+		}
+	}
+	return s, ""
+}
+
+// formNumber accepts the numeric values a decoded JSON body can hold
+func formNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFormSchema checks a form an admin wants to save
+func validateFormSchema(schema models.FormSchema) error {
+	errs := &formErrors{prefix: "form."}
+
+	if len(schema.Properties) == 0 {
+		errs.add("properties", "must declare at least one field")
+	}
+	if len(schema.Properties) > maxFormFields {
+		errs.add("properties", fmt.Sprintf("must declare at most %d fields", maxFormFields))
+	}
+	for name, field := range schema.Properties {
+		path := "properties." + name
+		if !formFieldNamePattern.MatchString(name) {
+			errs.add(path, "name must be lowercase letters, digits and underscores")
+		}
+		if !formFieldTypes[field.Type] {
+			errs.add(path+".type", "must be one of: boolean, integer, number, string")
+			continue
+		}
+		isString := field.Type == models.FormTypeString
+		isNumber := field.Type == models.FormTypeNumber || field.Type == models.FormTypeInteger
+
+		if field.Format != "" && (!isString || !formFormats[field.Format]) {
+			errs.add(path+".format", "must be date, email or phone, on a string field")
+		}
+		if field.Pattern != "" {
+			if !isString {
+				errs.add(path+".pattern", "applies to string fields only")
+			} else if _, err := regexp.Compile(field.Pattern); err != nil {
+				errs.add(path+".pattern", "is not a valid regular expression")
+			}
+		}
+		if len(field.Enum) > 0 && !isString {
+			errs.add(path+".enum", "applies to string fields only")
+		}
+		if (field.MinLength != nil || field.MaxLength != nil) && !isString {
+			errs.add(path, "minLength and maxLength apply to string fields only")
+		}
+		if (field.MinLength != nil && *field.MinLength < 0) || (field.MaxLength != nil && *field.MaxLength < 0) {
+			errs.add(path, "minLength and maxLength cannot be negative")
+		}
+		if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+			errs.add(path, "minLength cannot exceed maxLength")
+		}
+		if (field.Minimum != nil || field.Maximum != nil) && !isNumber {
+			errs.add(path, "minimum and maximum apply to number fields only")
+		}
+		if field.Minimum != nil && field.Maximum != nil && *field.Minimum > *field.Maximum {
+			errs.add(path, "minimum cannot exceed maximum")
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, name := range schema.Required {
+		if seen[name] {
+			errs.add("required", fmt.Sprintf("lists %q twice", name))
+		}
+		seen[name] = true
+		if _, ok := schema.Properties[name]; !ok {
+			errs.add("required", fmt.Sprintf("%q is not a field of the form", name))
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+func birthForm(t *testing.T) models.FormSchema {
+	t.Helper()
+	kind := defaultApplicationType("birth")
+	if kind == nil {
+		t.Fatal("expected a default birth certificate type")
+	}
+	return kind.Form
+}
+
+func TestValidateFormDataCleansValidAnswers(t *testing.T) {
+	data, err := validateFormData(birthForm(t), map[string]interface{}{
+		"child_name":     "  Aarav Patil ",
+		"date_of_birth":  "2024-03-15",
+		"gender":         "male",
+		"place_of_birth": "PHC Shirur",
+		"father_name":    "Suresh Patil",
+		"mother_name":    "Kavita Patil",
+		"hospital_name":  "",
+	})
+	if err != nil {
+		t.Fatalf("expected the form to pass, got %v", err)
+	}
+	if data["child_name"] != "Aarav Patil" {
+		t.Errorf("expected the name to be trimmed, got %q", data["child_name"])
+	}
+	if _, ok := data["hospital_name"]; ok {
+		t.Error("expected a blank optional field to be dropped")
+	}
+}
+
+func TestValidateFormDataListsEveryRejectedField(t *testing.T) {
+	_, err := validateFormData(birthForm(t), map[string]interface{}{
+		"child_name":     "Aarav Patil",
+		"date_of_birth":  "15/03/2024",
+		"gender":         "unknown",
+		"place_of_birth": 42.0,
+		"father_name":    "Suresh Patil",
+		"blood_group":    "B+",
+	})
+
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	want := []string{
+		"form_data.blood_group",
+		"form_data.date_of_birth",
+		"form_data.gender",
+		"form_data.mother_name",
+		"form_data.place_of_birth",
+	}
+	if len(validationErr.Fields) != len(want) {
+		t.Fatalf("expected %d rejected fields, got %+v", len(want), validationErr.Fields)
+	}
+	for i, field := range validationErr.Fields {
+		if field.Field != want[i] {
+			t.Errorf("expected field %d to be %s, got %s (%s)", i, want[i], field.Field, field.Message)
+		}
+	}
+}
+
+func TestValidateFormDataChecksNumbers(t *testing.T) {
+	minimum, maximum := 1.0, 20.0
+	form := models.FormSchema{
+		Required: []string{"members"},
+		Properties: map[string]models.FormField{
+			"members": {Type: models.FormTypeInteger, Minimum: &minimum, Maximum: &maximum},
+		},
+	}
+
+	for _, value := range []interface{}{2.5, 0.0, 21.0, "4"} {
+		if _, err := validateFormData(form, map[string]interface{}{"members": value}); err == nil {
+			t.Errorf("expected %v to be rejected", value)
+		}
+	}
+	data, err := validateFormData(form, map[string]interface{}{"members": 4.0})
+	if err != nil {
+		t.Fatalf("expected 4 to pass, got %v", err)
+	}
+	if data["members"] != int64(4) {
+		t.Errorf("expected an integer to be stored as one, got %#v", data["members"])
+	}
+}
+
+func TestValidateFormSchemaRejectsInconsistentForms(t *testing.T) {
+	for _, kind := range models.DefaultApplicationTypes {
+		if err := validateFormSchema(kind.Form); err != nil {
+			t.Errorf("expected the default %s form to be valid, got %v", kind.Code, err)
+		}
+	}
+
+	five := 5
+	err := validateFormSchema(models.FormSchema{
+		Required: []string{"name", "age"},
+		Properties: map[string]models.FormField{
+			"name":   {Type: models.FormTypeString, Pattern: "(["},
+			"count":  {Type: models.FormTypeNumber, MaxLength: &five},
+			"Joined": {Type: "date"},
+		},
+	})
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if len(validationErr.Fields) != 5 {
+		t.Errorf("expected 5 problems, got %+v", validationErr.Fields)
+	}
+}
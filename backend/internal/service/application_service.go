@@ -1,6 +1,315 @@
 package service
 
-// TODO: restore original contents of application_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+var (
+	ErrApplicationNotFound     = errors.New("application not found")
+	ErrApplicationTypeNotFound = errors.New("application type not found")
+
+	applicationTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+)
+
+var applicationPriorities = map[string]bool{
+	models.ApplicationPriorityNormal: true,
+	models.ApplicationPriorityUrgent: true,
+}
+
+type ApplicationService struct {
+	applicationRepo     *repository.ApplicationRepository
+	userRepo            *repository.UserRepository
+	roleService         *RoleService
+	notificationService *NotificationService
+}
+
+func NewApplicationService(applicationRepo *repository.ApplicationRepository, userRepo *repository.UserRepository, roleService *RoleService, notificationService *NotificationService) *ApplicationService {
+	return &ApplicationService{
+		applicationRepo:     applicationRepo,
+		userRepo:            userRepo,
+		roleService:         roleService,
+		notificationService: notificationService,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *ApplicationService) ForTenant(tenant *models.Panchayat) *ApplicationService {
+	bound := *s
+	bound.applicationRepo = s.applicationRepo.ForTenant(tenant.ID)
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	bound.roleService = s.roleService.ForTenant(tenant)
+	bound.notificationService = s.notificationService.ForTenant(tenant)
+	return &bound
+}
+
+// ApplicationTypeInput is the editable part of an application type. A nil
+// Workflow keeps the type's current workflow.
+type ApplicationTypeInput struct {
+	Name        string
+	Description string
+	Form        models.FormSchema
+	Workflow    []models.WorkflowStage
+	IsActive    bool
+}
+
+// CreateApplication files an application of an active type after checking
+// its form data against the type's current form. Rejected form fields are
+// returned as a *utils.ValidationError. The application follows the type's
+// workflow as it is now, from the first stage.
+func (s *ApplicationService) CreateApplication(userID uint, applicationType string, formData map[string]interface{}, priority string) (*models.Application, error) {
+	applicant, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("applicant not found")
+	}
+
+	kind, err := s.GetApplicationType(applicationType)
+	if err != nil {
+		return nil, err
+	}
+	if !kind.IsActive {
+		return nil, fmt.Errorf("%s applications are not being accepted", strings.ToLower(kind.Name))
+	}
+	if priority == "" {
+		priority = models.ApplicationPriorityNormal
+	}
+	if !applicationPriorities[priority] {
+		return nil, fmt.Errorf("invalid priority %q", priority)
+	}
+	cleaned, err := validateFormData(kind.Form, formData)
+	if err != nil {
+		return nil, err
+	}
+
+	application := &models.Application{
+		UserID:      userID,
+		Type:        kind.Code,
+		Title:       kind.Name,
+		FormData:    cleaned,
+		FormVersion: kind.Version,
+		Priority:    priority,
+		Status:      models.ApplicationStatusSubmitted,
+		Village:     applicant.Village,
+		Taluka:      applicant.Taluka,
+		Workflow:    kind.Workflow,
+		StageRole:   kind.Workflow[0].Role,
+	}
+	entry := &models.ApplicationAction{
+		Action:    models.ApplicationActionSubmit,
+		Stage:     kind.Workflow[0].Key,
+		ToStatus:  models.ApplicationStatusSubmitted,
+		ActorID:   userID,
+		ActorRole: applicant.Role,
+	}
+	if err := s.applicationRepo.Create(application, entry); err != nil {
+		return nil, err
+	}
+	return s.GetApplication(application.ID)
+}
+
+// GetApplications lists applications matching the filters within the scope
+func (s *ApplicationService) GetApplications(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Application, int64, error) {
+	return s.applicationRepo.List(list, filters, scope)
+}
+
+func (s *ApplicationService) GetApplication(applicationID uint) (*models.Application, error) {
+	application, err := s.applicationRepo.GetByID(applicationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	return application, nil
+}
+
+// GetAllUserApplications returns every application of one applicant
+func (s *ApplicationService) GetAllUserApplications(userID uint) ([]models.Application, error) {
+	return s.applicationRepo.ListByUser(userID)
+}
+
+// GetAdminStats counts the panchayat's applications by status, type and
+// priority
+func (s *ApplicationService) GetAdminStats() (map[string]interface{}, error) {
+	return s.stats(map[string]interface{}{})
+}
+
+// GetCitizenStats counts one applicant's applications by status and type
+func (s *ApplicationService) GetCitizenStats(userID uint) (map[string]interface{}, error) {
+	return s.stats(map[string]interface{}{"user_id": userID})
+}
+
+func (s *ApplicationService) stats(filters map[string]interface{}) (map[string]interface{}, error) {
+	byStatus, err := s.applicationRepo.CountBy("status", filters)
+	if err != nil {
+		return nil, err
+	}
+	byType, err := s.applicationRepo.CountBy("type", filters)
+	if err != nil {
+		return nil, err
+	}
+	byPriority, err := s.applicationRepo.CountBy("priority", filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, count := range byStatus {
+		total += count
+	}
+	return map[string]interface{}{
+		"total_applications":       total,
+		"pending_applications":     byStatus[models.ApplicationStatusSubmitted] + byStatus[models.ApplicationStatusUnderReview],
+		"returned_applications":    byStatus[models.ApplicationStatusReturned],
+		"approved_applications":    byStatus[models.ApplicationStatusApproved],
+		"rejected_applications":    byStatus[models.ApplicationStatusRejected],
+		"applications_by_status":   byStatus,
+		"applications_by_type":     byType,
+		"applications_by_priority": byPriority,
+	}, nil
+}
+
+// GetApplicationTypes lists the types the panchayat offers: the defaults,
+// each replaced by the panchayat's own version once it saves one, and the
+// types it added. Inactive types are left out unless includeInactive is set.
+func (s *ApplicationService) GetApplicationTypes(includeInactive bool) ([]models.ApplicationType, error) {
+	stored, err := s.applicationRepo.ListTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]models.ApplicationType, len(models.DefaultApplicationTypes)+len(stored))
+	for _, kind := range models.DefaultApplicationTypes {
+		byCode[kind.Code] = kind
+	}
+	for _, kind := range stored {
+		byCode[kind.Code] = kind
+	}
+
+	types := make([]models.ApplicationType, 0, len(byCode))
+	for _, kind := range byCode {
+		if kind.IsActive || includeInactive {
+			types = append(types, withDefaultWorkflow(kind))
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+	return types, nil
+}
+
+// GetApplicationType returns the panchayat's version of a type, or the
+// default of that code if it has not saved one
+func (s *ApplicationService) GetApplicationType(code string) (*models.ApplicationType, error) {
+	stored, err := s.applicationRepo.GetType(code)
+	if err == nil {
+		kind := withDefaultWorkflow(*stored)
+		return &kind, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if kind := defaultApplicationType(code); kind != nil {
+		return kind, nil
+	}
+	return nil, ErrApplicationTypeNotFound
+}
+
+// SaveApplicationType creates or replaces the panchayat's version of a type.
+// Each save bumps the form version; applications keep the form version and
+// workflow they were filed under.
+func (s *ApplicationService) SaveApplicationType(code string, input ApplicationTypeInput) (*models.ApplicationType, error) {
+	if !applicationTypeCodePattern.MatchString(code) {
+		return nil, errors.New("code must be 2-50 lowercase letters, digits and underscores, starting with a letter")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := validateFormSchema(input.Form); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.applicationRepo.GetType(code)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	workflow := input.Workflow
+	if workflow == nil {
+		workflow = models.DefaultApplicationWorkflow
+		if stored != nil && len(stored.Workflow) > 0 {
+			workflow = stored.Workflow
+		}
+	} else if err := s.validateWorkflow(workflow); err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		kind := &models.ApplicationType{
+			Code:        code,
+			Name:        name,
+			Description: strings.TrimSpace(input.Description),
+			Form:        input.Form,
+			Workflow:    workflow,
+			Version:     1,
+			IsActive:    input.IsActive,
+		}
+		if base := defaultApplicationType(code); base != nil {
+			kind.Version = base.Version + 1
+		}
+		if err := s.applicationRepo.CreateType(kind); err != nil {
+			return nil, err
+		}
+		return kind, nil
+	}
+
+	form, err := json.Marshal(input.Form)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := json.Marshal(workflow)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		"name":        name,
+		"description": strings.TrimSpace(input.Description),
+		"form":        string(form),
+		"workflow":    string(stages),
+		"version":     stored.Version + 1,
+		"is_active":   input.IsActive,
+	}
+	if err := s.applicationRepo.UpdateType(stored.ID, stored.Version, fields); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("application type changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+	return s.GetApplicationType(code)
+}
+
+// withDefaultWorkflow gives types saved before workflows existed the
+// default one
+func withDefaultWorkflow(kind models.ApplicationType) models.ApplicationType {
+	if len(kind.Workflow) == 0 {
+		kind.Workflow = models.DefaultApplicationWorkflow
+	}
+	return kind
+}
+
+func defaultApplicationType(code string) *models.ApplicationType {
+	for _, kind := range models.DefaultApplicationTypes {
+		if kind.Code == code {
+			kind := kind
+			return &kind
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+var (
+	ErrNotApplicant     = errors.New("only the applicant can correct this application")
+	ErrNotStageReviewer = errors.New("this stage is reviewed by another role")
+)
+
+// maxWorkflowStages bounds the stages of one application workflow
+const maxWorkflowStages = 10
+
+// workflowStep is where a review action takes an application
+type workflowStep struct {
+	status     string
+	stageIndex int
+}
+
+// nextWorkflowStep works out the result of a reviewer's action at the
+// application's current stage. Approving moves it to the next stage, or
+// approves it at the last one; sending it back moves it to the previous
+// stage; returning it waits for the applicant at the same stage.
+func nextWorkflowStep(application *models.Application, action string) (workflowStep, error) {
+	stage := application.CurrentStage()
+	if stage == nil {
+		return workflowStep{}, errors.New("application has no workflow stage to review")
+	}
+	if application.Status != models.ApplicationStatusSubmitted && application.Status != models.ApplicationStatusUnderReview {
+		return workflowStep{}, fmt.Errorf("a %s application cannot be reviewed", application.Status)
+	}
+	if !stage.Allows(action) {
+		return workflowStep{}, fmt.Errorf("%s does not allow %s", stage.Name, action)
+	}
+
+	index := application.StageIndex
+	switch action {
+	case models.ApplicationActionApprove:
+		if index == len(application.Workflow)-1 {
+			return workflowStep{models.ApplicationStatusApproved, index}, nil
+		}
+		return workflowStep{models.ApplicationStatusUnderReview, index + 1}, nil
+	case models.ApplicationActionReject:
+		return workflowStep{models.ApplicationStatusRejected, index}, nil
+	case models.ApplicationActionReturn:
+		return workflowStep{models.ApplicationStatusReturned, index}, nil
+	case models.ApplicationActionSendBack:
+		if index == 0 {
+			return workflowStep{}, errors.New("the first stage has no stage to send back to")
+		}
+		return workflowStep{models.ApplicationStatusUnderReview, index - 1}, nil
+	}
+	return workflowStep{}, fmt.Errorf("unknown action %q", action)
+}
+
+// stageRole is the role that acts next on an application in status at the
+// stage, or empty if no reviewer does
+func stageRole(workflow []models.WorkflowStage, status string, index int) string {
+	if status != models.ApplicationStatusSubmitted && status != models.ApplicationStatusUnderReview {
+		return ""
+	}
+	return workflow[index].Role
+}
+
+// ReviewApplication applies a reviewer's action at the application's
+// current stage. Only the stage's role, or an admin, may act, and every
+// action needs remarks. The applicant is told when the application is
+// returned or decided.
+func (s *ApplicationService) ReviewApplication(applicationID, reviewerID uint, reviewerRole, action, remarks string) (*models.Application, error) {
+	application, err := s.GetApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	remarks = strings.TrimSpace(remarks)
+	if remarks == "" {
+		return nil, errors.New("remarks are required at every step")
+	}
+
+	fields := map[string]interface{}{}
+	if len(application.Workflow) == 0 {
+		// Filed before its type had a workflow; follow the current one
+		kind, err := s.GetApplicationType(application.Type)
+		if err != nil {
+			return nil, err
+		}
+		workflow, err := json.Marshal(kind.Workflow)
+		if err != nil {
+			return nil, err
+		}
+		application.Workflow = kind.Workflow
+		fields["workflow"] = string(workflow)
+	}
+	stage := application.CurrentStage()
+	if stage != nil && reviewerRole != stage.Role && reviewerRole != models.RoleAdmin {
+		return nil, fmt.Errorf("%w: %s is for the %s role", ErrNotStageReviewer, stage.Name, stage.Role)
+	}
+	step, err := nextWorkflowStep(application, action)
+	if err != nil {
+		return nil, err
+	}
+
+	fields["status"] = step.status
+	fields["stage_index"] = step.stageIndex
+	fields["stage_role"] = stageRole(application.Workflow, step.status, step.stageIndex)
+	fields["remarks"] = remarks
+	if step.status == models.ApplicationStatusApproved || step.status == models.ApplicationStatusRejected {
+		fields["reviewed_by"] = reviewerID
+		fields["reviewed_at"] = time.Now()
+	}
+	entry := &models.ApplicationAction{
+		ApplicationID: application.ID,
+		Action:        action,
+		Stage:         stage.Key,
+		FromStatus:    application.Status,
+		ToStatus:      step.status,
+		Remarks:       remarks,
+		ActorID:       reviewerID,
+		ActorRole:     reviewerRole,
+	}
+	if err := s.applicationRepo.Advance(application.ID, application.Status, application.StageIndex, fields, entry); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("application changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+
+	updated, err := s.GetApplication(application.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch step.status {
+	case models.ApplicationStatusReturned:
+		s.notifyApplicant(updated, "returned for corrections", remarks)
+	case models.ApplicationStatusApproved, models.ApplicationStatusRejected:
+		s.notifyApplicant(updated, step.status, remarks)
+	}
+	return updated, nil
+}
+
+// ResubmitApplication sends a returned application back to the stage that
+// returned it. New form data, if given, replaces the old answers and is
+// checked against the type's current form.
+func (s *ApplicationService) ResubmitApplication(applicationID, userID uint, formData map[string]interface{}, remarks string) (*models.Application, error) {
+	application, err := s.GetApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != userID {
+		return nil, ErrNotApplicant
+	}
+	if application.Status != models.ApplicationStatusReturned {
+		return nil, errors.New("only applications returned for corrections can be resubmitted")
+	}
+
+	status := models.ApplicationStatusUnderReview
+	if application.StageIndex == 0 {
+		status = models.ApplicationStatusSubmitted
+	}
+	fields := map[string]interface{}{
+		"status":     status,
+		"stage_role": stageRole(application.Workflow, status, application.StageIndex),
+	}
+	if formData != nil {
+		kind, err := s.GetApplicationType(application.Type)
+		if err != nil {
+			return nil, err
+		}
+		cleaned, err := validateFormData(kind.Form, formData)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(cleaned)
+		if err != nil {
+			return nil, err
+		}
+		fields["form_data"] = string(data)
+		fields["form_version"] = kind.Version
+	}
+
+	var stageKey string
+	if stage := application.CurrentStage(); stage != nil {
+		stageKey = stage.Key
+	}
+	entry := &models.ApplicationAction{
+		ApplicationID: application.ID,
+		Action:        models.ApplicationActionResubmit,
+		Stage:         stageKey,
+		FromStatus:    application.Status,
+		ToStatus:      status,
+		Remarks:       strings.TrimSpace(remarks),
+		ActorID:       userID,
+		ActorRole:     models.RoleCitizen,
+	}
+	if err := s.applicationRepo.Advance(application.ID, application.Status, application.StageIndex, fields, entry); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("application changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+	return s.GetApplication(application.ID)
+}
+
+func (s *ApplicationService) notifyApplicant(application *models.Application, outcome, remarks string) {
+	title := fmt.Sprintf("Application #%d %s", application.ID, outcome)
+	message := fmt.Sprintf("Your %s application was %s: %s", strings.ToLower(application.Title), outcome, remarks)
+	if err := s.notificationService.Notify(application.UserID, title, message, models.AuditEntityApplication, application.ID); err != nil {
+		log.Printf("application: notifying user %d: %v", application.UserID, err)
+	}
+}
+
+// validateWorkflow checks a workflow an admin wants to save. Each stage's
+// role must exist and be allowed to review applications.
+func (s *ApplicationService) validateWorkflow(workflow []models.WorkflowStage) error {
+	errs := &formErrors{prefix: "workflow"}
+
+	if len(workflow) == 0 {
+		errs.add("", "must have at least one stage")
+	}
+	if len(workflow) > maxWorkflowStages {
+		errs.add("", fmt.Sprintf("must have at most %d stages", maxWorkflowStages))
+	}
+	keys := map[string]bool{}
+	for i, stage := range workflow {
+		path := fmt.Sprintf(".%d", i)
+		if !formFieldNamePattern.MatchString(stage.Key) {
+			errs.add(path+".key", "must be lowercase letters, digits and underscores")
+		} else if keys[stage.Key] {
+			errs.add(path+".key", "is used by an earlier stage")
+		}
+		keys[stage.Key] = true
+		if strings.TrimSpace(stage.Name) == "" {
+			errs.add(path+".name", "is required")
+		}
+
+		if stage.Role != models.RoleAdmin {
+			exists, err := s.roleService.RoleExists(stage.Role)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				errs.add(path+".role", "is not a role")
+			} else if !s.roleService.HasPermission(stage.Role, models.PermApplicationsStatusUpdate) {
+				errs.add(path+".role", "cannot review applications")
+			}
+		}
+
+		if !stage.Allows(models.ApplicationActionApprove) {
+			errs.add(path+".actions", "must include approve")
+		}
+		for _, action := range stage.Actions {
+			if !containsString(models.ApplicationReviewActions, action) {
+				errs.add(path+".actions", "must be among: "+strings.Join(models.ApplicationReviewActions, ", "))
+				break
+			}
+		}
+		if i == 0 && stage.Allows(models.ApplicationActionSendBack) {
+			errs.add(path+".actions", "the first stage cannot send back")
+		}
+	}
+	return errs.err()
+}
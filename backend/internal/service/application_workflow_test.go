@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+
+	"gram-panchayat/internal/models"
+)
+
+func TestNextWorkflowStepWalksTheStages(t *testing.T) {
+	application := &models.Application{
+		Status:   models.ApplicationStatusSubmitted,
+		Workflow: models.DefaultApplicationWorkflow,
+	}
+
+	for i, want := range []workflowStep{
+		{models.ApplicationStatusUnderReview, 1},
+		{models.ApplicationStatusUnderReview, 2},
+		{models.ApplicationStatusApproved, 2},
+	} {
+		step, err := nextWorkflowStep(application, models.ApplicationActionApprove)
+		if err != nil {
+			t.Fatalf("approval %d: %v", i+1, err)
+		}
+		if step != want {
+			t.Fatalf("approval %d: expected %+v, got %+v", i+1, want, step)
+		}
+		application.Status, application.StageIndex = step.status, step.stageIndex
+	}
+
+	if _, err := nextWorkflowStep(application, models.ApplicationActionReject); err == nil {
+		t.Error("expected an approved application to be closed to review")
+	}
+}
+
+func TestNextWorkflowStepFollowsStageActions(t *testing.T) {
+	application := &models.Application{
+		Status:   models.ApplicationStatusSubmitted,
+		Workflow: models.DefaultApplicationWorkflow,
+	}
+	if _, err := nextWorkflowStep(application, models.ApplicationActionSendBack); err == nil {
+		t.Error("expected the first stage to have nowhere to send back to")
+	}
+
+	step, err := nextWorkflowStep(application, models.ApplicationActionReturn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step.status != models.ApplicationStatusReturned || step.stageIndex != 0 {
+		t.Errorf("expected the application returned at the first stage, got %+v", step)
+	}
+	if role := stageRole(application.Workflow, step.status, step.stageIndex); role != "" {
+		t.Errorf("expected no reviewer while the applicant corrects it, got %q", role)
+	}
+
+	application.Status, application.StageIndex = models.ApplicationStatusUnderReview, 2
+	step, err = nextWorkflowStep(application, models.ApplicationActionSendBack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step.stageIndex != 1 || stageRole(application.Workflow, step.status, step.stageIndex) != models.RoleGramSevak {
+		t.Errorf("expected the application sent back to the Gram Sevak, got %+v", step)
+	}
+
+	application.Workflow = []models.WorkflowStage{{Key: "check", Name: "Check", Role: models.RoleStaff, Actions: []string{models.ApplicationActionApprove}}}
+	application.StageIndex = 0
+	if _, err := nextWorkflowStep(application, models.ApplicationActionReject); err == nil {
+		t.Error("expected an action the stage does not allow to be refused")
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+type AttendanceEntry struct {
+	UserID      uint
+	Status      string
+	CheckedInAt *time.Time
+	Remarks     string
+}
+
+type AttendanceService struct {
+	meetingRepo    *repository.MeetingRepository
+	attendanceRepo *repository.AttendanceRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewAttendanceService(meetingRepo *repository.MeetingRepository, attendanceRepo *repository.AttendanceRepository, userRepo *repository.UserRepository) *AttendanceService {
+	return &AttendanceService{
+		meetingRepo:    meetingRepo,
+		attendanceRepo: attendanceRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *AttendanceService) ForTenant(tenant *models.Panchayat) *AttendanceService {
+	bound := *s
+	bound.meetingRepo = s.meetingRepo.ForTenant(tenant.ID)
+	bound.attendanceRepo = s.attendanceRepo.ForTenant(tenant.ID)
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+func (s *AttendanceService) GetRegister(meetingID uint) ([]models.MeetingAttendance, error) {
+	if _, err := s.meetingRepo.GetByID(meetingID); err != nil {
+		return nil, errors.New("meeting not found")
+	}
+	return s.attendanceRepo.ListByMeeting(meetingID)
+}
+
+// RecordAttendance records or corrects register entries for a meeting that
+// is in progress or completed. Present members without a check-in time are
+// checked in now. Every member must be a user of the panchayat, since the
+// register decides quorum and who may vote.
+func (s *AttendanceService) RecordAttendance(meetingID, recordedBy uint, entries []AttendanceEntry) ([]models.MeetingAttendance, error) {
+	meeting, err := s.meetingRepo.GetByID(meetingID)
+	if err != nil {
+		return nil, errors.New("meeting not found")
+	}
+	if err := canRecordAttendance(meeting); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := map[uint]bool{}
+	records := make([]models.MeetingAttendance, 0, len(entries))
+	for _, entry := range entries {
+		if seen[entry.UserID] {
+			return nil, fmt.Errorf("member %d appears more than once", entry.UserID)
+		}
+		seen[entry.UserID] = true
+
+		record := models.MeetingAttendance{
+			MeetingID:  meetingID,
+			UserID:     entry.UserID,
+			Status:     entry.Status,
+			Remarks:    entry.Remarks,
+			RecordedBy: recordedBy,
+		}
+
+		switch entry.Status {
+		case models.AttendancePresent:
+			record.CheckedInAt = entry.CheckedInAt
+			if record.CheckedInAt == nil {
+				record.CheckedInAt = &now
+			}
+		case models.AttendanceAbsent, models.AttendanceExcused:
+		default:
+			return nil, fmt.Errorf("invalid attendance status %q for member %d", entry.Status, entry.UserID)
+		}
+
+		records = append(records, record)
+	}
+
+	ids := make([]uint, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.UserID)
+	}
+	existing, err := s.userRepo.ExistingIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			return nil, fmt.Errorf("member %d is not a user of this panchayat", id)
+		}
+	}
+
+	if err := s.attendanceRepo.Upsert(records); err != nil {
+		return nil, err
+	}
+
+	return s.attendanceRepo.ListByMeeting(meetingID)
+}
+
+// CheckIn marks the calling member present at a meeting that is in progress.
+// It never overrides an entry already in the register: checking in twice
+// returns the first check-in, and a member staff recorded as absent or
+// excused has to ask them to correct it.
+func (s *AttendanceService) CheckIn(meetingID, userID uint) (*models.MeetingAttendance, error) {
+	meeting, err := s.meetingRepo.GetByID(meetingID)
+	if err != nil {
+		return nil, errors.New("meeting not found")
+	}
+	if meeting.Status != models.MeetingStatusInProgress {
+		return nil, errors.New("check-in is only open while the meeting is in progress")
+	}
+
+	now := time.Now()
+	record := models.MeetingAttendance{
+		MeetingID:   meetingID,
+		UserID:      userID,
+		Status:      models.AttendancePresent,
+		CheckedInAt: &now,
+		RecordedBy:  userID,
+	}
+
+	inserted, err := s.attendanceRepo.Insert(&record)
+	if err != nil {
+		return nil, err
+	}
+	if inserted {
+		return &record, nil
+	}
+
+	existing, err := s.attendanceRepo.GetForMember(meetingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status != models.AttendancePresent {
+		return nil, fmt.Errorf("you are already recorded as %s at this meeting", existing.Status)
+	}
+	return existing, nil
+}
+
+// GetAttendanceReport returns attendance percentage per member for meetings
+// scheduled between from and to (both inclusive dates).
+func (s *AttendanceService) GetAttendanceReport(from, to time.Time, filters map[string]interface{}) ([]models.MemberAttendance, error) {
+	if to.Before(from) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	summary, err := s.attendanceRepo.MemberSummary(from, to.AddDate(0, 0, 1), filters)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range summary {
+		if summary[i].Meetings > 0 {
+			percentage := float64(summary[i].Present) / float64(summary[i].Meetings) * 100
+			summary[i].Percentage = math.Round(percentage*100) / 100
+		}
+	}
+
+	return summary, nil
+}
+
+func canRecordAttendance(meeting *models.Meeting) error {
+	switch meeting.Status {
+	case models.MeetingStatusInProgress, models.MeetingStatusCompleted:
+		return nil
+	default:
+		return fmt.Errorf("cannot record attendance for a %s meeting", meeting.Status)
+	}
+}
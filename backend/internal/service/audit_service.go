@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+// errAuditChainBroken stops a chain walk at the first bad record
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// auditIgnoredFields change on every write and would only add noise
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditEntry describes one mutation. Before is nil for creations and After is
+// nil for deletions; both are compared field by field as JSON.
+type AuditEntry struct {
+	ActorID    uint
+	ActorRole  string
+	Action     string
+	EntityType string
+	EntityID   uint
+	Before     interface{}
+	After      interface{}
+	IPAddress  string
+	UserAgent  string
+}
+
+// AuditVerification is the result of re-hashing a panchayat's audit chain
+type AuditVerification struct {
+	Valid    bool  `json:"valid"`
+	Checked  int   `json:"checked"`
+	BrokenAt *uint `json:"broken_at,omitempty"` // ID of the first record that does not match
+}
+
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *AuditService) ForTenant(tenant *models.Panchayat) *AuditService {
+	bound := *s
+	bound.auditRepo = s.auditRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+// Record appends a mutation to the audit chain. Updates that change nothing
+// are not recorded.
+func (s *AuditService) Record(entry AuditEntry) error {
+	changes, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && entry.Action == models.AuditActionUpdate {
+		return nil
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepo.Append(&models.AuditLog{
+		ActorID:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    encoded,
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+	})
+}
+
+func (s *AuditService) GetAuditLogs(list utils.ListQuery, filters map[string]interface{}) ([]models.AuditLog, int64, error) {
+	return s.auditRepo.List(list, filters)
+}
+
+// GetUserActivity returns the latest changes made by or to a user
+func (s *AuditService) GetUserActivity(userID uint) ([]models.AuditLog, error) {
+	return s.auditRepo.ListByUser(userID, 100)
+}
+
+// VerifyChain recomputes every hash of the panchayat's chain and reports the
+// first record that was altered, removed or inserted out of order
+func (s *AuditService) VerifyChain() (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true}
+	prevHash := ""
+
+	err := s.auditRepo.Walk(500, func(logs []models.AuditLog) error {
+		for i := range logs {
+			entry := &logs[i]
+			if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+				id := entry.ID
+				result.Valid = false
+				result.BrokenAt = &id
+				return errAuditChainBroken
+			}
+			prevHash = entry.Hash
+			result.Checked++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// auditDiff returns the fields whose JSON values differ between before and
+// after. Fields hidden from JSON, such as password hashes, never appear.
+func auditDiff(before, after interface{}) (map[string]models.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for field, value := range afterFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if old, ok := beforeFields[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = models.AuditChange{Before: beforeFields[field], After: value}
+		}
+	}
+	for field, value := range beforeFields {
+		if _, ok := afterFields[field]; !ok && !auditIgnoredFields[field] {
+			changes[field] = models.AuditChange{Before: value}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	// Lists, such as a staff member's jurisdictions, are compared as a whole
+	if object, ok := decoded.(map[string]interface{}); ok {
+		return object, nil
+	}
+	fields["value"] = decoded
+	return fields, nil
+}
@@ -1,6 +1,542 @@
 package service
 
-// TODO: restore original contents of auth_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+// Reasons recorded when a session is revoked
+const (
+	revokeReasonLogout          = "logout"
+	revokeReasonLogoutAll       = "logout_all"
+	revokeReasonNewLogin        = "replaced_by_new_login"
+	revokeReasonTokenReuse      = "refresh_token_reuse"
+	revokeReasonPasswordChanged = "password_changed"
+	revokeReasonPasswordReset   = "password_reset"
+	revokeReasonDeactivated     = "account_deactivated"
+	revokeReasonRoleChanged     = "role_changed"
+	revokeReasonAdmin           = "revoked_by_admin"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrAccountDeactivated  = errors.New("account is deactivated")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, please log in again")
+	ErrSessionRevoked      = errors.New("session has been signed out")
+)
+
+// DeviceInfo identifies the device a session is created from. DeviceID is
+// supplied by the client; a new login from the same device replaces the
+// previous session on it.
+type DeviceInfo struct {
+	DeviceID  string
+	UserAgent string
+	IPAddress string
+}
+
+// RegisterInput mirrors handlers.RegisterRequest so the handler can convert
+// its request directly.
+type RegisterInput struct {
+	Email        string
+	Password     string
+	FirstName    string
+	LastName     string
+	PhoneNumber  string
+	AadharNumber string
+	Address      string
+	Village      string
+	Pincode      string
+	PhoneOTP     string
+}
+
+// AuthResult is returned by every flow that signs a user in
+type AuthResult struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int64        `json:"expires_in"`
+	User         *models.User `json:"user"`
+}
+
+// ProfilePatch lists the fields a citizen may change on their own profile.
+// Role, verification flags, contact details and identity numbers are
+// deliberately absent; requests naming them are rejected.
+type ProfilePatch struct {
+	FirstName    *string `json:"first_name" binding:"omitempty,min=1,max=100"`
+	LastName     *string `json:"last_name" binding:"omitempty,min=1,max=100"`
+	Gender       *string `json:"gender" binding:"omitempty,oneof=male female other"`
+	Address      *string `json:"address" binding:"omitempty,max=500"`
+	Village      *string `json:"village" binding:"omitempty,max=100"`
+	Taluka       *string `json:"taluka" binding:"omitempty,max=100"`
+	District     *string `json:"district" binding:"omitempty,max=100"`
+	State        *string `json:"state" binding:"omitempty,max=100"`
+	Pincode      *string `json:"pincode" binding:"omitempty,len=6,numeric"`
+	ProfileImage *string `json:"profile_image" binding:"omitempty,max=500"`
+}
+
+type AuthService struct {
+	userRepo    *repository.UserRepository
+	tokenRepo   *repository.RefreshTokenRepository
+	sessionRepo *repository.SessionRepository
+	otpService  *OTPService
+}
+
+func NewAuthService(userRepo *repository.UserRepository, tokenRepo *repository.RefreshTokenRepository, sessionRepo *repository.SessionRepository, otpService *OTPService) *AuthService {
+	return &AuthService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		otpService:  otpService,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *AuthService) ForTenant(tenant *models.Panchayat) *AuthService {
+	bound := *s
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	bound.sessionRepo = s.sessionRepo.ForTenant(tenant.ID)
+	bound.otpService = s.otpService.ForTenant(tenant)
+	return &bound
+}
+
+// Register creates a citizen account. Email is optional when the phone
+// number is verified with a registration code (see RequestRegistrationOTP);
+// otherwise a verification code is emailed.
+func (s *AuthService) Register(input RegisterInput) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if email == "" && input.PhoneOTP == "" {
+		return nil, errors.New("either an email address or a verified phone number is required")
+	}
+
+	if email != "" {
+		if _, err := s.userRepo.GetByEmail(email); err == nil {
+			return nil, errors.New("email is already registered")
+		}
+	}
+	phoneNumber := utils.NormalizePhone(input.PhoneNumber)
+	if _, err := s.userRepo.GetByPhone(phoneNumber); err == nil {
+		return nil, errors.New("phone number is already registered")
+	}
+
+	phoneVerified := false
+	if input.PhoneOTP != "" {
+		if _, err := s.otpService.Verify(models.OTPPurposeRegistration, phoneNumber, input.PhoneOTP); err != nil {
+			return nil, err
+		}
+		phoneVerified = true
+	}
+
+	hash, err := utils.HashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:         email,
+		Password:      hash,
+		Role:          "citizen",
+		FirstName:     input.FirstName,
+		LastName:      input.LastName,
+		PhoneNumber:   phoneNumber,
+		AadharNumber:  input.AadharNumber,
+		Address:       input.Address,
+		Village:       input.Village,
+		Pincode:       input.Pincode,
+		IsActive:      true,
+		IsVerified:    phoneVerified,
+		PhoneVerified: phoneVerified,
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// A failed delivery should not fail the registration; the citizen can
+	// ask for the code again.
+	if email != "" && !phoneVerified {
+		if err := s.otpService.Issue(models.OTPPurposeRegistration, models.OTPChannelEmail, user.Email, &user.ID); err != nil {
+			log.Printf("Failed to send verification OTP to user %d: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// RequestRegistrationOTP sends a code to a phone number that is about to be
+// registered
+func (s *AuthService) RequestRegistrationOTP(phoneNumber string) error {
+	phoneNumber = utils.NormalizePhone(phoneNumber)
+	if _, err := s.userRepo.GetByPhone(phoneNumber); err == nil {
+		return errors.New("phone number is already registered")
+	}
+	return s.otpService.Issue(models.OTPPurposeRegistration, models.OTPChannelSMS, phoneNumber, nil)
+}
+
+func (s *AuthService) Login(email, password string, device DeviceInfo) (*AuthResult, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !utils.CheckPassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+
+	return s.startSession(user, device)
+}
+
+// RequestLoginOTP sends a login code by SMS. It does not reveal whether the
+// phone number is registered.
+func (s *AuthService) RequestLoginOTP(phoneNumber string) error {
+	user, err := s.userRepo.GetByPhone(utils.NormalizePhone(phoneNumber))
+	if err != nil || !user.IsActive {
+		return nil
+	}
+	return s.otpService.Issue(models.OTPPurposeLogin, models.OTPChannelSMS, user.PhoneNumber, &user.ID)
+}
+
+// LoginWithOTP signs a citizen in with the code sent to their phone and
+// issues the same tokens as Login.
+func (s *AuthService) LoginWithOTP(phoneNumber, otp string, device DeviceInfo) (*AuthResult, error) {
+	phoneNumber = utils.NormalizePhone(phoneNumber)
+	record, err := s.otpService.Verify(models.OTPPurposeLogin, phoneNumber, otp)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByPhone(phoneNumber)
+	if err != nil || record.UserID == nil || *record.UserID != user.ID {
+		return nil, ErrOTPInvalid
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+
+	if !user.PhoneVerified {
+		if err := s.userRepo.Update(user.ID, map[string]interface{}{"phone_verified": true}); err != nil {
+			return nil, err
+		}
+		user.PhoneVerified = true
+	}
+
+	return s.startSession(user, device)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Each refresh token can be used once; presenting a used token again revokes
+// the session it belongs to.
+func (s *AuthService) Refresh(refreshToken, ipAddress string) (*AuthResult, error) {
+	token, err := s.tokenRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.GetByID(token.SessionID)
+	if err != nil {
+		session = nil
+	}
+	if err := checkRefreshToken(token, session, time.Now()); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			return nil, s.handleReuse(token)
+		}
+		return nil, err
+	}
+
+	marked, err := s.tokenRepo.MarkUsed(token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		// Another request rotated this token first
+		return nil, s.handleReuse(token)
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !user.IsActive {
+		if err := s.sessionRepo.Revoke(session.ID, revokeReasonDeactivated); err != nil {
+			return nil, err
+		}
+		return nil, ErrAccountDeactivated
+	}
+
+	if err := s.sessionRepo.Touch(session.ID, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// Authenticate validates an access token and returns its active user and
+// the session the token was issued for.
+func (s *AuthService) Authenticate(accessToken string) (*models.User, *models.Session, error) {
+	claims, err := utils.ValidateToken(accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID {
+		return nil, nil, utils.ErrInvalidToken
+	}
+	if !session.IsActive() {
+		return nil, nil, ErrSessionRevoked
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, nil, utils.ErrInvalidToken
+	}
+
+	if !user.IsActive {
+		return nil, nil, ErrAccountDeactivated
+	}
+
+	return user, session, nil
+}
+
+// Logout signs out a single session
+func (s *AuthService) Logout(sessionID uint) error {
+	return s.sessionRepo.Revoke(sessionID, revokeReasonLogout)
+}
+
+// LogoutAll signs a user out of every device
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonLogoutAll)
+}
+
+func (s *AuthService) GetUserByID(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateProfile applies the profile fields a citizen may change themselves
+func (s *AuthService) UpdateProfile(userID uint, patch ProfilePatch) (*models.User, error) {
+	updates := patchUpdates(patch)
+	if len(updates) == 0 {
+		return nil, errors.New("no updatable profile fields provided")
+	}
+
+	if err := s.userRepo.Update(userID, updates); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// SendVerificationOTP (re)sends the registration code to an unverified account
+func (s *AuthService) SendVerificationOTP(email string) error {
+	user, err := s.userRepo.GetByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil || user.IsVerified {
+		return nil
+	}
+	return s.otpService.Issue(models.OTPPurposeRegistration, models.OTPChannelEmail, user.Email, &user.ID)
+}
+
+// VerifyOTP verifies the registration code and marks the account verified
+func (s *AuthService) VerifyOTP(email, otp string) (bool, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	record, err := s.otpService.Verify(models.OTPPurposeRegistration, email, otp)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || record.UserID == nil || *record.UserID != user.ID {
+		return false, ErrOTPInvalid
+	}
+
+	if err := s.userRepo.Update(user.ID, map[string]interface{}{"is_verified": true}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SendPasswordResetOTP issues a password reset code. It does not reveal
+// whether the email is registered.
+func (s *AuthService) SendPasswordResetOTP(email string) error {
+	user, err := s.userRepo.GetByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil
+	}
+	return s.otpService.Issue(models.OTPPurposePasswordReset, models.OTPChannelEmail, user.Email, &user.ID)
+}
+
+// ResetPassword sets a new password with a code from SendPasswordResetOTP.
+// The code must have been issued to the account it resets.
+func (s *AuthService) ResetPassword(email, otp, newPassword string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	record, err := s.otpService.Verify(models.OTPPurposePasswordReset, email, otp)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || record.UserID == nil || *record.UserID != user.ID {
+		return ErrOTPInvalid
+	}
+
+	hash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(user.ID, map[string]interface{}{"password": hash}); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(user.ID, revokeReasonPasswordReset)
+}
+
+// RequestPhoneChange sends a code by SMS to the new phone number
+func (s *AuthService) RequestPhoneChange(userID uint, phoneNumber string) error {
+	phoneNumber = utils.NormalizePhone(phoneNumber)
+	if existing, err := s.userRepo.GetByPhone(phoneNumber); err == nil && existing.ID != userID {
+		return errors.New("phone number is already registered")
+	}
+	return s.otpService.Issue(models.OTPPurposePhoneChange, models.OTPChannelSMS, phoneNumber, &userID)
+}
+
+// ConfirmPhoneChange verifies the code sent to the new number and saves it
+func (s *AuthService) ConfirmPhoneChange(userID uint, phoneNumber, otp string) (*models.User, error) {
+	phoneNumber = utils.NormalizePhone(phoneNumber)
+	record, err := s.otpService.Verify(models.OTPPurposePhoneChange, phoneNumber, otp)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID == nil || *record.UserID != userID {
+		return nil, ErrOTPInvalid
+	}
+
+	if err := s.userRepo.Update(userID, map[string]interface{}{"phone_number": phoneNumber, "phone_verified": true}); err != nil {
+		return nil, err
+	}
+	return s.GetUserByID(userID)
+}
+
+func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPassword(user.Password, currentPassword) {
+		return errors.New("current password is incorrect")
+	}
+
+	hash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(userID, map[string]interface{}{"password": hash}); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonPasswordChanged)
+}
+
+// startSession creates a session for the device, replacing any session the
+// user already had on it, and issues its first token pair.
+func (s *AuthService) startSession(user *models.User, device DeviceInfo) (*AuthResult, error) {
+	if device.DeviceID != "" {
+		if err := s.sessionRepo.RevokeDevice(user.ID, device.DeviceID, revokeReasonNewLogin); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		DeviceID:   device.DeviceID,
+		UserAgent:  device.UserAgent,
+		IPAddress:  device.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL()),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session)
+}
+
+func (s *AuthService) issueTokens(user *models.User, session *models.Session) (*AuthResult, error) {
+	accessToken, expiresAt, err := utils.GenerateToken(user.ID, user.Role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err := s.tokenRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	return &AuthResult{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Seconds()),
+		User:         user,
+	}, nil
+}
+
+// checkRefreshToken decides whether a stored refresh token may be exchanged.
+// ErrRefreshTokenReused means the token was already rotated and its session
+// must be revoked.
+func checkRefreshToken(token *models.RefreshToken, session *models.Session, now time.Time) error {
+	if now.After(token.ExpiresAt) {
+		return ErrInvalidRefreshToken
+	}
+	if session == nil || !session.IsActive() {
+		return ErrSessionRevoked
+	}
+	if token.UsedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (s *AuthService) handleReuse(token *models.RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %d, revoking session %d", token.UserID, token.SessionID)
+	if err := s.sessionRepo.Revoke(token.SessionID, revokeReasonTokenReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
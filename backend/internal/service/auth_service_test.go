@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+// privilegedUserFields must never be writable through a profile update
+var privilegedUserFields = []string{
+	"id", "panchayat_id", "role", "is_verified", "phone_verified", "is_active",
+	"email", "phone_number", "aadhar_number", "password", "otp", "created_at",
+}
+
+// sqlRecorder is a gorm logger that keeps the SQL of every statement
+type sqlRecorder struct {
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.sqls = append(r.sqls, sql)
+}
+
+// newDryRunDB returns a PostgreSQL handle that builds SQL without a server
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.RegisterTenantCallbacks(db); err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+func TestProfilePatchRejectsPrivilegedFields(t *testing.T) {
+	for _, field := range privilegedUserFields {
+		var patch ProfilePatch
+		body := `{"first_name":"Asha","` + field + `":"admin"}`
+		err := utils.DecodePatch([]byte(body), &patch)
+
+		var validationErr *utils.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a ValidationError, got %v", field, err)
+			continue
+		}
+		if len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != field {
+			t.Errorf("%s: got %+v", field, validationErr.Fields)
+		}
+		if patch.FirstName != nil {
+			t.Errorf("%s: rejected patch was still decoded", field)
+		}
+	}
+}
+
+func TestProfilePatchDeclaresNoPrivilegedFields(t *testing.T) {
+	updates := patchUpdates(ProfilePatch{
+		FirstName: new(string), LastName: new(string), Gender: new(string),
+		Address: new(string), Village: new(string), Taluka: new(string),
+		District: new(string), State: new(string), Pincode: new(string),
+		ProfileImage: new(string),
+	})
+	for _, field := range privilegedUserFields {
+		if _, ok := updates[field]; ok {
+			t.Errorf("ProfilePatch can write %q", field)
+		}
+	}
+}
+
+func TestUpdateProfileWritesOnlySentColumns(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	authService := NewAuthService(repository.NewUserRepository(db), nil, repository.NewSessionRepository(db),
+		NewOTPService(repository.NewOTPRepository(db), nil))
+
+	firstName, pincode := "Asha", "413001"
+	// The dry run affects no rows, so the service reports the user as missing
+	_, _ = authService.ForTenant(&models.Panchayat{ID: 3}).UpdateProfile(42, ProfilePatch{
+		FirstName: &firstName,
+		Pincode:   &pincode,
+	})
+
+	var update string
+	for _, sql := range recorder.sqls {
+		if strings.HasPrefix(sql, "UPDATE") {
+			update = sql
+		}
+	}
+	if update == "" {
+		t.Fatalf("no UPDATE was generated: %v", recorder.sqls)
+	}
+
+	set := strings.SplitN(update, " WHERE ", 2)[0]
+	for _, column := range []string{`"first_name"='Asha'`, `"pincode"='413001'`} {
+		if !strings.Contains(set, column) {
+			t.Errorf("expected %s in %s", column, set)
+		}
+	}
+	for _, column := range privilegedUserFields {
+		if strings.Contains(set, `"`+column+`"`) {
+			t.Errorf("UPDATE writes %q: %s", column, set)
+		}
+	}
+	if !strings.Contains(update, "id = 42") || !strings.Contains(update, `"users"."panchayat_id" = 3`) {
+		t.Errorf("UPDATE is not limited to the user and tenant: %s", update)
+	}
+}
+
+func TestCheckRefreshToken(t *testing.T) {
+	now := time.Now()
+	used := now.Add(-time.Minute)
+	revoked := now.Add(-time.Hour)
+	active := &models.Session{ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		token   models.RefreshToken
+		session *models.Session
+		want    error
+	}{
+		{"fresh", models.RefreshToken{ExpiresAt: now.Add(time.Hour)}, active, nil},
+		{"expired", models.RefreshToken{ExpiresAt: now.Add(-time.Second)}, active, ErrInvalidRefreshToken},
+		{"reused", models.RefreshToken{ExpiresAt: now.Add(time.Hour), UsedAt: &used}, active, ErrRefreshTokenReused},
+		{"session revoked", models.RefreshToken{ExpiresAt: now.Add(time.Hour)}, &models.Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}, ErrSessionRevoked},
+		{"session expired", models.RefreshToken{ExpiresAt: now.Add(time.Hour)}, &models.Session{ExpiresAt: now.Add(-time.Second)}, ErrSessionRevoked},
+		{"session missing", models.RefreshToken{ExpiresAt: now.Add(time.Hour)}, nil, ErrSessionRevoked},
+		{"reused after revocation", models.RefreshToken{ExpiresAt: now.Add(time.Hour), UsedAt: &used}, &models.Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}, ErrSessionRevoked},
+	}
+	for _, tt := range tests {
+		if err := checkRefreshToken(&tt.token, tt.session, now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
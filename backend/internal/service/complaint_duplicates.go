@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+// Duplicate detection looks at unresolved complaints of the same category
+// filed within duplicateWindow and, when coordinates are known, within
+// duplicateRadiusMeters
+const (
+	duplicateRadiusMeters   = 300
+	duplicateWindow         = 60 * 24 * time.Hour
+	duplicateCandidateLimit = 50
+	duplicateThreshold      = 0.35
+	maxDuplicateSuggestions = 5
+)
+
+// duplicateStopWords are too common in complaints to say they are alike
+var duplicateStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "not": true, "near": true, "with": true,
+	"this": true, "that": true, "from": true, "our": true, "are": true, "was": true,
+	"has": true, "have": true, "been": true, "since": true, "there": true, "please": true,
+	"days": true, "working": true, "problem": true, "complaint": true,
+}
+
+// DuplicateSuggestion is a complaint that may report the same problem. Score
+// weighs text similarity against distance, both from 0 to 1.
+type DuplicateSuggestion struct {
+	Complaint      models.Complaint `json:"complaint"`
+	Score          float64          `json:"score"`
+	TextSimilarity float64          `json:"text_similarity"`
+	DistanceMeters *float64         `json:"distance_meters,omitempty"`
+}
+
+// SuggestDuplicates lists the complaints within the scope most likely to
+// report the same problem as a complaint, best match first
+func (s *ComplaintService) SuggestDuplicates(complaintID uint, scope models.JurisdictionScope) ([]DuplicateSuggestion, error) {
+	complaint, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+	return s.suggestDuplicates(complaint, scope)
+}
+
+func (s *ComplaintService) suggestDuplicates(complaint *models.Complaint, scope models.JurisdictionScope) ([]DuplicateSuggestion, error) {
+	candidates, err := s.complaintRepo.FindDuplicateCandidates(complaint, scope, duplicateRadiusMeters,
+		complaint.CreatedAt.Add(-duplicateWindow), duplicateCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	text := complaint.Title + " " + complaint.Description
+	var suggestions []DuplicateSuggestion
+	for _, candidate := range candidates {
+		suggestion := DuplicateSuggestion{
+			Complaint:      candidate,
+			TextSimilarity: textSimilarity(text, candidate.Title+" "+candidate.Description),
+		}
+
+		// Without both sets of coordinates the candidate is only known to be
+		// in the same village
+		proximity := 0.5
+		if complaint.Latitude != nil && candidate.Latitude != nil && complaint.Longitude != nil && candidate.Longitude != nil {
+			distance := utils.DistanceMeters(*complaint.Latitude, *complaint.Longitude, *candidate.Latitude, *candidate.Longitude)
+			suggestion.DistanceMeters = &distance
+			proximity = math.Max(0, 1-distance/duplicateRadiusMeters)
+		}
+
+		suggestion.Score = math.Round((0.6*suggestion.TextSimilarity+0.4*proximity)*100) / 100
+		if suggestion.Score >= duplicateThreshold {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	if len(suggestions) > maxDuplicateSuggestions {
+		suggestions = suggestions[:maxDuplicateSuggestions]
+	}
+	return suggestions, nil
+}
+
+// flagPossibleDuplicate records the best duplicate match of a new complaint
+// for staff. It is a hint only, so failures are logged.
+func (s *ComplaintService) flagPossibleDuplicate(complaint *models.Complaint) {
+	suggestions, err := s.suggestDuplicates(complaint, models.JurisdictionScope{All: true})
+	if err != nil {
+		log.Printf("complaint: finding duplicates of complaint %d: %v", complaint.ID, err)
+		return
+	}
+	if len(suggestions) == 0 {
+		return
+	}
+
+	best := suggestions[0].Complaint.ID
+	if err := s.complaintRepo.Update(complaint.ID, map[string]interface{}{"possible_duplicate_of": best}); err != nil {
+		log.Printf("complaint: flagging complaint %d as a duplicate: %v", complaint.ID, err)
+		return
+	}
+	complaint.PossibleDuplicateOf = &best
+}
+
+// MergeComplaint closes a complaint as a duplicate of a parent complaint.
+// Its reporter follows the parent's status updates from then on, and stats
+// count the two as one.
+func (s *ComplaintService) MergeComplaint(complaintID, parentID, actorID uint, note string) (*models.Complaint, error) {
+	if complaintID == parentID {
+		return nil, errors.New("a complaint cannot be merged into itself")
+	}
+	complaint, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.GetComplaint(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if complaint.MergedInto != nil {
+		return nil, fmt.Errorf("complaint is already merged into #%d", *complaint.MergedInto)
+	}
+	if parent.MergedInto != nil {
+		return nil, fmt.Errorf("complaint #%d is itself merged into #%d", parent.ID, *parent.MergedInto)
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		note = fmt.Sprintf("Merged into complaint #%d", parent.ID)
+	}
+	fields := map[string]interface{}{
+		"status":    models.ComplaintStatusClosed,
+		"closed_at": time.Now(),
+	}
+	change := &models.ComplaintStatusChange{
+		ComplaintID: complaint.ID,
+		FromStatus:  complaint.Status,
+		ToStatus:    models.ComplaintStatusClosed,
+		Note:        note,
+		ChangedBy:   actorID,
+	}
+	if err := s.complaintRepo.Merge(complaint.ID, parent.ID, complaint.Status, fields, change); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("complaint or parent changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+
+	if complaint.UserID != parent.UserID {
+		title := fmt.Sprintf("Complaint #%d merged", complaint.ID)
+		message := fmt.Sprintf("Your complaint %q was reported already and is followed up as complaint #%d. You will be notified of its progress.",
+			complaint.Title, parent.ID)
+		if err := s.notificationService.Notify(complaint.UserID, title, message, models.AuditEntityComplaint, parent.ID); err != nil {
+			log.Printf("complaint: notifying user %d of merge: %v", complaint.UserID, err)
+		}
+	}
+	return s.GetComplaint(parent.ID)
+}
+
+// IsReporter reports whether a user filed a complaint or a duplicate merged
+// into it
+func (s *ComplaintService) IsReporter(complaintID, userID uint) (bool, error) {
+	reporters, err := s.complaintRepo.ListReporters(complaintID)
+	if err != nil {
+		return false, err
+	}
+	for _, reporter := range reporters {
+		if reporter == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// notifyStatusChange tells every reporter of a complaint, including those of
+// merged duplicates, that its status changed
+func (s *ComplaintService) notifyStatusChange(complaint *models.Complaint, actorID uint) {
+	reporters, err := s.complaintRepo.ListReporters(complaint.ID)
+	if err != nil {
+		log.Printf("complaint: listing reporters of complaint %d: %v", complaint.ID, err)
+		return
+	}
+
+	title := fmt.Sprintf("Complaint #%d is now %s", complaint.ID, strings.ReplaceAll(complaint.Status, "_", " "))
+	message := fmt.Sprintf("%q is now %s.", complaint.Title, strings.ReplaceAll(complaint.Status, "_", " "))
+	if complaint.Resolution != "" && complaint.Status == models.ComplaintStatusResolved {
+		message += " " + complaint.Resolution
+	}
+	for _, userID := range reporters {
+		if userID == actorID {
+			continue
+		}
+		if err := s.notificationService.Notify(userID, title, message, models.AuditEntityComplaint, complaint.ID); err != nil {
+			log.Printf("complaint: notifying user %d of complaint %d: %v", userID, complaint.ID, err)
+		}
+	}
+}
+
+// textSimilarity is the share of distinct words two texts have in common
+// (Jaccard similarity), ignoring case, short words and common filler
+func textSimilarity(a, b string) float64 {
+	wordsA, wordsB := similarityWords(a), similarityWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+func similarityWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r)
+	}) {
+		if len([]rune(word)) < 3 || duplicateStopWords[word] {
+			continue
+		}
+		words[word] = true
+	}
+	return words
+}
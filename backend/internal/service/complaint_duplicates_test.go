@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestTextSimilarity(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Street light not working near temple", "Street light near the temple is not working", 1, 1},
+		{"Street light broken on Main Road", "Broken street light, main road", 1, 1},
+		{"Street light broken on Main Road", "Street light flickering at school", 0.2, 0.5},
+		{"No water supply in ward 3", "Garbage not collected", 0, 0},
+		{"", "Street light", 0, 0},
+	} {
+		got := textSimilarity(tc.a, tc.b)
+		if got < tc.min || got > tc.max {
+			t.Errorf("textSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tc.a, tc.b, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestSimilarityWordsKeepsDevanagari(t *testing.T) {
+	words := similarityWords("पाणी पुरवठा बंद आहे")
+	if !words["पाणी"] || !words["पुरवठा"] {
+		t.Errorf("expected Devanagari words to be kept whole, got %v", words)
+	}
+}
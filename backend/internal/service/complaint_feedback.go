@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+var (
+	ErrNotComplaintReporter = errors.New("only the reporter can give feedback on this complaint")
+	ErrFeedbackWindowClosed = errors.New("the time to rate or reopen this complaint has passed")
+)
+
+// lowRating is the highest rating the assignee is told about
+const lowRating = 2
+
+// ComplaintFeedbackInput is the reporter's rating of a resolution, from 1 to 5
+type ComplaintFeedbackInput struct {
+	Rating  int
+	Comment string
+}
+
+// Satisfaction is how reporters judged the complaints resolved at least
+// once: their ratings, and how many they reopened
+type Satisfaction struct {
+	Resolved      int64   `json:"resolved"`
+	Rated         int64   `json:"rated"`
+	AverageRating float64 `json:"average_rating"`
+	Reopened      int64   `json:"reopened"`
+	ReopenRatePct float64 `json:"reopen_rate_pct"`
+}
+
+// RateComplaint records the reporter's rating of a resolved complaint. A
+// resolution can be rated once; a low rating is passed on to the assignee.
+func (s *ComplaintService) RateComplaint(complaintID, userID uint, input ComplaintFeedbackInput) (*models.Complaint, error) {
+	now := time.Now()
+	complaint, err := s.feedbackComplaint(complaintID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if input.Rating < 1 || input.Rating > 5 {
+		return nil, errors.New("rating must be between 1 and 5")
+	}
+	if complaint.FeedbackRating != nil {
+		return nil, errors.New("this resolution has already been rated")
+	}
+
+	comment := strings.TrimSpace(input.Comment)
+	fields := map[string]interface{}{
+		"feedback_rating":  input.Rating,
+		"feedback_comment": comment,
+		"feedback_at":      now,
+	}
+	if err := s.complaintRepo.SaveFeedback(complaint.ID, complaint.Status, fields); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("complaint changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+
+	if input.Rating <= lowRating && complaint.AssignedTo != nil {
+		title := fmt.Sprintf("Complaint #%d rated %d/5", complaint.ID, input.Rating)
+		message := fmt.Sprintf("The reporter of %q rated its resolution %d out of 5.", complaint.Title, input.Rating)
+		if comment != "" {
+			message += " " + comment
+		}
+		if err := s.notificationService.Notify(*complaint.AssignedTo, title, message, models.AuditEntityComplaint, complaint.ID); err != nil {
+			log.Printf("complaint: notifying user %d of rating: %v", *complaint.AssignedTo, err)
+		}
+	}
+	return s.GetComplaint(complaint.ID)
+}
+
+// ReopenComplaint lets the reporter reopen a resolved complaint whose fix did
+// not hold. The SLA clock starts again from now.
+func (s *ComplaintService) ReopenComplaint(complaintID, userID uint, reason string) (*models.Complaint, error) {
+	now := time.Now()
+	complaint, err := s.feedbackComplaint(complaintID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to reopen a complaint")
+	}
+
+	fields := s.reopenFields(complaint.Category, now)
+	fields["status"] = models.ComplaintStatusReopened
+	change := &models.ComplaintStatusChange{
+		ComplaintID: complaint.ID,
+		FromStatus:  complaint.Status,
+		ToStatus:    models.ComplaintStatusReopened,
+		Note:        reason,
+		ChangedBy:   userID,
+	}
+	if err := s.complaintRepo.ChangeStatus(complaint.ID, complaint.Status, fields, change); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("complaint changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+
+	updated, err := s.GetComplaint(complaint.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStatusChange(updated, userID)
+	if updated.AssignedTo != nil {
+		title := fmt.Sprintf("Complaint #%d reopened", updated.ID)
+		message := fmt.Sprintf("The reporter reopened %q: %s", updated.Title, reason)
+		if err := s.notificationService.Notify(*updated.AssignedTo, title, message, models.AuditEntityComplaint, updated.ID); err != nil {
+			log.Printf("complaint: notifying user %d of reopen: %v", *updated.AssignedTo, err)
+		}
+	}
+	return updated, nil
+}
+
+// feedbackComplaint loads a complaint its reporter may still rate or reopen:
+// resolved, possibly closed since, and within the panchayat's reopen window
+func (s *ComplaintService) feedbackComplaint(complaintID, userID uint, now time.Time) (*models.Complaint, error) {
+	complaint, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+	if complaint.UserID != userID {
+		return nil, ErrNotComplaintReporter
+	}
+	if complaint.MergedInto != nil {
+		return nil, fmt.Errorf("complaint is merged into #%d and followed up there", *complaint.MergedInto)
+	}
+	if (complaint.Status != models.ComplaintStatusResolved && complaint.Status != models.ComplaintStatusClosed) ||
+		complaint.ResolvedAt == nil {
+		return nil, errors.New("only resolved complaints can be rated or reopened")
+	}
+	if now.After(complaint.ResolvedAt.Add(s.tenant.ComplaintReopenWindow())) {
+		return nil, ErrFeedbackWindowClosed
+	}
+	return complaint, nil
+}
+
+// reopenFields restarts a reopened complaint's SLA clock from now and clears
+// the outcome of its last resolution, feedback included
+func (s *ComplaintService) reopenFields(category string, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"reopened_at":      now,
+		"resolved_at":      nil,
+		"closed_at":        nil,
+		"sla_due_at":       now.Add(s.tenant.ComplaintSLATarget(category)),
+		"breached":         false,
+		"escalation_level": 0,
+		"escalated_at":     nil,
+		"feedback_rating":  nil,
+		"feedback_comment": "",
+		"feedback_at":      nil,
+		"reopen_count":     gorm.Expr("reopen_count + 1"),
+	}
+}
+
+func satisfaction(rows []repository.SatisfactionRow) Satisfaction {
+	var result Satisfaction
+	var ratingSum int64
+	for _, row := range rows {
+		result.Resolved += row.Resolved
+		result.Rated += row.Rated
+		result.Reopened += row.Reopened
+		ratingSum += row.RatingSum
+	}
+	if result.Rated > 0 {
+		result.AverageRating = math.Round(float64(ratingSum)/float64(result.Rated)*100) / 100
+	}
+	if result.Resolved > 0 {
+		result.ReopenRatePct = math.Round(float64(result.Reopened)/float64(result.Resolved)*10000) / 100
+	}
+	return result
+}
+
+func satisfactionBy(rows []repository.SatisfactionRow) map[string]Satisfaction {
+	byKey := make(map[string]Satisfaction, len(rows))
+	for _, row := range rows {
+		byKey[row.Key] = satisfaction([]repository.SatisfactionRow{row})
+	}
+	return byKey
+}
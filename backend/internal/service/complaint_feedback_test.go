@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"gram-panchayat/internal/repository"
+)
+
+func TestSatisfactionAveragesRatedComplaintsOnly(t *testing.T) {
+	rows := []repository.SatisfactionRow{
+		{Key: "water", Resolved: 10, Rated: 4, RatingSum: 18, Reopened: 1},
+		{Key: "road", Resolved: 6, Rated: 2, RatingSum: 3, Reopened: 3},
+		{Key: "garbage", Resolved: 4},
+	}
+
+	overall := satisfaction(rows)
+	if overall.Resolved != 20 || overall.Rated != 6 || overall.Reopened != 4 {
+		t.Errorf("unexpected totals %+v", overall)
+	}
+	if overall.AverageRating != 3.5 {
+		t.Errorf("expected an average rating of 3.5, got %.2f", overall.AverageRating)
+	}
+	if overall.ReopenRatePct != 20 {
+		t.Errorf("expected a 20%% reopen rate, got %.2f", overall.ReopenRatePct)
+	}
+
+	byCategory := satisfactionBy(rows)
+	if got := byCategory["road"]; got.AverageRating != 1.5 || got.ReopenRatePct != 50 {
+		t.Errorf("unexpected road satisfaction %+v", got)
+	}
+	if got := byCategory["garbage"]; got.AverageRating != 0 || got.ReopenRatePct != 0 {
+		t.Errorf("expected no rating for unrated garbage complaints, got %+v", got)
+	}
+}
@@ -1,6 +1,579 @@
 package service
 
-// TODO: restore original contents of complaint_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+var (
+	ErrComplaintNotFound  = errors.New("complaint not found")
+	ErrAssigneeNotFound   = errors.New("assignee not found")
+	ErrCommentNotAllowed  = errors.New("you cannot comment on this complaint")
+	ErrInternalNoteDenied = errors.New("only staff can add internal notes")
+)
+
+// maxCommentAttachments bounds the photos and documents on one comment
+const maxCommentAttachments = 5
+
+// maxGeoJSONFeatures bounds a GeoJSON export
+const maxGeoJSONFeatures = 5000
+
+// complaintAssigneeKey groups complaint stats by assignee
+const complaintAssigneeKey = "COALESCE(CAST(assigned_to AS TEXT), 'unassigned')"
+
+var complaintCategories = map[string]bool{
+	models.ComplaintCategoryInfrastructure: true,
+	models.ComplaintCategoryWater:          true,
+	models.ComplaintCategoryElectricity:    true,
+	models.ComplaintCategorySanitation:     true,
+	models.ComplaintCategoryRoad:           true,
+	models.ComplaintCategoryStreetLight:    true,
+	models.ComplaintCategoryGarbage:        true,
+	models.ComplaintCategoryOther:          true,
+}
+
+var complaintPriorities = map[string]bool{
+	models.ComplaintPriorityLow:    true,
+	models.ComplaintPriorityMedium: true,
+	models.ComplaintPriorityHigh:   true,
+	models.ComplaintPriorityUrgent: true,
+}
+
+// complaintTransitions lists the statuses a complaint may move to from each status.
+var complaintTransitions = map[string][]string{
+	models.ComplaintStatusOpen:         {models.ComplaintStatusAcknowledged, models.ComplaintStatusInProgress, models.ComplaintStatusResolved, models.ComplaintStatusClosed},
+	models.ComplaintStatusAcknowledged: {models.ComplaintStatusInProgress, models.ComplaintStatusResolved, models.ComplaintStatusClosed},
+	models.ComplaintStatusInProgress:   {models.ComplaintStatusResolved},
+	models.ComplaintStatusResolved:     {models.ComplaintStatusClosed, models.ComplaintStatusReopened},
+	models.ComplaintStatusClosed:       {models.ComplaintStatusReopened},
+	models.ComplaintStatusReopened:     {models.ComplaintStatusAcknowledged, models.ComplaintStatusInProgress, models.ComplaintStatusResolved, models.ComplaintStatusClosed},
+}
+
+// complaintStatusTimes are the columns recording when a complaint entered each status
+var complaintStatusTimes = map[string]string{
+	models.ComplaintStatusAcknowledged: "acknowledged_at",
+	models.ComplaintStatusInProgress:   "started_at",
+	models.ComplaintStatusResolved:     "resolved_at",
+	models.ComplaintStatusClosed:       "closed_at",
+	models.ComplaintStatusReopened:     "reopened_at",
+}
+
+type ComplaintService struct {
+	complaintRepo       *repository.ComplaintRepository
+	userRepo            *repository.UserRepository
+	notificationService *NotificationService
+	tenant              *models.Panchayat
+}
+
+func NewComplaintService(complaintRepo *repository.ComplaintRepository, userRepo *repository.UserRepository, notificationService *NotificationService) *ComplaintService {
+	return &ComplaintService{
+		complaintRepo:       complaintRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *ComplaintService) ForTenant(tenant *models.Panchayat) *ComplaintService {
+	bound := *s
+	bound.complaintRepo = s.complaintRepo.ForTenant(tenant.ID)
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	bound.notificationService = s.notificationService.ForTenant(tenant)
+	bound.tenant = tenant
+	return &bound
+}
+
+type CreateComplaintInput struct {
+	Title       string
+	Description string
+	Category    string
+	Location    string
+	Latitude    *float64
+	Longitude   *float64
+	Ward        string
+	Priority    string
+}
+
+// ComplaintPatch lists the complaint fields staff may change. A status
+// change is validated against complaintTransitions and recorded in the
+// complaint's history along with the note.
+type ComplaintPatch struct {
+	Status     *string `json:"status" binding:"omitempty,oneof=open acknowledged in_progress resolved closed reopened"`
+	Priority   *string `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	Category   *string `json:"category" binding:"omitempty,oneof=infrastructure water electricity sanitation road street_light garbage other"`
+	AssignedTo *uint   `json:"assigned_to"`
+	Location   *string `json:"location" binding:"omitempty,max=255"`
+	Ward       *string `json:"ward" binding:"omitempty,max=100"`
+	Resolution *string `json:"resolution" binding:"omitempty,max=2000"`
+	Note       *string `json:"note" binding:"omitempty,max=1000"`
+}
+
+// CommentInput is a message added to a complaint's thread
+type CommentInput struct {
+	Body        string
+	IsInternal  bool
+	Attachments []AttachmentInput
+}
+
+// AttachmentInput is an uploaded photo or document, by URL
+type AttachmentInput struct {
+	Kind string
+	URL  string
+	Name string
+}
+
+// CreateComplaint registers a complaint for the reporter's village
+func (s *ComplaintService) CreateComplaint(userID uint, input CreateComplaintInput) (*models.Complaint, error) {
+	reporter, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("reporter not found")
+	}
+
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+	if !complaintCategories[input.Category] {
+		return nil, fmt.Errorf("invalid category %q", input.Category)
+	}
+	priority := input.Priority
+	if priority == "" {
+		priority = models.ComplaintPriorityMedium
+	}
+	if !complaintPriorities[priority] {
+		return nil, fmt.Errorf("invalid priority %q", priority)
+	}
+	if err := validateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, err
+	}
+
+	dueAt := time.Now().Add(s.tenant.ComplaintSLATarget(input.Category))
+	complaint := &models.Complaint{
+		UserID:      userID,
+		Title:       title,
+		Description: strings.TrimSpace(input.Description),
+		Category:    input.Category,
+		Priority:    priority,
+		Status:      models.ComplaintStatusOpen,
+		Location:    strings.TrimSpace(input.Location),
+		Latitude:    input.Latitude,
+		Longitude:   input.Longitude,
+		Village:     reporter.Village,
+		Taluka:      reporter.Taluka,
+		Ward:        strings.TrimSpace(input.Ward),
+		SLADueAt:    &dueAt,
+	}
+	if err := s.complaintRepo.Create(complaint); err != nil {
+		return nil, err
+	}
+	s.flagPossibleDuplicate(complaint)
+	return complaint, nil
+}
+
+// GetComplaints lists complaints within the scope, and within an area when
+// geo is set
+func (s *ComplaintService) GetComplaints(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope, geo *utils.GeoFilter) ([]models.Complaint, int64, error) {
+	return s.complaintRepo.List(list, filters, scope, geo)
+}
+
+// ExportGeoJSON returns the newest complaints with coordinates as a GeoJSON
+// FeatureCollection for mapping tools
+func (s *ComplaintService) ExportGeoJSON(filters map[string]interface{}, scope models.JurisdictionScope, geo *utils.GeoFilter) ([]byte, error) {
+	complaints, err := s.complaintRepo.ListLocated(filters, scope, geo, maxGeoJSONFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]utils.GeoJSONFeature, 0, len(complaints))
+	for _, complaint := range complaints {
+		features = append(features, utils.GeoJSONFeature{
+			ID:        complaint.ID,
+			Latitude:  *complaint.Latitude,
+			Longitude: *complaint.Longitude,
+			Properties: map[string]interface{}{
+				"title":      complaint.Title,
+				"category":   complaint.Category,
+				"priority":   complaint.Priority,
+				"status":     complaint.Status,
+				"location":   complaint.Location,
+				"village":    complaint.Village,
+				"breached":   complaint.Breached,
+				"created_at": complaint.CreatedAt,
+			},
+		})
+	}
+	return utils.GeoJSON(features)
+}
+
+// GetHotspots clusters open complaints by location and category, largest
+// clusters first
+func (s *ComplaintService) GetHotspots(filters map[string]interface{}, scope models.JurisdictionScope, cellMeters float64, minCount int) ([]repository.Hotspot, error) {
+	if cellMeters < 10 || cellMeters > 5000 {
+		return nil, errors.New("cell size must be between 10 and 5000 meters")
+	}
+	if minCount < 2 {
+		return nil, errors.New("a hotspot needs at least 2 complaints")
+	}
+	return s.complaintRepo.Hotspots(filters, scope, cellMeters, minCount)
+}
+
+func (s *ComplaintService) GetComplaint(complaintID uint) (*models.Complaint, error) {
+	complaint, err := s.complaintRepo.GetByID(complaintID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrComplaintNotFound
+		}
+		return nil, err
+	}
+	return complaint, nil
+}
+
+// UpdateComplaint applies a staff patch. A status change is only made while
+// the complaint is still in the status it was read in.
+func (s *ComplaintService) UpdateComplaint(complaintID, actorID uint, patch ComplaintPatch) (*models.Complaint, error) {
+	complaint, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := patchUpdates(patch)
+	delete(fields, "status")
+	delete(fields, "note")
+	if patch.AssignedTo != nil {
+		if err := s.checkAssignee(*patch.AssignedTo); err != nil {
+			return nil, err
+		}
+	}
+	// The SLA target follows the category
+	if patch.Category != nil && *patch.Category != complaint.Category {
+		fields["sla_due_at"] = complaint.CreatedAt.Add(s.tenant.ComplaintSLATarget(*patch.Category))
+	}
+
+	if patch.Status == nil || *patch.Status == complaint.Status {
+		if len(fields) == 0 {
+			return nil, errors.New("no updatable complaint fields provided")
+		}
+		if err := s.complaintRepo.Update(complaintID, fields); err != nil {
+			return nil, err
+		}
+		return s.GetComplaint(complaintID)
+	}
+
+	status := *patch.Status
+	note := ""
+	if patch.Note != nil {
+		note = strings.TrimSpace(*patch.Note)
+	}
+	if err := checkStatusChange(complaint, status, note, patch.Resolution); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fields["status"] = status
+	fields[complaintStatusTimes[status]] = now
+	if status == models.ComplaintStatusReopened {
+		category := complaint.Category
+		if patch.Category != nil {
+			category = *patch.Category
+		}
+		for column, value := range s.reopenFields(category, now) {
+			fields[column] = value
+		}
+	}
+	// Resolving or closing stops the SLA clock; a late finish is a breach
+	// even if the scheduler has not caught it yet
+	if status == models.ComplaintStatusResolved || status == models.ComplaintStatusClosed {
+		dueAt := complaint.SLADueAt
+		if changed, ok := fields["sla_due_at"].(time.Time); ok {
+			dueAt = &changed
+		}
+		if dueAt != nil && now.After(*dueAt) {
+			fields["breached"] = true
+		}
+	}
+
+	change := &models.ComplaintStatusChange{
+		ComplaintID: complaintID,
+		FromStatus:  complaint.Status,
+		ToStatus:    status,
+		Note:        note,
+		ChangedBy:   actorID,
+	}
+	if err := s.complaintRepo.ChangeStatus(complaintID, complaint.Status, fields, change); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStatusChange(updated, actorID)
+	return updated, nil
+}
+
+// AddComment adds to a complaint's thread. The reporter may comment on their
+// own complaint; staff on complaints within their scope, and only staff may
+// add internal notes. The other side of the conversation is notified.
+func (s *ComplaintService) AddComment(complaintID, authorID uint, input CommentInput, staff bool, scope models.JurisdictionScope) (*models.ComplaintComment, error) {
+	complaint, err := s.GetComplaint(complaintID)
+	if err != nil {
+		return nil, err
+	}
+
+	isReporter := complaint.UserID == authorID
+	if !isReporter && complaint.DuplicateCount > 0 {
+		if isReporter, err = s.IsReporter(complaint.ID, authorID); err != nil {
+			return nil, err
+		}
+	}
+	asStaff := staff && scope.Allows(complaint.Village, complaint.Taluka)
+	if !isReporter && !asStaff {
+		return nil, ErrCommentNotAllowed
+	}
+	if input.IsInternal && !asStaff {
+		return nil, ErrInternalNoteDenied
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, errors.New("comment is required")
+	}
+	if len(input.Attachments) > maxCommentAttachments {
+		return nil, fmt.Errorf("a comment can have at most %d attachments", maxCommentAttachments)
+	}
+
+	author, err := s.userRepo.GetByID(authorID)
+	if err != nil {
+		return nil, errors.New("author not found")
+	}
+
+	comment := &models.ComplaintComment{
+		ComplaintID: complaintID,
+		AuthorID:    authorID,
+		AuthorName:  strings.TrimSpace(author.FirstName + " " + author.LastName),
+		IsStaff:     asStaff,
+		Body:        body,
+		IsInternal:  input.IsInternal,
+	}
+	for _, attachment := range input.Attachments {
+		if attachment.Kind != models.AttachmentKindPhoto && attachment.Kind != models.AttachmentKindDocument {
+			return nil, fmt.Errorf("invalid attachment kind %q", attachment.Kind)
+		}
+		url := strings.TrimSpace(attachment.URL)
+		if url == "" {
+			return nil, errors.New("attachment URL is required")
+		}
+		comment.Attachments = append(comment.Attachments, models.ComplaintAttachment{
+			Kind: attachment.Kind,
+			URL:  url,
+			Name: strings.TrimSpace(attachment.Name),
+		})
+	}
+
+	if err := s.complaintRepo.AddComment(comment); err != nil {
+		return nil, err
+	}
+
+	s.notifyComment(complaint, comment)
+	return comment, nil
+}
+
+// notifyComment tells the reporter about a staff reply, and the assignee
+// about anything else. Internal notes never reach the reporter.
+func (s *ComplaintService) notifyComment(complaint *models.Complaint, comment *models.ComplaintComment) {
+	recipient := complaint.AssignedTo
+	if comment.IsStaff && !comment.IsInternal {
+		recipient = &complaint.UserID
+	}
+	if recipient == nil || *recipient == comment.AuthorID {
+		return
+	}
+
+	title := fmt.Sprintf("New comment on complaint #%d", complaint.ID)
+	if err := s.notificationService.Notify(*recipient, title, comment.Body, models.AuditEntityComplaint, complaint.ID); err != nil {
+		log.Printf("complaint: notifying user %d of comment %d: %v", *recipient, comment.ID, err)
+	}
+}
+
+// AssignComplaint hands a complaint to a staff member of the panchayat
+func (s *ComplaintService) AssignComplaint(complaintID, assigneeID uint) (*models.Complaint, error) {
+	if err := s.checkAssignee(assigneeID); err != nil {
+		return nil, err
+	}
+	if err := s.complaintRepo.Update(complaintID, map[string]interface{}{"assigned_to": assigneeID}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrComplaintNotFound
+		}
+		return nil, err
+	}
+	return s.GetComplaint(complaintID)
+}
+
+// GetComplaintStats counts complaints by status, category and priority, and
+// sums SLA compliance and reporter satisfaction per category and assignee
+func (s *ComplaintService) GetComplaintStats() (map[string]interface{}, error) {
+	byStatus, err := s.complaintRepo.CountBy("status")
+	if err != nil {
+		return nil, err
+	}
+	byCategory, err := s.complaintRepo.CountBy("category")
+	if err != nil {
+		return nil, err
+	}
+	byPriority, err := s.complaintRepo.CountBy("priority")
+	if err != nil {
+		return nil, err
+	}
+	avgHours, err := s.complaintRepo.AverageResolutionHours()
+	if err != nil {
+		return nil, err
+	}
+	slaByCategory, err := s.complaintRepo.SLACompliance("category")
+	if err != nil {
+		return nil, err
+	}
+	slaByAssignee, err := s.complaintRepo.SLACompliance(complaintAssigneeKey)
+	if err != nil {
+		return nil, err
+	}
+	satisfactionByCategory, err := s.complaintRepo.Satisfaction("category")
+	if err != nil {
+		return nil, err
+	}
+	satisfactionByAssignee, err := s.complaintRepo.Satisfaction(complaintAssigneeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, count := range byStatus {
+		total += count
+	}
+
+	return map[string]interface{}{
+		"total":                total,
+		"by_status":            byStatus,
+		"by_category":          byCategory,
+		"by_priority":          byPriority,
+		"avg_resolution_hours": avgHours,
+		"sla": map[string]interface{}{
+			"overall":     slaCompliance(slaByCategory),
+			"by_category": slaComplianceBy(slaByCategory),
+			"by_assignee": slaComplianceBy(slaByAssignee),
+		},
+		"satisfaction": map[string]interface{}{
+			"overall":     satisfaction(satisfactionByCategory),
+			"by_category": satisfactionBy(satisfactionByCategory),
+			"by_assignee": satisfactionBy(satisfactionByAssignee),
+		},
+	}, nil
+}
+
+// SLACompliance is how many complaints with a decided SLA outcome were
+// resolved within target
+type SLACompliance struct {
+	Total         int64   `json:"total"`
+	Met           int64   `json:"met"`
+	Breached      int64   `json:"breached"`
+	CompliancePct float64 `json:"compliance_pct"`
+}
+
+func slaCompliance(rows []repository.SLARow) SLACompliance {
+	var result SLACompliance
+	for _, row := range rows {
+		result.Total += row.Total
+		result.Breached += row.Breached
+	}
+	result.Met = result.Total - result.Breached
+	if result.Total > 0 {
+		result.CompliancePct = math.Round(float64(result.Met)/float64(result.Total)*10000) / 100
+	}
+	return result
+}
+
+func slaComplianceBy(rows []repository.SLARow) map[string]SLACompliance {
+	byKey := make(map[string]SLACompliance, len(rows))
+	for _, row := range rows {
+		byKey[row.Key] = slaCompliance([]repository.SLARow{row})
+	}
+	return byKey
+}
+
+// checkAssignee makes sure complaints are only assigned to active staff of
+// the panchayat
+func (s *ComplaintService) checkAssignee(assigneeID uint) error {
+	assignee, err := s.userRepo.GetByID(assigneeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssigneeNotFound
+		}
+		return err
+	}
+	if !assignee.IsActive || assignee.Role == models.RoleCitizen {
+		return errors.New("complaints can only be assigned to active staff")
+	}
+	return nil
+}
+
+func validateCoordinates(latitude, longitude *float64) error {
+	if (latitude == nil) != (longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if latitude == nil {
+		return nil
+	}
+	if *latitude < -90 || *latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// checkStatusChange applies the rules of a staff status change: merged
+// complaints are changed through their parent, the move must be in
+// complaintTransitions, resolving needs a resolution and closing or
+// reopening a complaint that was never resolved needs a note
+func checkStatusChange(complaint *models.Complaint, status, note string, resolution *string) error {
+	if complaint.MergedInto != nil {
+		return fmt.Errorf("complaint is merged into #%d, update that complaint instead", *complaint.MergedInto)
+	}
+	if !isAllowedComplaintTransition(complaint.Status, status) {
+		return fmt.Errorf("cannot change complaint status from %s to %s", complaint.Status, status)
+	}
+
+	switch status {
+	case models.ComplaintStatusResolved:
+		text := complaint.Resolution
+		if resolution != nil {
+			text = strings.TrimSpace(*resolution)
+		}
+		if text == "" {
+			return errors.New("a resolution is required to resolve a complaint")
+		}
+	case models.ComplaintStatusClosed, models.ComplaintStatusReopened:
+		if note == "" && complaint.Status != models.ComplaintStatusResolved {
+			return fmt.Errorf("a note is required to mark a complaint %s", status)
+		}
+	}
+	return nil
+}
+
+func isAllowedComplaintTransition(from, to string) bool {
+	for _, next := range complaintTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
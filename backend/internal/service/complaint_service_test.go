@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	"gram-panchayat/internal/models"
+)
+
+func TestIsAllowedComplaintTransition(t *testing.T) {
+	statuses := []string{
+		models.ComplaintStatusOpen,
+		models.ComplaintStatusAcknowledged,
+		models.ComplaintStatusInProgress,
+		models.ComplaintStatusResolved,
+		models.ComplaintStatusClosed,
+		models.ComplaintStatusReopened,
+	}
+	allowed := map[[2]string]bool{}
+	for _, transition := range [][2]string{
+		{"open", "acknowledged"}, {"open", "in_progress"}, {"open", "resolved"}, {"open", "closed"},
+		{"acknowledged", "in_progress"}, {"acknowledged", "resolved"}, {"acknowledged", "closed"},
+		{"in_progress", "resolved"},
+		{"resolved", "closed"}, {"resolved", "reopened"},
+		{"closed", "reopened"},
+		{"reopened", "acknowledged"}, {"reopened", "in_progress"}, {"reopened", "resolved"}, {"reopened", "closed"},
+	} {
+		allowed[transition] = true
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := isAllowedComplaintTransition(from, to); got != allowed[[2]string{from, to}] {
+				t.Errorf("%s -> %s: expected allowed=%v", from, to, !got)
+			}
+		}
+	}
+}
+
+func TestCheckStatusChange(t *testing.T) {
+	parent := uint(3)
+	fixed, blank := "Pipe replaced", "  "
+
+	for name, tc := range map[string]struct {
+		complaint  models.Complaint
+		status     string
+		note       string
+		resolution *string
+		wantErr    bool
+	}{
+		"merged complaint": {
+			complaint: models.Complaint{Status: models.ComplaintStatusOpen, MergedInto: &parent},
+			status:    models.ComplaintStatusAcknowledged,
+			wantErr:   true,
+		},
+		"transition not allowed": {
+			complaint: models.Complaint{Status: models.ComplaintStatusInProgress},
+			status:    models.ComplaintStatusClosed,
+			note:      "duplicate",
+			wantErr:   true,
+		},
+		"resolve without a resolution": {
+			complaint: models.Complaint{Status: models.ComplaintStatusInProgress},
+			status:    models.ComplaintStatusResolved,
+			wantErr:   true,
+		},
+		"resolve with a blank resolution": {
+			complaint:  models.Complaint{Status: models.ComplaintStatusInProgress, Resolution: "earlier text"},
+			status:     models.ComplaintStatusResolved,
+			resolution: &blank,
+			wantErr:    true,
+		},
+		"resolve with a resolution": {
+			complaint:  models.Complaint{Status: models.ComplaintStatusInProgress},
+			status:     models.ComplaintStatusResolved,
+			resolution: &fixed,
+		},
+		"resolve again keeping the earlier resolution": {
+			complaint: models.Complaint{Status: models.ComplaintStatusReopened, Resolution: "Pipe replaced"},
+			status:    models.ComplaintStatusResolved,
+		},
+		"close an unresolved complaint without a note": {
+			complaint: models.Complaint{Status: models.ComplaintStatusOpen},
+			status:    models.ComplaintStatusClosed,
+			wantErr:   true,
+		},
+		"close an unresolved complaint with a note": {
+			complaint: models.Complaint{Status: models.ComplaintStatusOpen},
+			status:    models.ComplaintStatusClosed,
+			note:      "Raised twice",
+		},
+		"close a resolved complaint": {
+			complaint: models.Complaint{Status: models.ComplaintStatusResolved},
+			status:    models.ComplaintStatusClosed,
+		},
+		"reopen a closed complaint without a note": {
+			complaint: models.Complaint{Status: models.ComplaintStatusClosed},
+			status:    models.ComplaintStatusReopened,
+			wantErr:   true,
+		},
+		"reopen a resolved complaint": {
+			complaint: models.Complaint{Status: models.ComplaintStatusResolved},
+			status:    models.ComplaintStatusReopened,
+		},
+	} {
+		err := checkStatusChange(&tc.complaint, tc.status, tc.note, tc.resolution)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected error=%v, got %v", name, tc.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+// ComplaintSLAScheduler periodically escalates complaints that have stayed
+// unresolved past their SLA target, in every panchayat
+type ComplaintSLAScheduler struct {
+	complaintService *ComplaintService
+	complaintRepo    *repository.ComplaintRepository
+	panchayatRepo    *repository.PanchayatRepository
+	interval         time.Duration
+}
+
+func NewComplaintSLAScheduler(complaintService *ComplaintService, complaintRepo *repository.ComplaintRepository, panchayatRepo *repository.PanchayatRepository, interval time.Duration) *ComplaintSLAScheduler {
+	return &ComplaintSLAScheduler{
+		complaintService: complaintService,
+		complaintRepo:    complaintRepo.AllTenants(),
+		panchayatRepo:    panchayatRepo,
+		interval:         interval,
+	}
+}
+
+// Start checks for breaches now and then every interval until ctx is done
+func (s *ComplaintSLAScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			if err := s.RunOnce(time.Now()); err != nil {
+				log.Printf("complaint SLA: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce escalates every overdue complaint. Each panchayat's complaints are
+// handled with its own SLA settings; a failure on one complaint, or to load
+// one panchayat, is logged and does not hold up the rest.
+func (s *ComplaintSLAScheduler) RunOnce(now time.Time) error {
+	overdue, err := s.complaintRepo.ListOverdue(now)
+	if err != nil {
+		return err
+	}
+
+	services := map[uint]*ComplaintService{}
+	for i := range overdue {
+		complaint := &overdue[i]
+		service, loaded := services[complaint.PanchayatID]
+		if !loaded {
+			panchayat, err := s.panchayatRepo.GetByID(complaint.PanchayatID)
+			if err != nil {
+				log.Printf("complaint SLA: loading panchayat %d, skipping its complaints: %v", complaint.PanchayatID, err)
+			} else {
+				service = s.complaintService.ForTenant(panchayat)
+			}
+			services[complaint.PanchayatID] = service
+		}
+		if service == nil || !service.tenant.IsActive {
+			continue
+		}
+		if err := service.EscalateOverdue(complaint, now); err != nil {
+			log.Printf("complaint SLA: escalating complaint %d: %v", complaint.ID, err)
+		}
+	}
+	return nil
+}
+
+// EscalateOverdue marks an overdue complaint breached and notifies every
+// escalation step whose time has come since the last run
+func (s *ComplaintService) EscalateOverdue(complaint *models.Complaint, now time.Time) error {
+	if complaint.SLADueAt == nil || now.Before(*complaint.SLADueAt) {
+		return nil
+	}
+
+	chain := s.tenant.ComplaintEscalationChain()
+	level := escalationLevel(chain, *complaint.SLADueAt, complaint.EscalationLevel, now)
+	if complaint.Breached && level == complaint.EscalationLevel {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"breached":         true,
+		"escalation_level": level,
+	}
+	if level > complaint.EscalationLevel {
+		fields["escalated_at"] = now
+	}
+	if err := s.complaintRepo.Escalate(complaint.ID, complaint.EscalationLevel, fields); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			// Resolved or escalated by someone else in the meantime
+			return nil
+		}
+		return err
+	}
+
+	for _, step := range chain[complaint.EscalationLevel:level] {
+		s.notifyEscalation(complaint, step)
+	}
+	return nil
+}
+
+// escalationLevel is the number of steps of the chain whose time has come at
+// now, counting on from the steps already notified. A run that comes late
+// reaches every step it missed at once.
+func escalationLevel(chain []models.EscalationStep, dueAt time.Time, level int, now time.Time) int {
+	for level < len(chain) && !now.Before(dueAt.Add(time.Duration(chain[level].AfterHours)*time.Hour)) {
+		level++
+	}
+	return level
+}
+
+// notifyEscalation tells the holders of an escalation step about a breached
+// complaint. Steps nobody holds, such as the assignee of an unassigned
+// complaint, are skipped.
+func (s *ComplaintService) notifyEscalation(complaint *models.Complaint, step models.EscalationStep) {
+	var recipients []uint
+	if step.Role == models.EscalationAssignee {
+		if complaint.AssignedTo != nil {
+			recipients = append(recipients, *complaint.AssignedTo)
+		}
+	} else {
+		users, err := s.userRepo.ListActiveByRole(step.Role)
+		if err != nil {
+			log.Printf("complaint SLA: listing %s users: %v", step.Role, err)
+			return
+		}
+		for _, user := range users {
+			recipients = append(recipients, user.ID)
+		}
+	}
+
+	title := fmt.Sprintf("Complaint #%d is overdue", complaint.ID)
+	message := fmt.Sprintf("%q (%s) was due by %s and is still %s.",
+		complaint.Title, complaint.Category, complaint.SLADueAt.Format("02 Jan 2006 15:04"), complaint.Status)
+	for _, userID := range recipients {
+		if err := s.notificationService.Notify(userID, title, message, models.AuditEntityComplaint, complaint.ID); err != nil {
+			log.Printf("complaint SLA: notifying user %d of complaint %d: %v", userID, complaint.ID, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+func TestEscalationLevel(t *testing.T) {
+	due := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	chain := models.DefaultComplaintEscalation // assignee at 0h, Gram Sevak at 24h, Sarpanch at 48h
+
+	for name, tc := range map[string]struct {
+		from  int
+		after time.Duration
+		want  int
+	}{
+		"not yet due":                      {from: 0, after: -time.Minute, want: 0},
+		"just due":                         {from: 0, after: 0, want: 1},
+		"late run skips to the gram sevak": {from: 0, after: 30 * time.Hour, want: 2},
+		"late run reaches the end":         {from: 0, after: 72 * time.Hour, want: 3},
+		"next step not due yet":            {from: 1, after: 23 * time.Hour, want: 1},
+		"next step due":                    {from: 1, after: 24 * time.Hour, want: 2},
+		"every step notified":              {from: 3, after: 100 * time.Hour, want: 3},
+	} {
+		if got := escalationLevel(chain, due, tc.from, due.Add(tc.after)); got != tc.want {
+			t.Errorf("%s: expected level %d, got %d", name, tc.want, got)
+		}
+	}
+
+	custom := []models.EscalationStep{{Role: models.RoleGramSevak, AfterHours: 12}}
+	if got := escalationLevel(custom, due, 0, due.Add(6*time.Hour)); got != 0 {
+		t.Errorf("expected a chain starting after 12h to wait, got level %d", got)
+	}
+}
+
+func TestEscalateOverdueOnlyMovesFromTheLevelItRead(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	userRepo := repository.NewUserRepository(db)
+	notificationService := NewNotificationService(repository.NewNotificationRepository(db), userRepo, nil)
+	s := NewComplaintService(repository.NewComplaintRepository(db), userRepo, notificationService).
+		ForTenant(&models.Panchayat{ID: 2, IsActive: true})
+
+	now := time.Date(2026, 10, 2, 15, 0, 0, 0, time.UTC)
+	hoursAgo := func(hours int) *time.Time {
+		at := now.Add(-time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	for name, tc := range map[string]struct {
+		complaint models.Complaint
+		want      []string // in the UPDATE; nil when nothing is written
+	}{
+		"not due": {
+			complaint: models.Complaint{ID: 1, Status: models.ComplaintStatusOpen, SLADueAt: hoursAgo(-2)},
+		},
+		"newly breached": {
+			complaint: models.Complaint{ID: 2, Status: models.ComplaintStatusOpen, SLADueAt: hoursAgo(1)},
+			want:      []string{`"breached"=true`, `"escalation_level"=1`, "escalation_level = 0"},
+		},
+		"breached, skipping to the sarpanch": {
+			complaint: models.Complaint{ID: 3, Status: models.ComplaintStatusInProgress, SLADueAt: hoursAgo(50), Breached: true, EscalationLevel: 1},
+			want:      []string{`"escalation_level"=3`, "escalation_level = 1"},
+		},
+		"breached with no step due": {
+			complaint: models.Complaint{ID: 4, Status: models.ComplaintStatusOpen, SLADueAt: hoursAgo(10), Breached: true, EscalationLevel: 1},
+		},
+		"reopened after a breach": {
+			complaint: models.Complaint{ID: 5, Status: models.ComplaintStatusReopened, SLADueAt: hoursAgo(1), ReopenCount: 1},
+			want:      []string{`"breached"=true`, `"escalation_level"=1`, "escalation_level = 0", "'reopened'"},
+		},
+	} {
+		recorder.sqls = nil
+		complaint := tc.complaint
+		// The dry run updates no rows, which EscalateOverdue takes for a lost race
+		if err := s.EscalateOverdue(&complaint, now); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if tc.want == nil {
+			if len(recorder.sqls) != 0 {
+				t.Errorf("%s: expected nothing written, got %q", name, recorder.sqls)
+			}
+			continue
+		}
+		if len(recorder.sqls) != 1 {
+			t.Errorf("%s: expected one UPDATE, got %q", name, recorder.sqls)
+			continue
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(recorder.sqls[0], want) {
+				t.Errorf("%s: expected %q in %q", name, want, recorder.sqls[0])
+			}
+		}
+	}
+}
+
+func TestReopenFieldsRestartTheSLA(t *testing.T) {
+	s := &ComplaintService{tenant: &models.Panchayat{ID: 2}}
+	now := time.Date(2026, 10, 2, 15, 0, 0, 0, time.UTC)
+
+	fields := s.reopenFields(models.ComplaintCategoryWater, now)
+	if fields["breached"] != false || fields["escalation_level"] != 0 || fields["escalated_at"] != nil {
+		t.Errorf("expected the breach and escalation cleared, got %+v", fields)
+	}
+	if due := fields["sla_due_at"]; due != now.Add(s.tenant.ComplaintSLATarget(models.ComplaintCategoryWater)) {
+		t.Errorf("expected a new SLA target from the reopening, got %v", due)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+var (
+	ErrOwnJurisdictions       = errors.New("you cannot change your own jurisdictions")
+	ErrJurisdictionOutOfScope = errors.New("you can only assign villages and talukas you cover yourself")
+)
+
+// JurisdictionInput names one village or taluka a staff member covers
+type JurisdictionInput struct {
+	Level    string
+	Name     string
+	District string
+}
+
+type JurisdictionService struct {
+	jurisdictionRepo *repository.JurisdictionRepository
+	userRepo         *repository.UserRepository
+}
+
+func NewJurisdictionService(jurisdictionRepo *repository.JurisdictionRepository, userRepo *repository.UserRepository) *JurisdictionService {
+	return &JurisdictionService{
+		jurisdictionRepo: jurisdictionRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *JurisdictionService) ForTenant(tenant *models.Panchayat) *JurisdictionService {
+	bound := *s
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+// ScopeFor returns the places a user may see. Holders of jurisdiction.all
+// are unrestricted; everyone else is limited to their assigned
+// jurisdictions, which for most citizens is none.
+func (s *JurisdictionService) ScopeFor(userID uint, permissions map[string]bool) (models.JurisdictionScope, error) {
+	if permissions[models.PermJurisdictionAll] {
+		return models.JurisdictionScope{All: true}, nil
+	}
+
+	jurisdictions, err := s.jurisdictionRepo.ListByUser(userID)
+	if err != nil {
+		return models.JurisdictionScope{}, err
+	}
+	return models.NewJurisdictionScope(jurisdictions), nil
+}
+
+func (s *JurisdictionService) GetJurisdictions(userID uint) ([]models.StaffJurisdiction, error) {
+	return s.jurisdictionRepo.ListByUser(userID)
+}
+
+// SetJurisdictions replaces the villages and talukas a staff member covers.
+// The actor can only hand out places inside their own scope, so nobody can
+// widen anyone's reach past their own, and cannot change their own.
+func (s *JurisdictionService) SetJurisdictions(actorID uint, actorScope models.JurisdictionScope, userID uint, inputs []JurisdictionInput) ([]models.StaffJurisdiction, error) {
+	if actorID == userID {
+		return nil, ErrOwnJurisdictions
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	seen := map[string]bool{}
+	jurisdictions := make([]models.StaffJurisdiction, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Level != models.JurisdictionVillage && input.Level != models.JurisdictionTaluka {
+			return nil, errors.New("jurisdiction level must be village or taluka")
+		}
+		name := strings.TrimSpace(input.Name)
+		if name == "" {
+			return nil, errors.New("jurisdiction name is required")
+		}
+		covered := actorScope.Allows(name, "")
+		if input.Level == models.JurisdictionTaluka {
+			covered = actorScope.Allows("", name)
+		}
+		if !covered {
+			return nil, fmt.Errorf("%w: %s %s", ErrJurisdictionOutOfScope, input.Level, name)
+		}
+
+		key := input.Level + ":" + strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		jurisdictions = append(jurisdictions, models.StaffJurisdiction{
+			UserID:    userID,
+			Level:     input.Level,
+			Name:      name,
+			District:  strings.TrimSpace(input.District),
+			CreatedBy: actorID,
+		})
+	}
+
+	if err := s.jurisdictionRepo.Replace(userID, jurisdictions); err != nil {
+		return nil, err
+	}
+	return s.jurisdictionRepo.ListByUser(userID)
+}
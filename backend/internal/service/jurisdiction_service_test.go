@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+func TestSetJurisdictionsStaysWithinTheActorsScope(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	s := NewJurisdictionService(repository.NewJurisdictionRepository(db), repository.NewUserRepository(db)).
+		ForTenant(&models.Panchayat{ID: 2})
+	scoped := models.JurisdictionScope{Villages: []string{"shirur"}, Talukas: []string{"haveli"}}
+
+	for name, tc := range map[string]struct {
+		actorID uint
+		inputs  []JurisdictionInput
+		want    error
+	}{
+		"own jurisdictions": {
+			actorID: 9,
+			inputs:  []JurisdictionInput{{Level: models.JurisdictionVillage, Name: "Shirur"}},
+			want:    ErrOwnJurisdictions,
+		},
+		"village outside scope": {
+			actorID: 1,
+			inputs:  []JurisdictionInput{{Level: models.JurisdictionVillage, Name: "Shirur"}, {Level: models.JurisdictionVillage, Name: "Wagholi"}},
+			want:    ErrJurisdictionOutOfScope,
+		},
+		"taluka outside scope": {
+			actorID: 1,
+			inputs:  []JurisdictionInput{{Level: models.JurisdictionTaluka, Name: "Mulshi"}},
+			want:    ErrJurisdictionOutOfScope,
+		},
+		"village named like a covered taluka": {
+			actorID: 1,
+			inputs:  []JurisdictionInput{{Level: models.JurisdictionVillage, Name: "Haveli"}},
+			want:    ErrJurisdictionOutOfScope,
+		},
+	} {
+		recorder.sqls = nil
+		if _, err := s.SetJurisdictions(tc.actorID, scoped, 9, tc.inputs); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+		if recorderWrote(recorder, `"staff_jurisdictions"`) {
+			t.Errorf("%s: refused change still wrote %q", name, recorder.sqls)
+		}
+	}
+}
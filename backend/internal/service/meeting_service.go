@@ -1,52 +1,146 @@
 package service
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 	"gram-panchayat/internal/models"
 	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+const (
+	// defaultMeetingDuration is used for the calendar end time because
+	// meetings are only scheduled with a start time.
+	defaultMeetingDuration = 2 * time.Hour
+
+	// calendarLookback keeps recently past meetings in the feed so that late
+	// cancellations and postponements still reach subscribed calendars.
+	calendarLookback = 30 * 24 * time.Hour
 )
+
+// QuorumRule is the minimum number of attendees that must be recorded before a
+// meeting of a given type can be marked completed.
+type QuorumRule struct {
+	MinAttendees int
+}
+
+// meetingTransitions lists the statuses a meeting may move to from each status.
+var meetingTransitions = map[string][]string{
+	models.MeetingStatusScheduled:  {models.MeetingStatusInProgress, models.MeetingStatusPostponed, models.MeetingStatusCancelled},
+	models.MeetingStatusPostponed:  {models.MeetingStatusScheduled, models.MeetingStatusCancelled},
+	models.MeetingStatusInProgress: {models.MeetingStatusCompleted},
+}
+
+type CreateMeetingInput struct {
+	Title       string
+	Description string
+	MeetingType string
+	ScheduledAt string
+	Location    string
+	Agenda      string
+}
+
+type MinutesInput struct {
+	Content       string
+	Attendees     string
+	AttendeeCount int
+	Decisions     string
+}
+
 type MeetingService struct {
-	meetingRepo *repository.MeetingRepository
+	meetingRepo    *repository.MeetingRepository
+	attendanceRepo *repository.AttendanceRepository
+	quorumRules    map[string]QuorumRule
 }
 
-func NewMeetingService(meetingRepo *repository.MeetingRepository) *MeetingService {
-	return &MeetingService{meetingRepo: meetingRepo}
+func NewMeetingService(meetingRepo *repository.MeetingRepository, attendanceRepo *repository.AttendanceRepository) *MeetingService {
+	return &MeetingService{
+		meetingRepo:    meetingRepo,
+		attendanceRepo: attendanceRepo,
+		quorumRules:    map[string]QuorumRule{},
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *MeetingService) ForTenant(tenant *models.Panchayat) *MeetingService {
+	bound := *s
+	bound.meetingRepo = s.meetingRepo.ForTenant(tenant.ID)
+	bound.attendanceRepo = s.attendanceRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+// SetQuorumRules replaces the quorum rules, keyed by meeting type
+func (s *MeetingService) SetQuorumRules(rules map[string]QuorumRule) {
+	s.quorumRules = rules
+}
+
+// ParseQuorumRules parses a quorum specification such as
+// "gram_sabha=100,ward_sabha=15" into rules keyed by meeting type.
+func ParseQuorumRules(spec string) (map[string]QuorumRule, error) {
+	rules := map[string]QuorumRule{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		meetingType, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid quorum rule %q", part)
+		}
+
+		minAttendees, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || minAttendees < 0 {
+			return nil, fmt.Errorf("invalid quorum value for %s", meetingType)
+		}
+
+		rules[strings.TrimSpace(meetingType)] = QuorumRule{MinAttendees: minAttendees}
+	}
+	return rules, nil
 }
 
-func (s *MeetingService) GetMeetings(page, limit int) ([]models.Meeting, int64, error) {
-	return s.meetingRepo.List(page, limit)
+func (s *MeetingService) GetMeetings(list utils.ListQuery, filters map[string]interface{}) ([]models.Meeting, int64, error) {
+	return s.meetingRepo.List(list, filters)
 }
 
 func (s *MeetingService) GetMeeting(meetingID uint) (*models.Meeting, error) {
-	return s.meetingRepo.GetByID(meetingID)
+	meeting, err := s.meetingRepo.GetByID(meetingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("meeting not found")
+		}
+		return nil, err
+	}
+	return meeting, nil
 }
 
-func (s *MeetingService) CreateMeeting(req interface{}) (*models.Meeting, error) {
-	r := req.(*struct {
-		Title       string
-		Description string
-		MeetingType string
-		ScheduledAt string
-		Location    string
-		Agenda      string
-	})
+// GetMinutesHistory returns every recorded revision of a meeting's minutes
+func (s *MeetingService) GetMinutesHistory(meetingID uint) ([]models.MeetingMinutes, error) {
+	if _, err := s.GetMeeting(meetingID); err != nil {
+		return nil, err
+	}
+	return s.meetingRepo.GetMinutesHistory(meetingID)
+}
 
-	scheduledAt, err := time.Parse(time.RFC3339, r.ScheduledAt)
+func (s *MeetingService) CreateMeeting(input CreateMeetingInput) (*models.Meeting, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, input.ScheduledAt)
 	if err != nil {
 		return nil, errors.New("invalid scheduled time format")
 	}
 
 	meeting := &models.Meeting{
-		Title:       r.Title,
-		Description: r.Description,
-		MeetingType: r.MeetingType,
+		Title:       input.Title,
+		Description: input.Description,
+		MeetingType: input.MeetingType,
 		ScheduledAt: scheduledAt,
-		Location:    r.Location,
-		Agenda:      r.Agenda,
-		Status:      "scheduled",
+		Location:    input.Location,
+		Agenda:      input.Agenda,
+		Status:      models.MeetingStatusScheduled,
 	}
 
 	if err := s.meetingRepo.Create(meeting); err != nil {
@@ -56,27 +150,203 @@ func (s *MeetingService) CreateMeeting(req interface{}) (*models.Meeting, error)
 	return meeting, nil
 }
 
-func (s *MeetingService) AddMinutes(meetingID, adminID uint, req interface{}) (*models.MeetingMinutes, error) {
-	r := req.(*struct {
-		Content   string
-		Attendees string
-		Decisions string
-	})
+// AddMinutes records a new revision of the minutes. Minutes can only be
+// recorded once the meeting has started; the meeting is completed separately
+// through ChangeStatus so the quorum can be checked.
+func (s *MeetingService) AddMinutes(meetingID, adminID uint, input MinutesInput) (*models.MeetingMinutes, error) {
+	meeting, err := s.GetMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch meeting.Status {
+	case models.MeetingStatusInProgress, models.MeetingStatusCompleted:
+	case models.MeetingStatusCancelled:
+		return nil, errors.New("cannot record minutes for a cancelled meeting")
+	default:
+		return nil, fmt.Errorf("cannot record minutes for a %s meeting", meeting.Status)
+	}
+
+	if input.AttendeeCount < 0 {
+		return nil, errors.New("attendee count cannot be negative")
+	}
 
 	minutes := &models.MeetingMinutes{
-		MeetingID:  meetingID,
-		Content:    r.Content,
-		Attendees:  r.Attendees,
-		Decisions:  r.Decisions,
-		RecordedBy: adminID,
+		MeetingID:     meetingID,
+		Content:       input.Content,
+		Attendees:     input.Attendees,
+		AttendeeCount: input.AttendeeCount,
+		Decisions:     input.Decisions,
+		RecordedBy:    adminID,
 	}
 
 	if err := s.meetingRepo.CreateMinutes(minutes); err != nil {
 		return nil, err
 	}
 
-	// Update meeting status
-	s.meetingRepo.Update(meetingID, map[string]interface{}{"status": "completed"})
-
 	return minutes, nil
-}
\ No newline at end of file
+}
+
+// ChangeStatus moves a meeting through its lifecycle:
+// scheduled -> in_progress -> completed, scheduled -> postponed/cancelled and
+// postponed -> scheduled (with a new date) or cancelled. Postponement and
+// cancellation require a reason, and completion requires the quorum for the
+// meeting type to be met.
+func (s *MeetingService) ChangeStatus(meetingID, userID uint, status, reason string, rescheduleTo *time.Time) (*models.Meeting, error) {
+	meeting, err := s.GetMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAllowedTransition(meeting.Status, status) {
+		return nil, fmt.Errorf("cannot change meeting status from %s to %s", meeting.Status, status)
+	}
+
+	reason = strings.TrimSpace(reason)
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":        status,
+		"status_reason": reason,
+	}
+
+	switch status {
+	case models.MeetingStatusPostponed, models.MeetingStatusCancelled:
+		if reason == "" {
+			return nil, fmt.Errorf("a reason is required to mark a meeting %s", status)
+		}
+	case models.MeetingStatusScheduled:
+		if rescheduleTo == nil {
+			return nil, errors.New("a new scheduled time is required to reschedule a meeting")
+		}
+		fields["scheduled_at"] = *rescheduleTo
+	case models.MeetingStatusInProgress:
+		fields["started_at"] = now
+	case models.MeetingStatusCompleted:
+		if err := s.checkQuorum(meeting); err != nil {
+			return nil, err
+		}
+		fields["completed_at"] = now
+	}
+
+	change := &models.MeetingStatusChange{
+		MeetingID:  meetingID,
+		FromStatus: meeting.Status,
+		ToStatus:   status,
+		Reason:     reason,
+		ChangedBy:  userID,
+	}
+
+	if err := s.meetingRepo.ChangeStatus(meetingID, meeting.Status, fields, change); err != nil {
+		return nil, err
+	}
+
+	return s.GetMeeting(meetingID)
+}
+
+// CalendarFeed returns an iCalendar feed of upcoming meetings, optionally
+// restricted to one meeting type.
+func (s *MeetingService) CalendarFeed(meetingType string) ([]byte, error) {
+	filters := map[string]interface{}{}
+	if meetingType != "" {
+		filters["meeting_type"] = meetingType
+	}
+
+	meetings, err := s.meetingRepo.ListForCalendar(time.Now().Add(-calendarLookback), filters)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]utils.ICalEvent, 0, len(meetings))
+	for _, meeting := range meetings {
+		events = append(events, meetingEvent(meeting))
+	}
+
+	return utils.ICalendar("Gram Panchayat Meetings", "", events), nil
+}
+
+// MeetingInvite returns a single-meeting iCalendar file
+func (s *MeetingService) MeetingInvite(meetingID uint) ([]byte, error) {
+	meeting, err := s.GetMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ICalendar("", "PUBLISH", []utils.ICalEvent{meetingEvent(*meeting)}), nil
+}
+
+// meetingEvent maps a meeting to a calendar event. The UID depends only on
+// the meeting ID and the sequence on the number of status changes, so a
+// postponement or cancellation updates the entry already in the calendar.
+func meetingEvent(meeting models.Meeting) utils.ICalEvent {
+	event := utils.ICalEvent{
+		UID:          fmt.Sprintf("meeting-%d@gram-panchayat", meeting.ID),
+		Sequence:     len(meeting.StatusHistory),
+		Summary:      meeting.Title,
+		Location:     meeting.Location,
+		Start:        meeting.ScheduledAt,
+		End:          meeting.ScheduledAt.Add(defaultMeetingDuration),
+		Status:       utils.ICalStatusConfirmed,
+		LastModified: meeting.UpdatedAt,
+	}
+
+	var description []string
+	if meeting.Description != "" {
+		description = append(description, meeting.Description)
+	}
+	if meeting.Agenda != "" {
+		description = append(description, "Agenda:\n"+meeting.Agenda)
+	}
+
+	switch meeting.Status {
+	case models.MeetingStatusCancelled:
+		event.Status = utils.ICalStatusCancelled
+		event.Summary = "CANCELLED: " + meeting.Title
+		description = append([]string{"Cancelled: " + meeting.StatusReason}, description...)
+	case models.MeetingStatusPostponed:
+		event.Status = utils.ICalStatusTentative
+		event.Summary = "POSTPONED: " + meeting.Title
+		description = append([]string{"Postponed: " + meeting.StatusReason}, description...)
+	}
+
+	event.Description = strings.Join(description, "\n\n")
+	return event
+}
+
+func (s *MeetingService) checkQuorum(meeting *models.Meeting) error {
+	rule, ok := s.quorumRules[meeting.MeetingType]
+	if !ok || rule.MinAttendees == 0 {
+		return nil
+	}
+
+	// The attendance register is authoritative; the head count in the
+	// minutes is only used for meetings without a register.
+	present, err := s.attendanceRepo.CountPresent(meeting.ID)
+	if err != nil {
+		return err
+	}
+
+	attendees := int(present)
+	if attendees == 0 {
+		minutes, err := s.meetingRepo.GetLatestMinutes(meeting.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if minutes != nil {
+			attendees = minutes.AttendeeCount
+		}
+	}
+
+	if attendees < rule.MinAttendees {
+		return fmt.Errorf("quorum not met: %d attendees recorded, %d required", attendees, rule.MinAttendees)
+	}
+	return nil
+}
+
+func isAllowedTransition(from, to string) bool {
+	for _, next := range meetingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,80 @@
 package service
 
-// TODO: restore original contents of notification_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"log"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	sender           Sender
+}
+
+// NewNotificationService takes the sender used to text notifications to
+// users' phones; nil only stores them in-app
+func NewNotificationService(notificationRepo *repository.NotificationRepository, userRepo *repository.UserRepository, sender Sender) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		sender:           sender,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *NotificationService) ForTenant(tenant *models.Panchayat) *NotificationService {
+	bound := *s
+	bound.notificationRepo = s.notificationRepo.ForTenant(tenant.ID)
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+// Notify stores an in-app notification for a user and texts it to their
+// phone. The stored notification is what counts, so a failed text is only
+// logged.
+func (s *NotificationService) Notify(userID uint, title, message, entityType string, entityID uint) error {
+	notification := &models.Notification{
+		UserID:     userID,
+		Title:      title,
+		Message:    message,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return err
+	}
+
+	if s.sender == nil {
+		return nil
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user.PhoneNumber == "" {
+		return nil
+	}
+	if err := s.sender.Send(user.PhoneNumber, title, title+": "+message); err != nil {
+		log.Printf("notification: failed to text user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// GetNotifications lists a user's notifications, newest first
+func (s *NotificationService) GetNotifications(userID uint, list utils.ListQuery, unreadOnly bool) ([]models.Notification, int64, error) {
+	return s.notificationRepo.ListByUser(userID, list, unreadOnly)
+}
+
+func (s *NotificationService) MarkRead(userID, notificationID uint) error {
+	if err := s.notificationRepo.MarkRead(notificationID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+const (
+	otpValidity    = 10 * time.Minute
+	otpMaxAttempts = 5
+	otpResendDelay = time.Minute
+	// otpHourlyLimit caps how many codes one email address or phone number
+	// can receive per hour, whatever the purpose.
+	otpHourlyLimit = 5
+)
+
+var (
+	ErrOTPInvalid          = errors.New("invalid or expired OTP")
+	ErrOTPAttemptsExceeded = errors.New("too many incorrect attempts, please request a new OTP")
+	ErrOTPResendTooSoon    = errors.New("please wait before requesting another OTP")
+	ErrOTPLimitReached     = errors.New("too many OTPs requested, please try again later")
+)
+
+var otpSubjects = map[string]string{
+	models.OTPPurposeRegistration:  "Verify your Gram Panchayat account",
+	models.OTPPurposePasswordReset: "Gram Panchayat password reset",
+	models.OTPPurposePhoneChange:   "Confirm your new phone number",
+	models.OTPPurposeLogin:         "Gram Panchayat login code",
+}
+
+// OTPService issues and verifies one-time codes. Codes are stored hashed,
+// scoped to a purpose and destination, and allow a limited number of
+// attempts.
+type OTPService struct {
+	otpRepo *repository.OTPRepository
+	senders map[string]Sender
+}
+
+// NewOTPService takes the sender used for each channel (email, sms)
+func NewOTPService(otpRepo *repository.OTPRepository, senders map[string]Sender) *OTPService {
+	return &OTPService{
+		otpRepo: otpRepo,
+		senders: senders,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *OTPService) ForTenant(tenant *models.Panchayat) *OTPService {
+	bound := *s
+	bound.otpRepo = s.otpRepo.ForTenant(tenant.ID)
+	return &bound
+}
+
+// Issue generates a code for the purpose, replacing any earlier code for the
+// same destination, and delivers it over the channel.
+func (s *OTPService) Issue(purpose, channel, destination string, userID *uint) error {
+	sender, ok := s.senders[channel]
+	if !ok {
+		return fmt.Errorf("unsupported OTP channel %q", channel)
+	}
+	subject, ok := otpSubjects[purpose]
+	if !ok {
+		return fmt.Errorf("unsupported OTP purpose %q", purpose)
+	}
+
+	if latest, err := s.otpRepo.GetLatest(purpose, destination); err == nil {
+		if time.Since(latest.CreatedAt) < otpResendDelay {
+			return ErrOTPResendTooSoon
+		}
+	}
+
+	issued, err := s.otpRepo.CountIssuedSince(destination, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if issued >= otpHourlyLimit {
+		return ErrOTPLimitReached
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(code)
+	if err != nil {
+		return err
+	}
+
+	record := &models.OTPCode{
+		UserID:      userID,
+		Purpose:     purpose,
+		Channel:     channel,
+		Destination: destination,
+		CodeHash:    hash,
+		MaxAttempts: otpMaxAttempts,
+		ExpiresAt:   time.Now().Add(otpValidity),
+	}
+	if err := s.otpRepo.Replace(record); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your OTP is %s. It is valid for %d minutes. Do not share it with anyone.",
+		code, int(otpValidity.Minutes()))
+	return sender.Send(destination, subject, body)
+}
+
+// Verify checks a code and consumes it on success. Every call counts as an
+// attempt, whether or not the code is correct.
+func (s *OTPService) Verify(purpose, destination, code string) (*models.OTPCode, error) {
+	record, err := s.otpRepo.GetLatest(purpose, destination)
+	if err != nil {
+		return nil, ErrOTPInvalid
+	}
+	if err := checkOTPUsable(record, purpose, time.Now()); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.otpRepo.RecordAttempt(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrOTPAttemptsExceeded
+	}
+
+	if !utils.CheckPassword(record.CodeHash, code) {
+		return nil, ErrOTPInvalid
+	}
+
+	consumed, err := s.otpRepo.Consume(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrOTPInvalid
+	}
+
+	return record, nil
+}
+
+// checkOTPUsable rejects a code issued for another purpose, used, expired or
+// out of attempts. RecordAttempt enforces the attempt limit again atomically.
+func checkOTPUsable(record *models.OTPCode, purpose string, now time.Time) error {
+	if record.Purpose != purpose {
+		return ErrOTPInvalid
+	}
+	if record.ConsumedAt != nil || now.After(record.ExpiresAt) {
+		return ErrOTPInvalid
+	}
+	if record.Attempts >= record.MaxAttempts {
+		return ErrOTPAttemptsExceeded
+	}
+	return nil
+}
+
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
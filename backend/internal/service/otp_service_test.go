@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/utils"
+)
+
+func TestCheckOTPUsable(t *testing.T) {
+	now := time.Now()
+	consumed := now.Add(-time.Minute)
+	live := models.OTPCode{
+		Purpose:     models.OTPPurposeLogin,
+		MaxAttempts: otpMaxAttempts,
+		ExpiresAt:   now.Add(otpValidity),
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(*models.OTPCode)
+		purpose string
+		want    error
+	}{
+		{"live", func(*models.OTPCode) {}, models.OTPPurposeLogin, nil},
+		{"other purpose", func(*models.OTPCode) {}, models.OTPPurposePasswordReset, ErrOTPInvalid},
+		{"consumed", func(c *models.OTPCode) { c.ConsumedAt = &consumed }, models.OTPPurposeLogin, ErrOTPInvalid},
+		{"expired", func(c *models.OTPCode) { c.ExpiresAt = now.Add(-time.Second) }, models.OTPPurposeLogin, ErrOTPInvalid},
+		{"last attempt", func(c *models.OTPCode) { c.Attempts = otpMaxAttempts - 1 }, models.OTPPurposeLogin, nil},
+		{"attempts used up", func(c *models.OTPCode) { c.Attempts = otpMaxAttempts }, models.OTPPurposeLogin, ErrOTPAttemptsExceeded},
+	}
+	for _, tt := range tests {
+		record := live
+		tt.edit(&record)
+		if err := checkOTPUsable(&record, tt.purpose, now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestOTPCodesAreStoredHashed(t *testing.T) {
+	code, err := generateOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		t.Fatalf("expected a 6-digit code, got %q", code)
+	}
+
+	hash, err := utils.HashPassword(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(hash, code) {
+		t.Error("expected the stored hash not to contain the code")
+	}
+	if !utils.CheckPassword(hash, code) {
+		t.Error("expected the code to match its hash")
+	}
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if utils.CheckPassword(hash, wrong) {
+		t.Error("expected another code not to match")
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+// panchayatCacheTTL bounds how long settings edited on another instance can
+// stay stale
+const panchayatCacheTTL = time.Minute
+
+var ErrPanchayatNotFound = errors.New("panchayat not found")
+
+// PanchayatSettingsInput is the tenant configuration an administrator edits
+type PanchayatSettingsInput struct {
+	Name                    string
+	LGDCode                 string
+	LogoURL                 string
+	FinancialYearStartMonth int
+	TaxRates                map[string]float64
+}
+
+// ComplaintSLAInput is a panchayat's complaint resolution targets, in hours
+// per category, its escalation chain, and how many days reporters have to
+// rate or reopen a resolved complaint (0 for the default)
+type ComplaintSLAInput struct {
+	Hours      map[string]int
+	Escalation []models.EscalationStep
+	ReopenDays int
+}
+
+// maxComplaintReopenDays bounds a panchayat's complaint reopen window
+const maxComplaintReopenDays = 90
+
+type cachedPanchayat struct {
+	panchayat *models.Panchayat
+	loadedAt  time.Time
+}
+
+type PanchayatService struct {
+	panchayatRepo *repository.PanchayatRepository
+
+	mu    sync.RWMutex
+	cache map[string]cachedPanchayat
+}
+
+func NewPanchayatService(panchayatRepo *repository.PanchayatRepository) *PanchayatService {
+	return &PanchayatService{
+		panchayatRepo: panchayatRepo,
+		cache:         map[string]cachedPanchayat{},
+	}
+}
+
+// Resolve looks up an active panchayat by slug. Results are cached for
+// panchayatCacheTTL since every request resolves its tenant.
+func (s *PanchayatService) Resolve(slug string) (*models.Panchayat, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	s.mu.RLock()
+	cached, ok := s.cache[slug]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < panchayatCacheTTL {
+		return cached.panchayat, nil
+	}
+
+	panchayat, err := s.panchayatRepo.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPanchayatNotFound
+		}
+		return nil, err
+	}
+	if !panchayat.IsActive {
+		return nil, ErrPanchayatNotFound
+	}
+
+	s.mu.Lock()
+	s.cache[slug] = cachedPanchayat{panchayat: panchayat, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return panchayat, nil
+}
+
+func (s *PanchayatService) GetPanchayat(id uint) (*models.Panchayat, error) {
+	panchayat, err := s.panchayatRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPanchayatNotFound
+		}
+		return nil, err
+	}
+	return panchayat, nil
+}
+
+// GetPanchayats lists every panchayat, active or not
+func (s *PanchayatService) GetPanchayats() ([]models.Panchayat, error) {
+	return s.panchayatRepo.List()
+}
+
+// UpdateSettings changes a panchayat's name, codes, logo, financial year and
+// tax rates
+func (s *PanchayatService) UpdateSettings(id uint, input PanchayatSettingsInput) (*models.Panchayat, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if input.FinancialYearStartMonth < 1 || input.FinancialYearStartMonth > 12 {
+		return nil, errors.New("financial year start month must be between 1 and 12")
+	}
+	for propertyType, rate := range input.TaxRates {
+		if !propertyTypes[propertyType] {
+			return nil, fmt.Errorf("unknown property type %q in tax rates", propertyType)
+		}
+		if rate < 0 {
+			return nil, errors.New("tax rates cannot be negative")
+		}
+	}
+
+	if input.TaxRates == nil {
+		input.TaxRates = map[string]float64{}
+	}
+	// Map updates bypass the model's JSON serializer
+	taxRates, err := json.Marshal(input.TaxRates)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":                       strings.TrimSpace(input.Name),
+		"lgd_code":                   strings.TrimSpace(input.LGDCode),
+		"logo_url":                   strings.TrimSpace(input.LogoURL),
+		"financial_year_start_month": input.FinancialYearStartMonth,
+		"tax_rates":                  string(taxRates),
+	}
+	if err := s.panchayatRepo.Update(id, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPanchayatNotFound
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = map[string]cachedPanchayat{}
+	s.mu.Unlock()
+
+	return s.GetPanchayat(id)
+}
+
+// UpdateComplaintSLA replaces a panchayat's complaint SLA targets,
+// escalation chain and reopen window. Complaints already filed keep their
+// due dates. Steps name a role, or "assignee", and must be in order of
+// AfterHours.
+func (s *PanchayatService) UpdateComplaintSLA(id uint, input ComplaintSLAInput) (*models.Panchayat, error) {
+	for category, hours := range input.Hours {
+		if !complaintCategories[category] {
+			return nil, fmt.Errorf("unknown complaint category %q in SLA targets", category)
+		}
+		if hours <= 0 {
+			return nil, errors.New("SLA targets must be at least one hour")
+		}
+	}
+	for i, step := range input.Escalation {
+		input.Escalation[i].Role = strings.TrimSpace(step.Role)
+		if input.Escalation[i].Role == "" {
+			return nil, errors.New("every escalation step needs a role")
+		}
+		if step.AfterHours < 0 {
+			return nil, errors.New("escalation steps cannot come before the SLA target")
+		}
+		if i > 0 && step.AfterHours < input.Escalation[i-1].AfterHours {
+			return nil, errors.New("escalation steps must be in order of after_hours")
+		}
+	}
+
+	if input.ReopenDays < 0 || input.ReopenDays > maxComplaintReopenDays {
+		return nil, fmt.Errorf("the reopen window must be between 0 (the default) and %d days", maxComplaintReopenDays)
+	}
+
+	if input.Hours == nil {
+		input.Hours = map[string]int{}
+	}
+	if input.Escalation == nil {
+		input.Escalation = []models.EscalationStep{}
+	}
+	// Map updates bypass the model's JSON serializer
+	hours, err := json.Marshal(input.Hours)
+	if err != nil {
+		return nil, err
+	}
+	escalation, err := json.Marshal(input.Escalation)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"complaint_sla_hours":   string(hours),
+		"complaint_escalation":  string(escalation),
+		"complaint_reopen_days": input.ReopenDays,
+	}
+	if err := s.panchayatRepo.Update(id, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPanchayatNotFound
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = map[string]cachedPanchayat{}
+	s.mu.Unlock()
+
+	return s.GetPanchayat(id)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+)
+
+// patchUpdates turns a typed patch into column updates. Patches declare every
+// updatable field as a pointer tagged with its column's JSON name; nil fields
+// were not sent and are left unchanged.
+func patchUpdates(patch interface{}) map[string]interface{} {
+	updates := map[string]interface{}{}
+	value := reflect.Indirect(reflect.ValueOf(patch))
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		column, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if column == "" || column == "-" || value.Field(i).Kind() != reflect.Ptr || value.Field(i).IsNil() {
+			continue
+		}
+		updates[column] = value.Field(i).Elem().Interface()
+	}
+	return updates
+}
+
+// NoticePatch lists the fields UpdateNotice may change. The author and
+// panchayat of a notice are fixed.
+type NoticePatch struct {
+	Title       *string `json:"title" binding:"omitempty,min=1,max=255"`
+	Content     *string `json:"content" binding:"omitempty,min=1"`
+	Category    *string `json:"category" binding:"omitempty,max=100"`
+	Priority    *string `json:"priority" binding:"omitempty,max=50"`
+	IsPublished *bool   `json:"is_published"`
+}
+
+// Updates returns the columns the patch sets
+func (p NoticePatch) Updates() map[string]interface{} {
+	return patchUpdates(p)
+}
+
+// SchemePatch lists the fields UpdateScheme may change
+type SchemePatch struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+	Category    *string `json:"category" binding:"omitempty,max=100"`
+	StartDate   *string `json:"start_date" binding:"omitempty,min=1"`
+	EndDate     *string `json:"end_date"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// Updates returns the columns the patch sets
+func (p SchemePatch) Updates() map[string]interface{} {
+	return patchUpdates(p)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"gram-panchayat/internal/utils"
+)
+
+func TestNoticeAndSchemePatchesRejectFixedFields(t *testing.T) {
+	for name, patch := range map[string]interface{}{
+		"notice": &NoticePatch{},
+		"scheme": &SchemePatch{},
+	} {
+		for _, field := range []string{"id", "panchayat_id", "created_by", "created_at"} {
+			err := utils.DecodePatch([]byte(`{"`+field+`":1}`), patch)
+
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != field {
+				t.Errorf("%s %s: expected the field to be rejected, got %v", name, field, err)
+			}
+		}
+	}
+}
+
+func TestNoticePatchUpdatesOnlySentFields(t *testing.T) {
+	published := false
+	updates := NoticePatch{IsPublished: &published}.Updates()
+	if len(updates) != 1 || updates["is_published"] != false {
+		t.Errorf("expected only is_published, got %v", updates)
+	}
+}
@@ -1,6 +1,479 @@
 package service
 
-// TODO: restore original contents of property_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+var (
+	ErrPropertyNotFound = errors.New("property not found")
+	ErrBillNotFound     = errors.New("bill not found")
+	ErrPaymentNotFound  = errors.New("payment not found")
+
+	financialYearPattern = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+)
+
+var propertyTypes = map[string]bool{
+	models.PropertyTypeResidential:  true,
+	models.PropertyTypeCommercial:   true,
+	models.PropertyTypeAgricultural: true,
+}
+
+var paymentMethods = map[string]bool{
+	"online": true,
+	"cash":   true,
+	"cheque": true,
+	"upi":    true,
+}
+
+// PropertyPatch lists the fields UpdateProperty may change. The owner and
+// property number are fixed once a property is registered.
+type PropertyPatch struct {
+	PropertyType    *string  `json:"property_type" binding:"omitempty,oneof=residential commercial agricultural"`
+	Address         *string  `json:"address" binding:"omitempty,min=1,max=500"`
+	Village         *string  `json:"village" binding:"omitempty,max=100"`
+	Taluka          *string  `json:"taluka" binding:"omitempty,max=100"`
+	Area            *float64 `json:"area" binding:"omitempty,gt=0"`
+	AnnualTaxAmount *float64 `json:"annual_tax_amount" binding:"omitempty,gte=0"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+// CreatePropertyInput is the data needed to register a property
+type CreatePropertyInput struct {
+	PropertyType    string
+	Address         string
+	Area            float64
+	AnnualTaxAmount float64
+}
+
+// CreateBillInput is the data needed to raise a tax bill
+type CreateBillInput struct {
+	FinancialYear string
+	Quarter       string
+	TaxAmount     float64
+	DueDate       string // YYYY-MM-DD
+}
+
+// PaymentInput is a payment made against a bill
+type PaymentInput struct {
+	BillID        uint
+	Amount        float64
+	PaymentMethod string
+	TransactionID string
+}
+
+// Receipt is the printable summary of a payment
+type Receipt struct {
+	ReceiptNumber string    `json:"receipt_number"`
+	PaidAt        time.Time `json:"paid_at"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	PropertyID    uint      `json:"property_id"`
+	Address       string    `json:"address"`
+	Village       string    `json:"village"`
+	FinancialYear string    `json:"financial_year"`
+	Quarter       string    `json:"quarter"`
+	BillAmount    float64   `json:"bill_amount"`
+	BalanceDue    float64   `json:"balance_due"`
+}
+
+type PropertyService struct {
+	propertyRepo   *repository.PropertyRepository
+	paymentRepo    *repository.PaymentRepository
+	userRepo       *repository.UserRepository
+	reminderSender Sender
+	tenant         *models.Panchayat
+}
+
+func NewPropertyService(propertyRepo *repository.PropertyRepository, paymentRepo *repository.PaymentRepository, userRepo *repository.UserRepository, reminderSender Sender) *PropertyService {
+	return &PropertyService{
+		propertyRepo:   propertyRepo,
+		paymentRepo:    paymentRepo,
+		userRepo:       userRepo,
+		reminderSender: reminderSender,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *PropertyService) ForTenant(tenant *models.Panchayat) *PropertyService {
+	bound := *s
+	bound.propertyRepo = s.propertyRepo.ForTenant(tenant.ID)
+	bound.paymentRepo = s.paymentRepo.ForTenant(tenant.ID)
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	bound.tenant = tenant
+	return &bound
+}
+
+// GetProperties lists properties within the scope
+func (s *PropertyService) GetProperties(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Property, int64, error) {
+	return s.propertyRepo.List(list, filters, scope)
+}
+
+func (s *PropertyService) GetProperty(propertyID uint) (*models.Property, error) {
+	property, err := s.propertyRepo.GetByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
+		}
+		return nil, err
+	}
+	return property, nil
+}
+
+// CreateProperty registers a property for its owner. The property takes the
+// owner's village and taluka so it falls under the right staff.
+func (s *PropertyService) CreateProperty(ownerID uint, input CreatePropertyInput) (*models.Property, error) {
+	if !propertyTypes[input.PropertyType] {
+		return nil, errors.New("property type must be residential, commercial, or agricultural")
+	}
+	if input.Area <= 0 {
+		return nil, errors.New("area must be greater than 0")
+	}
+	if input.AnnualTaxAmount < 0 {
+		return nil, errors.New("annual tax amount cannot be negative")
+	}
+
+	owner, err := s.userRepo.GetByID(ownerID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	property := &models.Property{
+		OwnerID:         ownerID,
+		PropertyType:    input.PropertyType,
+		Address:         input.Address,
+		Village:         owner.Village,
+		Taluka:          owner.Taluka,
+		Area:            input.Area,
+		AnnualTaxAmount: input.AnnualTaxAmount,
+		IsActive:        true,
+	}
+
+	// Without an explicit amount, assess tax at the panchayat's rate
+	if property.AnnualTaxAmount == 0 && s.tenant != nil {
+		if rate, ok := s.tenant.TaxRates[input.PropertyType]; ok {
+			property.AnnualTaxAmount = math.Round(input.Area*rate*100) / 100
+		}
+	}
+
+	if err := s.propertyRepo.Create(property); err != nil {
+		return nil, err
+	}
+	return property, nil
+}
+
+func (s *PropertyService) UpdateProperty(propertyID uint, patch PropertyPatch) (*models.Property, error) {
+	if patch.PropertyType != nil && !propertyTypes[*patch.PropertyType] {
+		return nil, errors.New("property type must be residential, commercial, or agricultural")
+	}
+	updates := patchUpdates(patch)
+	if len(updates) == 0 {
+		return nil, errors.New("no updatable fields provided")
+	}
+
+	if err := s.propertyRepo.Update(propertyID, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
+		}
+		return nil, err
+	}
+	return s.GetProperty(propertyID)
+}
+
+// DeleteProperty deactivates a property; its bills and payments are kept
+func (s *PropertyService) DeleteProperty(propertyID uint) error {
+	err := s.propertyRepo.Update(propertyID, map[string]interface{}{"is_active": false})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPropertyNotFound
+	}
+	return err
+}
+
+func (s *PropertyService) GetPropertyBills(propertyID uint) ([]models.TaxBill, error) {
+	return s.propertyRepo.ListBills(propertyID)
+}
+
+// GetUserDueBills returns the unpaid bills on all of a user's properties
+func (s *PropertyService) GetUserDueBills(userID uint) ([]models.TaxBill, error) {
+	return s.propertyRepo.ListDueBills(userID, 0)
+}
+
+func (s *PropertyService) CreateBill(propertyID uint, input CreateBillInput) (*models.TaxBill, error) {
+	property, err := s.GetProperty(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if !property.IsActive {
+		return nil, errors.New("property is not active")
+	}
+
+	if m := financialYearPattern.FindStringSubmatch(input.FinancialYear); m == nil || !consecutiveYears(m[1], m[2]) {
+		return nil, errors.New("financial year must look like 2024-25")
+	}
+	quarter := strings.ToUpper(input.Quarter)
+	switch quarter {
+	case "Q1", "Q2", "Q3", "Q4":
+	default:
+		return nil, errors.New("quarter must be Q1, Q2, Q3 or Q4")
+	}
+	if input.TaxAmount <= 0 {
+		return nil, errors.New("tax amount must be greater than 0")
+	}
+	dueDate, err := time.Parse("2006-01-02", input.DueDate)
+	if err != nil {
+		return nil, errors.New("due date must be YYYY-MM-DD")
+	}
+
+	bill := &models.TaxBill{
+		PropertyID:    propertyID,
+		FinancialYear: input.FinancialYear,
+		Quarter:       quarter,
+		Amount:        input.TaxAmount,
+		Status:        models.BillStatusPending,
+		DueDate:       dueDate,
+	}
+
+	if err := s.propertyRepo.CreateBill(bill); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "idx_tax_bill_period") {
+			return nil, errors.New("a bill for this period already exists")
+		}
+		return nil, err
+	}
+	return bill, nil
+}
+
+// MakePayment records a payment against a bill of the given property
+func (s *PropertyService) MakePayment(propertyID, userID uint, input PaymentInput) (*models.Payment, error) {
+	if !paymentMethods[input.PaymentMethod] {
+		return nil, errors.New("payment method must be online, cash, cheque, or upi")
+	}
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be greater than 0")
+	}
+
+	bill, err := s.propertyRepo.GetBill(input.BillID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBillNotFound
+		}
+		return nil, err
+	}
+	if bill.PropertyID != propertyID {
+		return nil, ErrBillNotFound
+	}
+	if bill.Status == models.BillStatusPaid {
+		return nil, errors.New("bill is already paid")
+	}
+
+	payment := &models.Payment{
+		UserID:        userID,
+		BillID:        bill.ID,
+		Amount:        math.Round(input.Amount*100) / 100,
+		PaymentMethod: input.PaymentMethod,
+		TransactionID: input.TransactionID,
+		Status:        models.PaymentStatusSuccess,
+		PaidAt:        time.Now(),
+	}
+
+	if err := s.paymentRepo.Record(payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetAllPayments lists payments on properties within the scope
+func (s *PropertyService) GetAllPayments(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Payment, int64, error) {
+	return s.paymentRepo.List(list, filters, scope)
+}
+
+// GetPaymentsAfter lists payments older than the cursor, newest first, with
+// the cursor of the next page, which is nil on the last page
+func (s *PropertyService) GetPaymentsAfter(cursor utils.Cursor, limit int, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.Payment, *utils.Cursor, error) {
+	// One extra row tells whether another page follows
+	payments, err := s.paymentRepo.ListAfter(cursor, limit+1, filters, scope)
+	if err != nil || len(payments) <= limit {
+		return payments, nil, err
+	}
+
+	payments = payments[:limit]
+	last := payments[limit-1]
+	return payments, &utils.Cursor{Time: last.PaidAt, ID: last.ID}, nil
+}
+
+func (s *PropertyService) GetPayment(paymentID uint) (*models.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetReceipt returns the receipt details for a payment
+func (s *PropertyService) GetReceipt(paymentID uint) (*Receipt, error) {
+	payment, err := s.GetPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		ReceiptNumber: payment.ReceiptNumber,
+		PaidAt:        payment.PaidAt,
+		Amount:        payment.Amount,
+		PaymentMethod: payment.PaymentMethod,
+		TransactionID: payment.TransactionID,
+	}
+	if bill := payment.Bill; bill != nil {
+		receipt.FinancialYear = bill.FinancialYear
+		receipt.Quarter = bill.Quarter
+		receipt.BillAmount = bill.Amount
+		receipt.BalanceDue = bill.Balance()
+		if property := bill.Property; property != nil {
+			receipt.PropertyID = property.ID
+			receipt.Address = property.Address
+			receipt.Village = property.Village
+		}
+	}
+	return receipt, nil
+}
+
+// GetPropertyStats summarises registered properties and collections
+func (s *PropertyService) GetPropertyStats() (map[string]interface{}, error) {
+	byType, err := s.propertyRepo.CountBy("property_type")
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, count := range byType {
+		total += count
+	}
+
+	assessed, err := s.propertyRepo.SumAnnualTax()
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding, err := s.propertyRepo.SumOutstanding(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	collectedThisMonth, err := s.paymentRepo.SumSince(monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"total_properties":     total,
+		"by_type":              byType,
+		"annual_tax_assessed":  assessed,
+		"overdue_amount":       outstanding,
+		"collected_this_month": collectedThisMonth,
+	}, nil
+}
+
+// GetRevenueReport totals collections per month, quarter or year. Dates are
+// YYYY-MM-DD and default to the last twelve months; endDate is inclusive.
+func (s *PropertyService) GetRevenueReport(startDate, endDate, groupBy string) (map[string]interface{}, error) {
+	switch groupBy {
+	case "month", "quarter", "year":
+	default:
+		return nil, errors.New("group_by must be month, quarter or year")
+	}
+
+	to := time.Now()
+	if endDate != "" {
+		t, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return nil, errors.New("end_date must be YYYY-MM-DD")
+		}
+		to = t
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+
+	from := to.AddDate(-1, 0, 0)
+	if startDate != "" {
+		t, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return nil, errors.New("start_date must be YYYY-MM-DD")
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return nil, errors.New("start_date must be before end_date")
+	}
+
+	rows, err := s.paymentRepo.Revenue(from, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var total float64
+	for _, row := range rows {
+		total += row.Total
+	}
+
+	return map[string]interface{}{
+		"start_date": from.Format("2006-01-02"),
+		"end_date":   to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"group_by":   groupBy,
+		"periods":    rows,
+		"total":      total,
+	}, nil
+}
+
+// SendPaymentReminder texts the owner the amount due on a property
+func (s *PropertyService) SendPaymentReminder(propertyID uint) error {
+	property, err := s.GetProperty(propertyID)
+	if err != nil {
+		return err
+	}
+
+	bills, err := s.propertyRepo.ListDueBills(0, propertyID)
+	if err != nil {
+		return err
+	}
+	if len(bills) == 0 {
+		return errors.New("no dues on this property")
+	}
+
+	var due float64
+	for _, bill := range bills {
+		due += bill.Balance()
+	}
+
+	owner, err := s.userRepo.GetByID(property.OwnerID)
+	if err != nil {
+		return errors.New("property owner not found")
+	}
+	if owner.PhoneNumber == "" {
+		return errors.New("property owner has no phone number")
+	}
+
+	body := fmt.Sprintf("Property tax of Rs. %.2f is due on your property at %s (earliest due date %s). Please pay at the Gram Panchayat office or online.",
+		due, property.Address, bills[0].DueDate.Format("02 Jan 2006"))
+	return s.reminderSender.Send(owner.PhoneNumber, "Property tax reminder", body)
+}
+
+// consecutiveYears checks that "2024" and "25" name adjacent years
+func consecutiveYears(start, end string) bool {
+	var y, e int
+	fmt.Sscanf(start, "%d", &y)
+	fmt.Sscanf(end, "%d", &e)
+	return (y+1)%100 == e
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+type CreateResolutionInput struct {
+	Title      string
+	Text       string
+	MoverID    uint
+	SeconderID uint
+}
+
+type VoteInput struct {
+	UserID uint
+	Vote   string
+}
+
+type ResolutionService struct {
+	meetingRepo    *repository.MeetingRepository
+	attendanceRepo *repository.AttendanceRepository
+	resolutionRepo *repository.ResolutionRepository
+	tenant         *models.Panchayat
+}
+
+func NewResolutionService(meetingRepo *repository.MeetingRepository, attendanceRepo *repository.AttendanceRepository, resolutionRepo *repository.ResolutionRepository) *ResolutionService {
+	return &ResolutionService{
+		meetingRepo:    meetingRepo,
+		attendanceRepo: attendanceRepo,
+		resolutionRepo: resolutionRepo,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *ResolutionService) ForTenant(tenant *models.Panchayat) *ResolutionService {
+	bound := *s
+	bound.meetingRepo = s.meetingRepo.ForTenant(tenant.ID)
+	bound.attendanceRepo = s.attendanceRepo.ForTenant(tenant.ID)
+	bound.resolutionRepo = s.resolutionRepo.ForTenant(tenant.ID)
+	bound.tenant = tenant
+	return &bound
+}
+
+// CreateResolution records a resolution moved in a meeting. The mover and
+// seconder must be different members who are marked present in the
+// meeting's attendance register.
+func (s *ResolutionService) CreateResolution(meetingID, adminID uint, input CreateResolutionInput) (*models.Resolution, error) {
+	meeting, err := s.meetingRepo.GetByID(meetingID)
+	if err != nil {
+		return nil, errors.New("meeting not found")
+	}
+	if meeting.Status != models.MeetingStatusInProgress && meeting.Status != models.MeetingStatusCompleted {
+		return nil, fmt.Errorf("cannot record resolutions for a %s meeting", meeting.Status)
+	}
+
+	if input.MoverID == input.SeconderID {
+		return nil, errors.New("mover and seconder must be different members")
+	}
+
+	present, err := s.attendanceRepo.PresentMembers(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !present[input.MoverID] {
+		return nil, errors.New("mover is not marked present at this meeting")
+	}
+	if !present[input.SeconderID] {
+		return nil, errors.New("seconder is not marked present at this meeting")
+	}
+
+	resolution := &models.Resolution{
+		MeetingID:     meetingID,
+		FinancialYear: s.tenant.FinancialYearOf(meeting.ScheduledAt),
+		Title:         input.Title,
+		Text:          input.Text,
+		MoverID:       input.MoverID,
+		SeconderID:    input.SeconderID,
+		Status:        models.ResolutionStatusPending,
+		CreatedBy:     adminID,
+	}
+
+	if err := s.resolutionRepo.Create(resolution); err != nil {
+		return nil, err
+	}
+
+	return resolution, nil
+}
+
+func (s *ResolutionService) GetResolution(resolutionID uint) (*models.Resolution, error) {
+	resolution, err := s.resolutionRepo.GetByID(resolutionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("resolution not found")
+		}
+		return nil, err
+	}
+	return resolution, nil
+}
+
+func (s *ResolutionService) GetResolutions(list utils.ListQuery, filters map[string]interface{}) ([]models.Resolution, int64, error) {
+	return s.resolutionRepo.List(list, filters)
+}
+
+// RecordVotes replaces the votes on a resolution. Only members present at the
+// meeting may vote, each at most once. A resolution passes when votes for
+// outnumber votes against. Votes can be corrected while the meeting is in
+// progress; once it is completed, a resolution still pending can have its
+// votes recorded once and the tally is then final.
+func (s *ResolutionService) RecordVotes(resolutionID uint, votes []VoteInput) (*models.Resolution, error) {
+	resolution, err := s.GetResolution(resolutionID)
+	if err != nil {
+		return nil, err
+	}
+	meeting, err := s.meetingRepo.GetByID(resolution.MeetingID)
+	if err != nil {
+		return nil, errors.New("meeting not found")
+	}
+	var fromStatus string
+	switch meeting.Status {
+	case models.MeetingStatusInProgress:
+	case models.MeetingStatusCompleted:
+		if resolution.Status != models.ResolutionStatusPending {
+			return nil, errors.New("the meeting is completed and the votes on this resolution are final")
+		}
+		fromStatus = models.ResolutionStatusPending
+	default:
+		return nil, fmt.Errorf("cannot record votes for a %s meeting", meeting.Status)
+	}
+
+	present, err := s.attendanceRepo.PresentMembers(resolution.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := map[string]int{}
+	voted := map[uint]bool{}
+	records := make([]models.ResolutionVote, 0, len(votes))
+	for _, v := range votes {
+		switch v.Vote {
+		case models.VoteFor, models.VoteAgainst, models.VoteAbstain:
+		default:
+			return nil, fmt.Errorf("invalid vote %q for member %d", v.Vote, v.UserID)
+		}
+		if !present[v.UserID] {
+			return nil, fmt.Errorf("member %d is not marked present at this meeting", v.UserID)
+		}
+		if voted[v.UserID] {
+			return nil, fmt.Errorf("member %d has voted more than once", v.UserID)
+		}
+		voted[v.UserID] = true
+		tally[v.Vote]++
+
+		records = append(records, models.ResolutionVote{
+			ResolutionID: resolutionID,
+			UserID:       v.UserID,
+			Vote:         v.Vote,
+		})
+	}
+
+	status := models.ResolutionStatusRejected
+	if tally[models.VoteFor] > tally[models.VoteAgainst] {
+		status = models.ResolutionStatusPassed
+	}
+
+	fields := map[string]interface{}{
+		"votes_for":     tally[models.VoteFor],
+		"votes_against": tally[models.VoteAgainst],
+		"votes_abstain": tally[models.VoteAbstain],
+		"status":        status,
+	}
+
+	if err := s.resolutionRepo.ReplaceVotes(resolutionID, fromStatus, records, fields); err != nil {
+		if errors.Is(err, repository.ErrStaleStatus) {
+			return nil, errors.New("resolution changed meanwhile, reload and try again")
+		}
+		return nil, err
+	}
+
+	return s.GetResolution(resolutionID)
+}
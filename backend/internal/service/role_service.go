@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+// rolePermissionsTTL bounds how long another server instance can keep using
+// permissions that were edited elsewhere
+const rolePermissionsTTL = time.Minute
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+)
+
+// RoleInput is the editable part of a role
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+// permissionKey names a role of one panchayat
+type permissionKey struct {
+	panchayatID uint
+	role        string
+}
+
+// permissionCache is shared by every tenant-bound copy of the service
+type permissionCache struct {
+	mu      sync.RWMutex
+	entries map[permissionKey]cachedPermissions
+}
+
+type RoleService struct {
+	roleRepo    *repository.RoleRepository
+	panchayatID uint
+	cache       *permissionCache
+}
+
+func NewRoleService(roleRepo *repository.RoleRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		cache:    &permissionCache{entries: map[permissionKey]cachedPermissions{}},
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *RoleService) ForTenant(tenant *models.Panchayat) *RoleService {
+	bound := *s
+	bound.roleRepo = s.roleRepo.ForTenant(tenant.ID)
+	bound.panchayatID = tenant.ID
+	return &bound
+}
+
+// EnsureDefaultRoles creates the built-in roles the panchayat does not have
+// yet and grants its admin role any permission added to the catalog since.
+func (s *RoleService) EnsureDefaultRoles() error {
+	for name, permissions := range models.DefaultRoles {
+		role, err := s.roleRepo.GetByName(name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			role = &models.Role{
+				Name:     name,
+				IsSystem: name == models.RoleAdmin || name == models.RoleCitizen,
+			}
+			for _, p := range permissions {
+				role.Grants = append(role.Grants, models.RolePermission{Permission: p})
+			}
+			if err := s.roleRepo.Create(role); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if name == models.RoleAdmin {
+			if err := s.roleRepo.AddPermissions(role.ID, allPermissions()); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// GetPermissionCatalog returns every known permission and its description
+func (s *RoleService) GetPermissionCatalog() map[string]string {
+	return models.PermissionCatalog
+}
+
+func (s *RoleService) GetRoles() ([]models.Role, error) {
+	return s.roleRepo.List()
+}
+
+func (s *RoleService) GetRole(name string) (*models.Role, error) {
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// RoleExists reports whether users can be given the role
+func (s *RoleService) RoleExists(name string) (bool, error) {
+	_, err := s.GetRole(name)
+	if errors.Is(err, ErrRoleNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *RoleService) CreateRole(input RoleInput) (*models.Role, error) {
+	if !roleNamePattern.MatchString(input.Name) {
+		return nil, errors.New("role name must be lowercase letters, digits and underscores")
+	}
+	if _, err := s.roleRepo.GetByName(input.Name); err == nil {
+		return nil, errors.New("role already exists")
+	}
+
+	permissions, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        input.Name,
+		Description: input.Description,
+	}
+	for _, p := range permissions {
+		role.Grants = append(role.Grants, models.RolePermission{Permission: p})
+	}
+
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return s.GetRole(role.Name)
+}
+
+// UpdateRole changes a role's description and replaces its permissions. The
+// admin role's permissions cannot be narrowed, so nobody can lock
+// administrators out.
+func (s *RoleService) UpdateRole(name string, input RoleInput) (*models.Role, error) {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Update(role.ID, map[string]interface{}{"description": input.Description}); err != nil {
+		return nil, err
+	}
+	if role.Name != models.RoleAdmin {
+		if err := s.roleRepo.SetPermissions(role.ID, permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	s.invalidate()
+	return s.GetRole(name)
+}
+
+func (s *RoleService) DeleteRole(name string) error {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return errors.New("built-in roles cannot be deleted")
+	}
+
+	users, err := s.roleRepo.CountUsers(name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return fmt.Errorf("role is assigned to %d users", users)
+	}
+
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Permissions returns the permission set of a role. Unknown roles have no
+// permissions. Results are cached for rolePermissionsTTL.
+func (s *RoleService) Permissions(roleName string) (map[string]bool, error) {
+	key := permissionKey{panchayatID: s.panchayatID, role: roleName}
+	s.cache.mu.RLock()
+	cached, ok := s.cache.entries[key]
+	s.cache.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < rolePermissionsTTL {
+		return cached.permissions, nil
+	}
+
+	permissions := map[string]bool{}
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if role != nil {
+		for _, p := range role.Permissions {
+			permissions[p] = true
+		}
+	}
+
+	// The admin role always holds everything, even before migration
+	if roleName == models.RoleAdmin {
+		for _, p := range allPermissions() {
+			permissions[p] = true
+		}
+	}
+
+	s.cache.mu.Lock()
+	s.cache.entries[key] = cachedPermissions{permissions: permissions, loadedAt: time.Now()}
+	s.cache.mu.Unlock()
+
+	return permissions, nil
+}
+
+// HasPermission reports whether the role grants the permission
+func (s *RoleService) HasPermission(roleName, permission string) bool {
+	permissions, err := s.Permissions(roleName)
+	return err == nil && permissions[permission]
+}
+
+// invalidate forgets the cached permissions of the panchayat's roles
+func (s *RoleService) invalidate() {
+	s.cache.mu.Lock()
+	for key := range s.cache.entries {
+		if key.panchayatID == s.panchayatID {
+			delete(s.cache.entries, key)
+		}
+	}
+	s.cache.mu.Unlock()
+}
+
+func allPermissions() []string {
+	names := make([]string, 0, len(models.PermissionCatalog))
+	for p := range models.PermissionCatalog {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizePermissions rejects permissions missing from the catalog and
+// removes duplicates
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if _, ok := models.PermissionCatalog[p]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sender delivers a short message to an email address or phone number
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// EmailSender delivers messages over SMTP
+type EmailSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewEmailSender reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD
+// and SMTP_FROM from the environment.
+func NewEmailSender() *EmailSender {
+	return &EmailSender{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     os.Getenv("SMTP_PORT"),
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("SMTP_FROM"),
+	}
+}
+
+func (s *EmailSender) Send(to, subject, body string) error {
+	if s.host == "" || s.from == "" {
+		return fmt.Errorf("email delivery is not configured")
+	}
+
+	msg := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	return smtp.SendMail(s.host+":"+s.port, auth, s.from, []string{to}, []byte(msg))
+}
+
+// SMSSender delivers messages through an HTTP SMS gateway that accepts a JSON
+// body of the form {"to": ..., "sender": ..., "message": ...}.
+type SMSSender struct {
+	apiURL   string
+	apiKey   string
+	senderID string
+	client   *http.Client
+}
+
+// NewSMSSender reads SMS_API_URL, SMS_API_KEY and SMS_SENDER_ID from the
+// environment.
+func NewSMSSender() *SMSSender {
+	return &SMSSender{
+		apiURL:   os.Getenv("SMS_API_URL"),
+		apiKey:   os.Getenv("SMS_API_KEY"),
+		senderID: os.Getenv("SMS_SENDER_ID"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *SMSSender) Send(to, subject, body string) error {
+	if s.apiURL == "" {
+		return fmt.Errorf("SMS delivery is not configured")
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"to":      to,
+		"sender":  s.senderID,
+		"message": body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LogSender only writes messages to the server log. It is meant for local
+// development and must not be used in production, since codes end up in logs.
+type LogSender struct{}
+
+func (LogSender) Send(to, subject, body string) error {
+	log.Printf("[dev delivery] to=%s subject=%q body=%q", to, subject, body)
+	return nil
+}
@@ -1,6 +1,243 @@
 package service
 
-// TODO: restore original contents of user_service.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"errors"
+	"time"
 
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+// ErrPermissionsNotHeld is returned when an actor hands out, or acts on a
+// user holding, a role with permissions the actor does not have
+var ErrPermissionsNotHeld = errors.New("role has permissions you do not have")
+
+// UserPatch lists the profile fields an administrator may correct on a user's
+// behalf. Role, status, password and verification changes have their own
+// endpoints; contact details and identity numbers are not editable.
+type UserPatch struct {
+	FirstName    *string    `json:"first_name" binding:"omitempty,min=1,max=100"`
+	LastName     *string    `json:"last_name" binding:"omitempty,min=1,max=100"`
+	DateOfBirth  *time.Time `json:"date_of_birth"`
+	Gender       *string    `json:"gender" binding:"omitempty,oneof=male female other"`
+	Address      *string    `json:"address" binding:"omitempty,max=500"`
+	Village      *string    `json:"village" binding:"omitempty,max=100"`
+	Taluka       *string    `json:"taluka" binding:"omitempty,max=100"`
+	District     *string    `json:"district" binding:"omitempty,max=100"`
+	State        *string    `json:"state" binding:"omitempty,max=100"`
+	Pincode      *string    `json:"pincode" binding:"omitempty,len=6,numeric"`
+	ProfileImage *string    `json:"profile_image" binding:"omitempty,max=500"`
+}
+
+type UserService struct {
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	roleService *RoleService
+}
+
+func NewUserService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, roleService *RoleService) *UserService {
+	return &UserService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		roleService: roleService,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *UserService) ForTenant(tenant *models.Panchayat) *UserService {
+	bound := *s
+	bound.userRepo = s.userRepo.ForTenant(tenant.ID)
+	bound.sessionRepo = s.sessionRepo.ForTenant(tenant.ID)
+	bound.roleService = s.roleService.ForTenant(tenant)
+	return &bound
+}
+
+// GetUsers lists accounts within the caller's jurisdiction scope
+func (s *UserService) GetUsers(list utils.ListQuery, filters map[string]interface{}, search string, scope models.JurisdictionScope) ([]models.User, int64, error) {
+	return s.userRepo.List(list, filters, search, scope)
+}
+
+func (s *UserService) GetUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) UpdateUser(userID uint, patch UserPatch) (*models.User, error) {
+	updates := patchUpdates(patch)
+	if len(updates) == 0 {
+		return nil, errors.New("no updatable fields provided")
+	}
+
+	if err := s.userRepo.Update(userID, updates); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// DeleteUser deletes an account the actor outranks and signs it out everywhere
+func (s *UserService) DeleteUser(actorRole string, userID uint) error {
+	if _, err := s.managedUser(actorRole, userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(userID); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonDeactivated)
+}
+
+// SetUserActiveStatus activates or deactivates an account the actor
+// outranks. Deactivation signs the user out of every device.
+func (s *UserService) SetUserActiveStatus(actorRole string, userID uint, isActive bool) error {
+	if _, err := s.managedUser(actorRole, userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(userID, map[string]interface{}{"is_active": isActive}); err != nil {
+		return err
+	}
+	if isActive {
+		return nil
+	}
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonDeactivated)
+}
+
+// ChangeUserRole changes a user's role and signs them out everywhere so no
+// session keeps acting with the old role. The actor can only change the
+// role of users they outrank, and only to roles whose permissions they hold
+// themselves.
+func (s *UserService) ChangeUserRole(actorRole string, userID uint, role string) error {
+	exists, err := s.roleService.RoleExists(role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+	if err := s.checkPermissionsHeld(actorRole, role); err != nil {
+		return err
+	}
+
+	user, err := s.managedUser(actorRole, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == role {
+		return nil
+	}
+
+	if err := s.userRepo.Update(userID, map[string]interface{}{"role": role}); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonRoleChanged)
+}
+
+// ResetPassword sets a new password for a user the actor outranks (admin
+// action) and signs the user out everywhere.
+func (s *UserService) ResetPassword(actorRole string, userID uint, newPassword string) error {
+	if _, err := s.managedUser(actorRole, userID); err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(userID, map[string]interface{}{"password": hash}); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllForUser(userID, revokeReasonPasswordReset)
+}
+
+// managedUser loads a user the actor may manage: one whose role holds no
+// permission the actor lacks, so a clerk cannot take over an admin account
+func (s *UserService) managedUser(actorRole string, userID uint) (*models.User, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPermissionsHeld(actorRole, user.Role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkPermissionsHeld returns ErrPermissionsNotHeld unless the actor's role
+// holds every permission of the role
+func (s *UserService) checkPermissionsHeld(actorRole, role string) error {
+	needed, err := s.roleService.Permissions(role)
+	if err != nil {
+		return err
+	}
+	held, err := s.roleService.Permissions(actorRole)
+	if err != nil {
+		return err
+	}
+	for permission := range needed {
+		if !held[permission] {
+			return ErrPermissionsNotHeld
+		}
+	}
+	return nil
+}
+
+func (s *UserService) GetUserStats() (map[string]interface{}, error) {
+	total, err := s.userRepo.Count(map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.userRepo.Count(map[string]interface{}{"is_active": true})
+	if err != nil {
+		return nil, err
+	}
+	verified, err := s.userRepo.Count(map[string]interface{}{"is_verified": true})
+	if err != nil {
+		return nil, err
+	}
+	byRole, err := s.userRepo.CountBy("role")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	newThisMonth, err := s.userRepo.CountCreatedSince(monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"total_users":    total,
+		"active_users":   active,
+		"inactive_users": total - active,
+		"verified_users": verified,
+		"users_by_role":  byRole,
+		"new_this_month": newThisMonth,
+	}, nil
+}
+
+// GetActiveSessions lists the devices a user is currently signed in on
+func (s *UserService) GetActiveSessions(userID uint) ([]models.Session, error) {
+	if _, err := s.GetUser(userID); err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.ListActive(userID)
+}
+
+// RevokeSession signs a user out of one device (admin action)
+func (s *UserService) RevokeSession(userID, sessionID uint) error {
+	if _, err := s.GetUser(userID); err != nil {
+		return err
+	}
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+	return s.sessionRepo.Revoke(sessionID, revokeReasonAdmin)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+)
+
+// newUserServiceWithTarget returns a user service of panchayat 2 on a
+// dry-run database in which every user lookup finds target, and in which a
+// clerk role can manage users and assign roles but do nothing else
+func newUserServiceWithTarget(t *testing.T, target models.User) (*UserService, *sqlRecorder) {
+	t.Helper()
+	db, recorder := newDryRunDB(t)
+	err := db.Callback().Query().After("gorm:query").Register("test:find_target", func(tx *gorm.DB) {
+		if user, ok := tx.Statement.Dest.(*models.User); ok {
+			*user = target
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roleService := NewRoleService(repository.NewRoleRepository(db))
+	for role, permissions := range map[string][]string{
+		"clerk":              {models.PermUsersManage, models.PermUsersRoleAssign},
+		models.RoleCitizen:   {},
+		models.RoleGramSevak: {models.PermUsersView, models.PermUsersManage, models.PermComplaintsViewAll},
+	} {
+		held := map[string]bool{}
+		for _, p := range permissions {
+			held[p] = true
+		}
+		roleService.cache.entries[permissionKey{panchayatID: 2, role: role}] = cachedPermissions{permissions: held, loadedAt: time.Now()}
+	}
+	s := NewUserService(repository.NewUserRepository(db), repository.NewSessionRepository(db), roleService)
+	return s.ForTenant(&models.Panchayat{ID: 2}), recorder
+}
+
+func TestUserManagementRefusesUsersWithPermissionsTheActorLacks(t *testing.T) {
+	actions := map[string]func(s *UserService, userID uint) error{
+		"change role": func(s *UserService, userID uint) error {
+			return s.ChangeUserRole("clerk", userID, models.RoleCitizen)
+		},
+		"reset password": func(s *UserService, userID uint) error {
+			return s.ResetPassword("clerk", userID, "secret123")
+		},
+		"deactivate": func(s *UserService, userID uint) error {
+			return s.SetUserActiveStatus("clerk", userID, false)
+		},
+		"delete": func(s *UserService, userID uint) error {
+			return s.DeleteUser("clerk", userID)
+		},
+	}
+
+	for name, act := range actions {
+		for _, role := range []string{models.RoleAdmin, models.RoleGramSevak} {
+			s, recorder := newUserServiceWithTarget(t, models.User{ID: 9, Role: role})
+			if err := act(s, 9); !errors.Is(err, ErrPermissionsNotHeld) {
+				t.Errorf("%s on a %s: expected ErrPermissionsNotHeld, got %v", name, role, err)
+			}
+			for _, sql := range recorder.sqls {
+				if strings.HasPrefix(sql, "UPDATE") || strings.HasPrefix(sql, "DELETE") {
+					t.Errorf("%s on a %s: refused action still wrote %q", name, role, sql)
+				}
+			}
+		}
+
+		s, recorder := newUserServiceWithTarget(t, models.User{ID: 9, Role: "clerk"})
+		if err := act(s, 9); errors.Is(err, ErrPermissionsNotHeld) {
+			t.Errorf("%s on a peer: expected the action allowed, got %v", name, err)
+		}
+		if !recorderWrote(recorder, `"users"`) {
+			t.Errorf("%s on a peer: expected the user written, got %q", name, recorder.sqls)
+		}
+	}
+}
+
+func TestChangeUserRoleRefusesRolesTheActorLacks(t *testing.T) {
+	s, _ := newUserServiceWithTarget(t, models.User{ID: 9, Role: models.RoleCitizen})
+	if err := s.ChangeUserRole("clerk", 9, models.RoleGramSevak); !errors.Is(err, ErrPermissionsNotHeld) {
+		t.Errorf("expected ErrPermissionsNotHeld, got %v", err)
+	}
+}
+
+// recorderWrote reports whether an UPDATE or DELETE touched the table
+func recorderWrote(recorder *sqlRecorder, table string) bool {
+	for _, sql := range recorder.sqls {
+		if (strings.HasPrefix(sql, "UPDATE") || strings.HasPrefix(sql, "DELETE")) && strings.Contains(sql, table) {
+			return true
+		}
+	}
+	return false
+}
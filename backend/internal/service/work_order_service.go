@@ -0,0 +1,569 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gram-panchayat/internal/models"
+	"gram-panchayat/internal/repository"
+	"gram-panchayat/internal/utils"
+)
+
+var (
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrMaterialNotFound  = errors.New("material not found")
+)
+
+// maxWorkOrderComplaints bounds the complaints fixed by one work order
+const maxWorkOrderComplaints = 20
+
+// workOrderTransitions lists the statuses a work order may be moved to by an
+// update. Completion has its own step, CompleteWorkOrder.
+var workOrderTransitions = map[string][]string{
+	models.WorkOrderStatusPlanned:    {models.WorkOrderStatusInProgress, models.WorkOrderStatusCancelled},
+	models.WorkOrderStatusInProgress: {models.WorkOrderStatusCancelled},
+}
+
+type WorkOrderService struct {
+	workOrderRepo       *repository.WorkOrderRepository
+	materialRepo        *repository.MaterialRepository
+	complaintService    *ComplaintService
+	notificationService *NotificationService
+}
+
+func NewWorkOrderService(workOrderRepo *repository.WorkOrderRepository, materialRepo *repository.MaterialRepository, complaintService *ComplaintService, notificationService *NotificationService) *WorkOrderService {
+	return &WorkOrderService{
+		workOrderRepo:       workOrderRepo,
+		materialRepo:        materialRepo,
+		complaintService:    complaintService,
+		notificationService: notificationService,
+	}
+}
+
+// ForTenant returns a copy of the service bound to one panchayat
+func (s *WorkOrderService) ForTenant(tenant *models.Panchayat) *WorkOrderService {
+	bound := *s
+	bound.workOrderRepo = s.workOrderRepo.ForTenant(tenant.ID)
+	bound.materialRepo = s.materialRepo.ForTenant(tenant.ID)
+	bound.complaintService = s.complaintService.ForTenant(tenant)
+	bound.notificationService = s.notificationService.ForTenant(tenant)
+	return &bound
+}
+
+type CreateWorkOrderInput struct {
+	Title        string
+	Description  string
+	ComplaintIDs []uint
+	Crew         []uint
+	PlannedDate  *time.Time
+	LaborCost    float64
+	Ward         string
+}
+
+// WorkOrderPatch lists the work order fields staff may change before it is
+// completed. Status may only start or cancel the work.
+type WorkOrderPatch struct {
+	Title       *string    `json:"title" binding:"omitempty,max=255"`
+	Description *string    `json:"description" binding:"omitempty,max=5000"`
+	Ward        *string    `json:"ward" binding:"omitempty,max=100"`
+	PlannedDate *time.Time `json:"planned_date"`
+	LaborCost   *float64   `json:"labor_cost" binding:"omitempty,min=0"`
+	Crew        *[]uint    `json:"crew" binding:"omitempty,max=20"`
+	Status      *string    `json:"status" binding:"omitempty,oneof=in_progress cancelled"`
+}
+
+type MaterialInput struct {
+	Name     string
+	Unit     string
+	UnitCost float64
+	Quantity float64
+}
+
+// MaterialPatch lists the material fields that may be edited; stock changes
+// through RestockMaterial and IssueMaterial only
+type MaterialPatch struct {
+	Name     *string  `json:"name" binding:"omitempty,max=255"`
+	Unit     *string  `json:"unit" binding:"omitempty,max=50"`
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+}
+
+// CreateWorkOrder plans the field work for one or more unresolved complaints
+// within the scope. The work order takes its category, village and, unless
+// given, its ward from the first complaint. The crew is notified.
+func (s *WorkOrderService) CreateWorkOrder(actorID uint, input CreateWorkOrderInput, scope models.JurisdictionScope) (*models.WorkOrder, error) {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+	if len(input.ComplaintIDs) == 0 {
+		return nil, errors.New("a work order needs at least one complaint")
+	}
+	if len(input.ComplaintIDs) > maxWorkOrderComplaints {
+		return nil, fmt.Errorf("a work order can fix at most %d complaints", maxWorkOrderComplaints)
+	}
+	if input.LaborCost < 0 {
+		return nil, errors.New("labour cost cannot be negative")
+	}
+
+	seen := map[uint]bool{}
+	var complaints []*models.Complaint
+	for _, id := range input.ComplaintIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("complaint #%d is listed twice", id)
+		}
+		seen[id] = true
+
+		complaint, err := s.complaintService.GetComplaint(id)
+		if err != nil {
+			return nil, fmt.Errorf("complaint #%d: %w", id, err)
+		}
+		if !scope.Allows(complaint.Village, complaint.Taluka) {
+			return nil, fmt.Errorf("complaint #%d: %w", id, ErrComplaintNotFound)
+		}
+		if complaint.MergedInto != nil || !isOpenComplaint(complaint.Status) {
+			return nil, fmt.Errorf("complaint #%d is %s and needs no work order", id, complaint.Status)
+		}
+		if complaint.WorkOrderID != nil {
+			linked, err := s.workOrderRepo.GetByID(*complaint.WorkOrderID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if linked != nil && containsString(models.WorkOrderActiveStatuses, linked.Status) {
+				return nil, fmt.Errorf("complaint #%d is already on work order #%d", id, *complaint.WorkOrderID)
+			}
+		}
+		complaints = append(complaints, complaint)
+	}
+	crew, err := s.checkCrew(input.Crew)
+	if err != nil {
+		return nil, err
+	}
+
+	first := complaints[0]
+	ward := strings.TrimSpace(input.Ward)
+	for _, complaint := range complaints {
+		if ward != "" {
+			break
+		}
+		ward = complaint.Ward
+	}
+	workOrder := &models.WorkOrder{
+		Title:       title,
+		Description: strings.TrimSpace(input.Description),
+		Category:    first.Category,
+		Ward:        ward,
+		Village:     first.Village,
+		Taluka:      first.Taluka,
+		Status:      models.WorkOrderStatusPlanned,
+		Crew:        crew,
+		PlannedDate: input.PlannedDate,
+		LaborCost:   input.LaborCost,
+		CreatedBy:   actorID,
+	}
+	if err := s.workOrderRepo.Create(workOrder, input.ComplaintIDs); err != nil {
+		return nil, err
+	}
+
+	s.notifyCrew(workOrder, crew)
+	return s.GetWorkOrder(workOrder.ID)
+}
+
+// GetWorkOrders lists work orders within the scope
+func (s *WorkOrderService) GetWorkOrders(list utils.ListQuery, filters map[string]interface{}, scope models.JurisdictionScope) ([]models.WorkOrder, int64, error) {
+	return s.workOrderRepo.List(list, filters, scope)
+}
+
+func (s *WorkOrderService) GetWorkOrder(workOrderID uint) (*models.WorkOrder, error) {
+	workOrder, err := s.workOrderRepo.GetByID(workOrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkOrderNotFound
+		}
+		return nil, err
+	}
+	return workOrder, nil
+}
+
+// UpdateWorkOrder applies a staff patch to a planned or in-progress work
+// order. Starting the work moves its complaints to in progress; cancelling
+// releases them for another work order.
+func (s *WorkOrderService) UpdateWorkOrder(workOrderID, actorID uint, patch WorkOrderPatch) (*models.WorkOrder, error) {
+	workOrder, err := s.GetWorkOrder(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Status == models.WorkOrderStatusCompleted || workOrder.Status == models.WorkOrderStatusCancelled {
+		return nil, fmt.Errorf("a %s work order cannot be changed", workOrder.Status)
+	}
+
+	fields := patchUpdates(patch)
+	delete(fields, "status")
+	delete(fields, "crew")
+	if title, ok := fields["title"].(string); ok && strings.TrimSpace(title) == "" {
+		return nil, errors.New("title cannot be empty")
+	}
+	var newCrew []uint
+	if patch.Crew != nil {
+		if newCrew, err = s.checkCrew(*patch.Crew); err != nil {
+			return nil, err
+		}
+		// Slice updates bypass the model's JSON serializer
+		crew, err := json.Marshal(newCrew)
+		if err != nil {
+			return nil, err
+		}
+		fields["crew"] = string(crew)
+	}
+
+	if patch.Status == nil || *patch.Status == workOrder.Status {
+		if len(fields) == 0 {
+			return nil, errors.New("no updatable work order fields provided")
+		}
+		if err := s.workOrderRepo.Update(workOrderID, fields); err != nil {
+			return nil, err
+		}
+	} else {
+		status := *patch.Status
+		if !isAllowedWorkOrderTransition(workOrder.Status, status) {
+			return nil, fmt.Errorf("cannot change work order status from %s to %s", workOrder.Status, status)
+		}
+		fields["status"] = status
+		if status == models.WorkOrderStatusInProgress {
+			fields["started_at"] = time.Now()
+		} else {
+			fields["cancelled_at"] = time.Now()
+		}
+		if err := s.workOrderRepo.ChangeStatus(workOrderID, workOrder.Status, fields); err != nil {
+			return nil, err
+		}
+		if status == models.WorkOrderStatusInProgress {
+			s.startComplaints(workOrder, actorID)
+		}
+	}
+
+	s.notifyCrew(workOrder, newlyAdded(workOrder.Crew, newCrew))
+	return s.GetWorkOrder(workOrderID)
+}
+
+// CompleteWorkOrder closes the work with a note on what was done and
+// resolves its complaints with that note. At least one photo of the site
+// after the work is required.
+func (s *WorkOrderService) CompleteWorkOrder(workOrderID, actorID uint, note string) (*models.WorkOrder, error) {
+	workOrder, err := s.GetWorkOrder(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Status != models.WorkOrderStatusPlanned && workOrder.Status != models.WorkOrderStatusInProgress {
+		return nil, fmt.Errorf("a %s work order cannot be completed", workOrder.Status)
+	}
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("a completion note is required")
+	}
+	afterPhotos, err := s.workOrderRepo.CountPhotos(workOrderID, models.PhotoStageAfter)
+	if err != nil {
+		return nil, err
+	}
+	if afterPhotos == 0 {
+		return nil, errors.New("add a photo of the site after the work before completing it")
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":          models.WorkOrderStatusCompleted,
+		"completed_at":    now,
+		"completion_note": note,
+	}
+	if workOrder.StartedAt == nil {
+		fields["started_at"] = now
+	}
+	if err := s.workOrderRepo.ChangeStatus(workOrderID, workOrder.Status, fields); err != nil {
+		return nil, err
+	}
+
+	// The work is done whatever happens to a complaint, so failures are
+	// logged rather than undoing the completion
+	resolved := models.ComplaintStatusResolved
+	resolution := fmt.Sprintf("Fixed under work order #%d: %s", workOrder.ID, note)
+	for _, complaint := range workOrder.Complaints {
+		if !isOpenComplaint(complaint.Status) || complaint.MergedInto != nil {
+			continue
+		}
+		patch := ComplaintPatch{Status: &resolved, Resolution: &resolution}
+		if _, err := s.complaintService.UpdateComplaint(complaint.ID, actorID, patch); err != nil {
+			log.Printf("work order %d: resolving complaint %d: %v", workOrder.ID, complaint.ID, err)
+		}
+	}
+	return s.GetWorkOrder(workOrderID)
+}
+
+// IssueMaterial draws a quantity of a material from stock for a planned or
+// in-progress work order, at the material's current unit cost
+func (s *WorkOrderService) IssueMaterial(workOrderID, actorID, materialID uint, quantity float64) (*models.WorkOrderMaterial, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+	if _, err := s.GetWorkOrder(workOrderID); err != nil {
+		return nil, err
+	}
+
+	line := &models.WorkOrderMaterial{
+		WorkOrderID: workOrderID,
+		MaterialID:  materialID,
+		Quantity:    quantity,
+		IssuedBy:    actorID,
+	}
+	if err := s.workOrderRepo.IssueMaterial(line); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrMaterialNotFound
+		case errors.Is(err, repository.ErrStaleStatus):
+			return nil, errors.New("materials can only be issued to planned or in-progress work orders")
+		}
+		return nil, err
+	}
+	return line, nil
+}
+
+// AddPhoto adds a photo of the site before or after the work to a work
+// order that is not yet completed or cancelled
+func (s *WorkOrderService) AddPhoto(workOrderID, actorID uint, stage, url, caption string) (*models.WorkOrderPhoto, error) {
+	if stage != models.PhotoStageBefore && stage != models.PhotoStageAfter {
+		return nil, fmt.Errorf("invalid photo stage %q", stage)
+	}
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil, errors.New("photo URL is required")
+	}
+	workOrder, err := s.GetWorkOrder(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Status == models.WorkOrderStatusCompleted || workOrder.Status == models.WorkOrderStatusCancelled {
+		return nil, fmt.Errorf("photos cannot be added to a %s work order", workOrder.Status)
+	}
+
+	photo := &models.WorkOrderPhoto{
+		WorkOrderID: workOrderID,
+		Stage:       stage,
+		URL:         url,
+		Caption:     strings.TrimSpace(caption),
+		UploadedBy:  actorID,
+	}
+	if err := s.workOrderRepo.AddPhoto(photo); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// GetCostReport totals the labour and material cost of work orders
+// completed in a period, per complaint category and per ward. Dates are
+// YYYY-MM-DD and default to the current month; endDate is inclusive.
+func (s *WorkOrderService) GetCostReport(startDate, endDate string) (map[string]interface{}, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 1, 0)
+	if startDate != "" {
+		t, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return nil, errors.New("start_date must be YYYY-MM-DD")
+		}
+		from = t
+	}
+	if endDate != "" {
+		t, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return nil, errors.New("end_date must be YYYY-MM-DD")
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		return nil, errors.New("start_date must be before end_date")
+	}
+
+	byCategory, err := s.workOrderRepo.CostReport("category", from, to)
+	if err != nil {
+		return nil, err
+	}
+	byWard, err := s.workOrderRepo.CostReport("COALESCE(NULLIF(ward, ''), 'unspecified')", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	total := repository.WorkOrderCostRow{Key: "total"}
+	for _, row := range byCategory {
+		total.WorkOrders += row.WorkOrders
+		total.Complaints += row.Complaints
+		total.LaborCost += row.LaborCost
+		total.MaterialCost += row.MaterialCost
+		total.TotalCost += row.TotalCost
+	}
+
+	return map[string]interface{}{
+		"start_date":  from.Format("2006-01-02"),
+		"end_date":    to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"by_category": byCategory,
+		"by_ward":     byWard,
+		"total":       total,
+	}, nil
+}
+
+// GetMaterials lists the materials in stock, optionally matching a search
+func (s *WorkOrderService) GetMaterials(list utils.ListQuery, search string) ([]models.Material, int64, error) {
+	return s.materialRepo.List(list, strings.TrimSpace(search))
+}
+
+func (s *WorkOrderService) GetMaterial(materialID uint) (*models.Material, error) {
+	material, err := s.materialRepo.GetByID(materialID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaterialNotFound
+		}
+		return nil, err
+	}
+	return material, nil
+}
+
+// CreateMaterial adds a material to the panchayat's stock
+func (s *WorkOrderService) CreateMaterial(input MaterialInput) (*models.Material, error) {
+	name := strings.TrimSpace(input.Name)
+	unit := strings.TrimSpace(input.Unit)
+	if name == "" || unit == "" {
+		return nil, errors.New("name and unit are required")
+	}
+	if input.UnitCost < 0 || input.Quantity < 0 {
+		return nil, errors.New("unit cost and quantity cannot be negative")
+	}
+
+	material := &models.Material{
+		Name:     name,
+		Unit:     unit,
+		UnitCost: input.UnitCost,
+		Quantity: input.Quantity,
+	}
+	if err := s.materialRepo.Create(material); err != nil {
+		return nil, err
+	}
+	return material, nil
+}
+
+func (s *WorkOrderService) UpdateMaterial(materialID uint, patch MaterialPatch) (*models.Material, error) {
+	fields := patchUpdates(patch)
+	if len(fields) == 0 {
+		return nil, errors.New("no updatable material fields provided")
+	}
+	for _, column := range []string{"name", "unit"} {
+		if value, ok := fields[column].(string); ok {
+			if fields[column] = strings.TrimSpace(value); fields[column] == "" {
+				return nil, fmt.Errorf("%s cannot be empty", column)
+			}
+		}
+	}
+	if err := s.materialRepo.Update(materialID, fields); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaterialNotFound
+		}
+		return nil, err
+	}
+	return s.GetMaterial(materialID)
+}
+
+// RestockMaterial adds a delivery to a material's stock
+func (s *WorkOrderService) RestockMaterial(materialID uint, quantity float64) (*models.Material, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+	if err := s.materialRepo.Restock(materialID, quantity); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaterialNotFound
+		}
+		return nil, err
+	}
+	return s.GetMaterial(materialID)
+}
+
+// checkCrew makes sure every crew member is active staff of the panchayat
+// and drops repeats
+func (s *WorkOrderService) checkCrew(crew []uint) ([]uint, error) {
+	seen := map[uint]bool{}
+	members := []uint{}
+	for _, userID := range crew {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if err := s.complaintService.checkAssignee(userID); err != nil {
+			return nil, fmt.Errorf("crew member %d: %w", userID, err)
+		}
+		members = append(members, userID)
+	}
+	return members, nil
+}
+
+// startComplaints moves a work order's complaints to in progress once work
+// starts, so their reporters hear of it
+func (s *WorkOrderService) startComplaints(workOrder *models.WorkOrder, actorID uint) {
+	inProgress := models.ComplaintStatusInProgress
+	note := fmt.Sprintf("Work started under work order #%d", workOrder.ID)
+	for _, complaint := range workOrder.Complaints {
+		if !isAllowedComplaintTransition(complaint.Status, inProgress) || complaint.MergedInto != nil {
+			continue
+		}
+		patch := ComplaintPatch{Status: &inProgress, Note: &note}
+		if _, err := s.complaintService.UpdateComplaint(complaint.ID, actorID, patch); err != nil {
+			log.Printf("work order %d: starting complaint %d: %v", workOrder.ID, complaint.ID, err)
+		}
+	}
+}
+
+// notifyCrew tells crew members they are on a work order
+func (s *WorkOrderService) notifyCrew(workOrder *models.WorkOrder, crew []uint) {
+	title := fmt.Sprintf("Work order #%d", workOrder.ID)
+	message := fmt.Sprintf("You are on the crew for %q.", workOrder.Title)
+	if workOrder.PlannedDate != nil {
+		message += " Planned for " + workOrder.PlannedDate.Format("02 Jan 2006") + "."
+	}
+	for _, userID := range crew {
+		if err := s.notificationService.Notify(userID, title, message, models.AuditEntityWorkOrder, workOrder.ID); err != nil {
+			log.Printf("work order: notifying user %d of work order %d: %v", userID, workOrder.ID, err)
+		}
+	}
+}
+
+// newlyAdded returns the members of next that are not in previous
+func newlyAdded(previous, next []uint) []uint {
+	had := map[uint]bool{}
+	for _, userID := range previous {
+		had[userID] = true
+	}
+	var added []uint
+	for _, userID := range next {
+		if !had[userID] {
+			added = append(added, userID)
+		}
+	}
+	return added
+}
+
+func isOpenComplaint(status string) bool {
+	for _, open := range models.ComplaintOpenStatuses {
+		if status == open {
+			return true
+		}
+	}
+	return false
+}
+
+func isAllowedWorkOrderTransition(from, to string) bool {
+	for _, next := range workOrderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
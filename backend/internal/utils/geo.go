@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetersPerDegree is the length of one degree of latitude, near enough
+const MetersPerDegree = 111320.0
+
+// MaxSearchRadius bounds ?radius= in meters
+const MaxSearchRadius = 50000
+
+// BoundingBox is a rectangle of coordinates in degrees
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// GeoFilter limits a list to a circle around a point, when RadiusMeters is
+// set, or else to a bounding box
+type GeoFilter struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	Box          BoundingBox
+}
+
+// ParseGeoFilter reads either ?lat=&lng=&radius= (radius in meters) or
+// ?bbox=minLng,minLat,maxLng,maxLat, the GeoJSON order. It returns nil when
+// the request asks for neither.
+func ParseGeoFilter(c *gin.Context) (*GeoFilter, error) {
+	var fieldErrors []FieldError
+	number := func(name string) float64 {
+		value, err := strconv.ParseFloat(c.Query(name), 64)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Message: "must be a number"})
+		}
+		return value
+	}
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		if c.Query("radius") != "" {
+			return nil, newValidationError([]FieldError{{Field: "bbox", Message: "cannot be combined with radius"}})
+		}
+		parts := strings.Split(bbox, ",")
+		var values [4]float64
+		valid := len(parts) == 4
+		for i := 0; valid && i < 4; i++ {
+			value, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+			values[i], valid = value, err == nil
+		}
+		box := BoundingBox{MinLongitude: values[0], MinLatitude: values[1], MaxLongitude: values[2], MaxLatitude: values[3]}
+		if !valid || !validLatitude(box.MinLatitude) || !validLatitude(box.MaxLatitude) ||
+			!validLongitude(box.MinLongitude) || !validLongitude(box.MaxLongitude) ||
+			box.MinLatitude > box.MaxLatitude || box.MinLongitude > box.MaxLongitude {
+			return nil, newValidationError([]FieldError{{Field: "bbox", Message: "must be minLng,minLat,maxLng,maxLat"}})
+		}
+		return &GeoFilter{Box: box}, nil
+	}
+
+	if c.Query("radius") == "" {
+		return nil, nil
+	}
+	filter := &GeoFilter{
+		Latitude:     number("lat"),
+		Longitude:    number("lng"),
+		RadiusMeters: number("radius"),
+	}
+	if len(fieldErrors) == 0 {
+		if !validLatitude(filter.Latitude) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "lat", Message: "must be between -90 and 90"})
+		}
+		if !validLongitude(filter.Longitude) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "lng", Message: "must be between -180 and 180"})
+		}
+		if filter.RadiusMeters <= 0 || filter.RadiusMeters > MaxSearchRadius {
+			fieldErrors = append(fieldErrors, FieldError{Field: "radius", Message: "must be between 1 and " + strconv.Itoa(MaxSearchRadius) + " meters"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, newValidationError(fieldErrors)
+	}
+	return CircleFilter(filter.Latitude, filter.Longitude, filter.RadiusMeters), nil
+}
+
+// CircleFilter limits a list to radiusMeters around a point. The box around
+// the circle lets the database narrow rows by plain comparisons before
+// measuring distances.
+func CircleFilter(latitude, longitude, radiusMeters float64) *GeoFilter {
+	latDelta := radiusMeters / MetersPerDegree
+	lngDelta := radiusMeters / (MetersPerDegree * math.Max(math.Cos(latitude*math.Pi/180), 0.01))
+	return &GeoFilter{
+		Latitude:     latitude,
+		Longitude:    longitude,
+		RadiusMeters: radiusMeters,
+		Box: BoundingBox{
+			MinLatitude:  math.Max(latitude-latDelta, -90),
+			MaxLatitude:  math.Min(latitude+latDelta, 90),
+			MinLongitude: math.Max(longitude-lngDelta, -180),
+			MaxLongitude: math.Min(longitude+lngDelta, 180),
+		},
+	}
+}
+
+// DistanceMeters is the great-circle distance between two points
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLng := (lng2 - lng1) * toRadians
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+func validLatitude(value float64) bool {
+	return value >= -90 && value <= 90
+}
+
+func validLongitude(value float64) bool {
+	return value >= -180 && value <= 180
+}
+
+// GeoJSONFeature is a point with properties
+type GeoJSONFeature struct {
+	ID         uint
+	Latitude   float64
+	Longitude  float64
+	Properties map[string]interface{}
+}
+
+// GeoJSON builds an RFC 7946 FeatureCollection of points
+func GeoJSON(features []GeoJSONFeature) ([]byte, error) {
+	type geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string                 `json:"type"`
+		ID         uint                   `json:"id"`
+		Geometry   geometry               `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: make([]feature, 0, len(features))}
+
+	for _, f := range features {
+		collection.Features = append(collection.Features, feature{
+			Type: "Feature",
+			ID:   f.ID,
+			// GeoJSON positions are longitude first
+			Geometry:   geometry{Type: "Point", Coordinates: [2]float64{f.Longitude, f.Latitude}},
+			Properties: f.Properties,
+		})
+	}
+	return json.Marshal(collection)
+}
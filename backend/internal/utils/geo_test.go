@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func geoFilter(t *testing.T, query string) (*GeoFilter, error) {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/complaints?"+query, nil)
+	return ParseGeoFilter(c)
+}
+
+func TestParseGeoFilterRadius(t *testing.T) {
+	filter, err := geoFilter(t, "lat=18.52&lng=73.85&radius=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Latitude != 18.52 || filter.Longitude != 73.85 || filter.RadiusMeters != 500 {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+
+	// The box spans the circle: 500 m is about 0.0045 degrees of latitude,
+	// and more degrees of longitude away from the equator
+	latSpan := filter.Box.MaxLatitude - filter.Box.MinLatitude
+	lngSpan := filter.Box.MaxLongitude - filter.Box.MinLongitude
+	if math.Abs(latSpan-0.00898) > 0.0001 {
+		t.Errorf("expected a latitude span of about 0.009, got %f", latSpan)
+	}
+	if lngSpan <= latSpan {
+		t.Errorf("expected a wider longitude span than %f, got %f", latSpan, lngSpan)
+	}
+}
+
+func TestParseGeoFilterBoundingBox(t *testing.T) {
+	filter, err := geoFilter(t, "bbox=73.8,18.5,73.9,18.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BoundingBox{MinLongitude: 73.8, MinLatitude: 18.5, MaxLongitude: 73.9, MaxLatitude: 18.6}
+	if filter.RadiusMeters != 0 || filter.Box != want {
+		t.Errorf("expected box %+v, got %+v", want, filter)
+	}
+}
+
+func TestParseGeoFilterRejectsBadInput(t *testing.T) {
+	if filter, err := geoFilter(t, "status=open"); filter != nil || err != nil {
+		t.Errorf("expected no filter without geo parameters, got %+v, %v", filter, err)
+	}
+
+	for _, query := range []string{
+		"lat=18.5&radius=500",
+		"lat=95&lng=73&radius=500",
+		"lat=18.5&lng=73&radius=100000",
+		"bbox=73.9,18.5,73.8,18.6",
+		"bbox=1,2,3",
+		"bbox=73.8,18.5,73.9,18.6&radius=500",
+	} {
+		_, err := geoFilter(t, query)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a ValidationError, got %v", query, err)
+		}
+	}
+}
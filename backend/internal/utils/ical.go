@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// iCalendar event statuses (RFC 5545 section 3.8.1.11)
+const (
+	ICalStatusConfirmed = "CONFIRMED"
+	ICalStatusTentative = "TENTATIVE"
+	ICalStatusCancelled = "CANCELLED"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// ICalEvent is a single VEVENT. UID must stay the same for the lifetime of
+// the event and Sequence must grow whenever the event changes, so calendar
+// apps update their existing entry instead of adding a new one.
+type ICalEvent struct {
+	UID          string
+	Sequence     int
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	LastModified time.Time
+}
+
+// ICalendar builds an RFC 5545 calendar. Method is optional; use "PUBLISH"
+// for one-off downloads and leave it empty for subscription feeds.
+func ICalendar(name, method string, events []ICalEvent) []byte {
+	var b strings.Builder
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Gram Panchayat//Meetings//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	if method != "" {
+		writeICalLine(&b, "METHOD:"+method)
+	}
+	if name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(name))
+	}
+
+	now := time.Now().UTC().Format(icalTimeFormat)
+	for _, e := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+e.UID)
+		writeICalLine(&b, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		writeICalLine(&b, "DTSTAMP:"+now)
+		writeICalLine(&b, "DTSTART:"+e.Start.UTC().Format(icalTimeFormat))
+		writeICalLine(&b, "DTEND:"+e.End.UTC().Format(icalTimeFormat))
+		if !e.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+e.LastModified.UTC().Format(icalTimeFormat))
+		}
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(e.Summary))
+		if e.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			writeICalLine(&b, "LOCATION:"+escapeICalText(e.Location))
+		}
+		if e.Status != "" {
+			writeICalLine(&b, "STATUS:"+e.Status)
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// escapeICalText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeICalLine writes a content line folded at 75 octets, never splitting a
+// multi-byte UTF-8 character (RFC 5545 section 3.1).
+func writeICalLine(b *strings.Builder, line string) {
+	const maxOctets = 75
+
+	limit := maxOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Start(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts toward the limit
+		limit = maxOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isUTF8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-secret"
+
+// signedToken builds a token with the given header and claims, signed with secret
+func signedToken(t *testing.T, header string, claims Claims, secret string) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(unsigned, []byte(secret))
+}
+
+func TestValidateToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+
+	valid, _, err := GenerateToken(7, "staff", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	tampered, _ := json.Marshal(Claims{UserID: 7, Role: "admin", SessionID: 3, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+	live := Claims{UserID: 7, Role: "staff", SessionID: 3, ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	expired := live
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	noSession := live
+	noSession.SessionID = 0
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", valid, nil},
+		{"tampered payload", parts[0] + "." + base64.RawURLEncoding.EncodeToString(tampered) + "." + parts[2], ErrInvalidToken},
+		{"alg none", signedToken(t, `{"alg":"none","typ":"JWT"}`, live, ""), ErrInvalidToken},
+		{"unsigned alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".", ErrInvalidToken},
+		{"HS512 header", signedToken(t, `{"alg":"HS512","typ":"JWT"}`, live, testJWTSecret), ErrInvalidToken},
+		{"wrong secret", signedToken(t, `{"alg":"HS256","typ":"JWT"}`, live, "other-secret"), ErrInvalidToken},
+		{"expired", signedToken(t, `{"alg":"HS256","typ":"JWT"}`, expired, testJWTSecret), ErrExpiredToken},
+		{"no session", signedToken(t, `{"alg":"HS256","typ":"JWT"}`, noSession, testJWTSecret), ErrInvalidToken},
+		{"malformed", "not-a-token", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		claims, err := ValidateToken(tt.token)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+			continue
+		}
+		if tt.want == nil && (claims.UserID != 7 || claims.SessionID != 3 || claims.Role != "staff") {
+			t.Errorf("%s: unexpected claims %+v", tt.name, claims)
+		}
+	}
+}
+
+func TestValidateTokenRequiresSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	if _, err := ValidateToken("a.b.c"); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("expected ErrMissingKey, got %v", err)
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("refresh") != HashToken("refresh") {
+		t.Error("expected the same token to hash the same")
+	}
+	if HashToken("refresh") == HashToken("refresh2") {
+		t.Error("expected different tokens to hash differently")
+	}
+	if hash := HashToken("refresh"); len(hash) != 64 || strings.Contains(hash, "refresh") {
+		t.Errorf("expected a SHA-256 hex digest, got %q", hash)
+	}
+}
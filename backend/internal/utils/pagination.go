@@ -1,6 +1,199 @@
 package utils
 
-// TODO: restore original contents of pagination.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
+)
+
+// Page size limits for list endpoints
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 100
+)
+
+// SortField is one column of a list's ordering
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Cursor marks the last row of a keyset page: its timestamp and ID. The
+// zero Cursor starts from the newest row.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   uint      `json:"id"`
+}
+
+// ListOptions describe what a list endpoint accepts
+type ListOptions struct {
+	// Sortable maps the names accepted by ?sort= to their columns
+	Sortable map[string]string
+	// DefaultSort is used without ?sort=, in the same syntax, e.g. "-created_at"
+	DefaultSort string
+	// DefaultLimit overrides DefaultPageLimit
+	DefaultLimit int
+	// Keyset enables ?cursor= pagination; the cursor fixes the ordering
+	Keyset bool
+}
+
+// ListQuery is a parsed page, limit and sort, or a keyset cursor
+type ListQuery struct {
+	Page   int
+	Limit  int
+	Sort   []SortField
+	Cursor *Cursor // set when the client asked for keyset pagination
+}
+
+// ParseListQuery reads page, limit, sort and cursor from the query string.
+// Page and limit are clamped to sane values; unknown sort fields and
+// malformed cursors are rejected with a *ValidationError.
+func ParseListQuery(c *gin.Context, options ListOptions) (ListQuery, error) {
+	query := ListQuery{Page: 1, Limit: options.DefaultLimit}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageLimit
+	}
+
+	var fieldErrors []FieldError
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "page", Message: "must be a number"})
+		} else if page > 1 {
+			query.Page = page
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		switch {
+		case err != nil:
+			fieldErrors = append(fieldErrors, FieldError{Field: "limit", Message: "must be a number"})
+		case limit < 1:
+			query.Limit = 1
+		case limit > MaxPageLimit:
+			query.Limit = MaxPageLimit
+		default:
+			query.Limit = limit
+		}
+	}
+
+	sortParam := c.Query("sort")
+	if sortParam == "" {
+		sortParam = options.DefaultSort
+	}
+	order, err := parseSort(sortParam, options.Sortable)
+	if err != nil {
+		fieldErrors = append(fieldErrors, FieldError{Field: "sort", Message: err.Error()})
+	}
+	query.Sort = order
+
+	if value, ok := c.GetQuery("cursor"); ok && options.Keyset {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "cursor", Message: "is invalid"})
+		}
+		if c.Query("sort") != "" || c.Query("page") != "" {
+			fieldErrors = append(fieldErrors, FieldError{Field: "cursor", Message: "cannot be combined with page or sort"})
+		}
+		query.Cursor = cursor
+	}
+
+	if len(fieldErrors) > 0 {
+		return query, newValidationError(fieldErrors)
+	}
+	return query, nil
+}
+
+// Offset is the number of rows before the page
+func (q ListQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// OrderBy renders the sort as an ORDER BY clause. The primary key is added
+// last so that rows with equal sort values keep a stable order across pages.
+func (q ListQuery) OrderBy() string {
+	clauses := make([]string, 0, len(q.Sort)+1)
+	hasID := false
+	for _, field := range q.Sort {
+		direction := " ASC"
+		if field.Desc {
+			direction = " DESC"
+		}
+		clauses = append(clauses, field.Column+direction)
+		hasID = hasID || field.Column == "id"
+	}
+	if !hasID {
+		clauses = append(clauses, "id DESC")
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// Pagination describes the page of a list with total rows
+func (q ListQuery) Pagination(total int64) Pagination {
+	return Pagination{
+		Page:       q.Page,
+		Limit:      q.Limit,
+		TotalPages: int((total + int64(q.Limit) - 1) / int64(q.Limit)),
+		TotalItems: total,
+	}
+}
+
+// EncodeCursor returns the opaque ?cursor= value that continues after a row
+func EncodeCursor(cursor Cursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// DecodeCursor parses a ?cursor= value; the empty string starts from the top
+func DecodeCursor(value string) (*Cursor, error) {
+	cursor := &Cursor{}
+	if value == "" {
+		return cursor, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(decoded, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// parseSort reads "-created_at,title": a comma-separated list of allowed
+// names, each descending when prefixed with "-"
+func parseSort(value string, sortable map[string]string) ([]SortField, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		column, ok := sortable[name]
+		if !ok {
+			allowed := make([]string, 0, len(sortable))
+			for name := range sortable {
+				allowed = append(allowed, name)
+			}
+			sort.Strings(allowed)
+			return nil, errors.New("must be a comma-separated list of: " + strings.Join(allowed, ", "))
+		}
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "strings"
+
+// NormalizePhone reduces an Indian mobile number to its ten digits, so that
+// "+91 98765 43210", "919876543210" and "9876543210" are the same number for
+// lookups and per-number OTP limits
+func NormalizePhone(phone string) string {
+	phone = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	phone = strings.TrimPrefix(phone, "+91")
+	if len(phone) == 12 && strings.HasPrefix(phone, "91") {
+		phone = phone[2:]
+	} else if len(phone) == 11 && strings.HasPrefix(phone, "0") {
+		phone = phone[1:]
+	}
+	return phone
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	for input, want := range map[string]string{
+		"9876543210":        "9876543210",
+		"+91 98765 43210":   "9876543210",
+		"+919876543210":     "9876543210",
+		"919876543210":      "9876543210",
+		"09876543210":       "9876543210",
+		" 98765-43210 ":     "9876543210",
+		"+91 (98765) 43210": "9876543210",
+	} {
+		if got := NormalizePhone(input); got != want {
+			t.Errorf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
@@ -1,6 +1,177 @@
 package utils
 
-// TODO: restore original contents of response.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"net/http"
 
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is the context key under which the request ID is stored
+const RequestIDKey = "requestID"
+
+// Error codes are part of the API contract: clients branch on them, so an
+// existing code must never be renamed or reused for a different condition.
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeInvalidRequest     = "INVALID_REQUEST"
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeInvalidID          = "INVALID_ID"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeTokenRequired      = "TOKEN_REQUIRED"
+	CodeTokenExpired       = "TOKEN_EXPIRED"
+	CodeTokenInvalid       = "TOKEN_INVALID"
+	CodeSessionRevoked     = "SESSION_REVOKED"
+	CodeAccountDeactivated = "ACCOUNT_DEACTIVATED"
+	CodeForbidden          = "FORBIDDEN"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeAuditFailed        = "AUDIT_FAILED"
+
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeRefreshTokenInvalid = "REFRESH_TOKEN_INVALID"
+	CodeRefreshTokenReused  = "REFRESH_TOKEN_REUSED"
+	CodeOTPInvalid          = "OTP_INVALID"
+	CodeOTPAttemptsExceeded = "OTP_ATTEMPTS_EXCEEDED"
+	CodeOTPResendTooSoon    = "OTP_RESEND_TOO_SOON"
+	CodeOTPLimitReached     = "OTP_LIMIT_REACHED"
+	CodeUnknownPermission   = "UNKNOWN_PERMISSION"
+	CodeFeedbackWindowOver  = "FEEDBACK_WINDOW_OVER"
+
+	CodePanchayatNotFound       = "PANCHAYAT_NOT_FOUND"
+	CodeUserNotFound            = "USER_NOT_FOUND"
+	CodeRoleNotFound            = "ROLE_NOT_FOUND"
+	CodePropertyNotFound        = "PROPERTY_NOT_FOUND"
+	CodeBillNotFound            = "BILL_NOT_FOUND"
+	CodePaymentNotFound         = "PAYMENT_NOT_FOUND"
+	CodeComplaintNotFound       = "COMPLAINT_NOT_FOUND"
+	CodeApplicationNotFound     = "APPLICATION_NOT_FOUND"
+	CodeApplicationTypeNotFound = "APPLICATION_TYPE_NOT_FOUND"
+	CodeMeetingNotFound         = "MEETING_NOT_FOUND"
+	CodeResolutionNotFound      = "RESOLUTION_NOT_FOUND"
+	CodeNoticeNotFound          = "NOTICE_NOT_FOUND"
+	CodeSchemeNotFound          = "SCHEME_NOT_FOUND"
+	CodeNotificationNotFound    = "NOTIFICATION_NOT_FOUND"
+	CodeWorkOrderNotFound       = "WORK_ORDER_NOT_FOUND"
+	CodeMaterialNotFound        = "MATERIAL_NOT_FOUND"
+)
+
+// statusCodes are the codes used when a handler does not name one
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          CodeBadRequest,
+	http.StatusUnauthorized:        CodeUnauthorized,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusTooManyRequests:     CodeRateLimited,
+	http.StatusInternalServerError: CodeInternal,
+}
+
+// Response is the envelope of every API response
+type Response struct {
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination interface{} `json:"pagination,omitempty"` // Pagination or CursorPagination
+	Error      *ErrorBody  `json:"error,omitempty"`
+}
+
+// ErrorBody describes why a request failed. Message is for people; Code is
+// for programs.
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Details   string       `json:"details,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	TotalPages int   `json:"total_pages"`
+	TotalItems int64 `json:"total_items"`
+}
+
+// CursorPagination describes a keyset page. NextCursor is passed back as
+// ?cursor= to fetch the following page and is empty on the last one.
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func SuccessResponse(c *gin.Context, status int, message string, data interface{}) {
+	c.JSON(status, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+func PaginatedSuccessResponse(c *gin.Context, status int, message string, data interface{}, pagination Pagination) {
+	c.JSON(status, Response{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
+func CursorSuccessResponse(c *gin.Context, status int, message string, data interface{}, pagination CursorPagination) {
+	c.JSON(status, Response{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
+// ErrorResponse aborts the request with the generic code for its status, such
+// as NOT_FOUND for 404. Use ErrorResponseWithCode when clients need to tell
+// this failure apart from others with the same status.
+func ErrorResponse(c *gin.Context, status int, message string, details string) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeBadRequest
+		if status >= http.StatusInternalServerError {
+			code = CodeInternal
+		}
+	}
+	ErrorResponseWithCode(c, status, code, message, details)
+}
+
+// ErrorResponseWithCode aborts the request with a specific error code
+func ErrorResponseWithCode(c *gin.Context, status int, code, message, details string) {
+	abortWithError(c, status, message, &ErrorBody{Code: code, Details: details})
+}
+
+// ValidationErrorResponse rejects a request with the fields that failed
+func ValidationErrorResponse(c *gin.Context, err *ValidationError) {
+	abortWithError(c, http.StatusBadRequest, "Validation failed", &ErrorBody{
+		Code:   CodeValidationFailed,
+		Fields: err.Fields,
+	})
+}
+
+// BindErrorResponse rejects a request whose body could not be bound. Field
+// validation failures are listed individually; malformed JSON is reported
+// as INVALID_REQUEST.
+func BindErrorResponse(c *gin.Context, err error) {
+	if validationErr := AsValidationError(err); validationErr != nil {
+		ValidationErrorResponse(c, validationErr)
+		return
+	}
+	ErrorResponseWithCode(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request data", err.Error())
+}
+
+func abortWithError(c *gin.Context, status int, message string, body *ErrorBody) {
+	body.RequestID = c.GetString(RequestIDKey)
+	c.AbortWithStatusJSON(status, Response{
+		Success: false,
+		Message: message,
+		Error:   body,
+	})
+}
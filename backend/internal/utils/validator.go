@@ -1,6 +1,204 @@
 package utils
 
-// TODO: restore original contents of validator.go.
-// This is an auto-generated stub to allow 'go build' to proceed.
-// Replace with the original implementation.
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
 
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation failures by the field names clients send
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			if name := jsonName(field); name != "" {
+				return name
+			}
+			return field.Name
+		})
+	}
+}
+
+// FieldError explains why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every rejected field of a request
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// BindPatch decodes a JSON object into a typed patch. Fields the patch does
+// not declare are rejected rather than ignored, so a client cannot slip a
+// protected column such as "role" into an update. Patches use pointer fields
+// so that absent fields are left unchanged, and "binding" tags for
+// validation.
+func BindPatch(c *gin.Context, patch interface{}) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	return DecodePatch(body, patch)
+}
+
+// DecodePatch is BindPatch for a raw request body
+func DecodePatch(body []byte, patch interface{}) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return &ValidationError{Fields: []FieldError{{Field: "body", Message: "must be a JSON object"}}}
+	}
+	if len(raw) == 0 {
+		return &ValidationError{Fields: []FieldError{{Field: "body", Message: "no fields to update"}}}
+	}
+
+	allowed := patchFieldNames(reflect.TypeOf(patch).Elem())
+
+	var fieldErrors []FieldError
+	for name := range raw {
+		if _, ok := allowed[name]; !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Message: "cannot be updated"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return newValidationError(fieldErrors)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if err := decoder.Decode(patch); err != nil {
+		if validationErr := AsValidationError(err); validationErr != nil {
+			return validationErr
+		}
+		return newValidationError([]FieldError{{Field: "body", Message: err.Error()}})
+	}
+
+	if err := binding.Validator.ValidateStruct(patch); err != nil {
+		if validationErr := AsValidationError(err); validationErr != nil {
+			return validationErr
+		}
+		return err
+	}
+	return nil
+}
+
+// AsValidationError describes a binding or decoding error field by field. It
+// returns nil for errors that are not about particular fields, such as
+// malformed JSON.
+func AsValidationError(err error) *ValidationError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return newValidationError([]FieldError{{Field: typeErr.Field, Message: "must be a " + jsonTypeName(typeErr.Type)}})
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{Field: fe.Field(), Message: validationMessage(fe)})
+		}
+		return newValidationError(fields)
+	}
+	return nil
+}
+
+func newValidationError(fields []FieldError) *ValidationError {
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &ValidationError{Fields: fields}
+}
+
+// patchFieldNames returns the JSON names of a struct's fields, including
+// those of embedded structs
+func patchFieldNames(t reflect.Type) map[string]struct{} {
+	names := map[string]struct{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name := range patchFieldNames(field.Type) {
+				names[name] = struct{}{}
+			}
+			continue
+		}
+		if name := jsonName(field); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	}
+	return "valid value"
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be %s or more", fe.Param())
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "numeric":
+		return "must contain only digits"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+type testPatch struct {
+	Name    *string  `json:"name" binding:"omitempty,min=2"`
+	Gender  *string  `json:"gender" binding:"omitempty,oneof=male female other"`
+	Pincode *string  `json:"pincode" binding:"omitempty,len=6,numeric"`
+	Area    *float64 `json:"area" binding:"omitempty,gt=0"`
+}
+
+func decodeError(t *testing.T, body string) *ValidationError {
+	t.Helper()
+	var patch testPatch
+	err := DecodePatch([]byte(body), &patch)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("%s: expected a ValidationError, got %v", body, err)
+	}
+	return validationErr
+}
+
+func TestDecodePatchRejectsUndeclaredFields(t *testing.T) {
+	err := decodeError(t, `{"name":"Asha","role":"admin","is_verified":true}`)
+
+	if len(err.Fields) != 2 {
+		t.Fatalf("expected 2 rejected fields, got %+v", err.Fields)
+	}
+	for i, field := range []string{"is_verified", "role"} {
+		if err.Fields[i].Field != field || err.Fields[i].Message != "cannot be updated" {
+			t.Errorf("field %d: got %+v", i, err.Fields[i])
+		}
+	}
+}
+
+func TestDecodePatchKeepsAbsentFieldsNil(t *testing.T) {
+	var patch testPatch
+	if err := DecodePatch([]byte(`{"name":"Asha","area":12.5}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	if patch.Name == nil || *patch.Name != "Asha" || patch.Area == nil || *patch.Area != 12.5 {
+		t.Errorf("sent fields not decoded: %+v", patch)
+	}
+	if patch.Gender != nil || patch.Pincode != nil {
+		t.Errorf("absent fields were set: %+v", patch)
+	}
+}
+
+func TestDecodePatchReportsInvalidValues(t *testing.T) {
+	cases := map[string]FieldError{
+		`{"area":"large"}`:     {Field: "area", Message: "must be a number"},
+		`{"area":0}`:           {Field: "area", Message: "must be greater than 0"},
+		`{"gender":"unknown"}`: {Field: "gender", Message: "must be one of: male, female, other"},
+		`{"pincode":"4130"}`:   {Field: "pincode", Message: "must be exactly 6 characters"},
+		`{"pincode":"41300a"}`: {Field: "pincode", Message: "must contain only digits"},
+		`{"name":"A"}`:         {Field: "name", Message: "must be at least 2"},
+	}
+	for body, want := range cases {
+		err := decodeError(t, body)
+		if len(err.Fields) != 1 || err.Fields[0] != want {
+			t.Errorf("%s: got %+v, want %+v", body, err.Fields, want)
+		}
+	}
+}
+
+func TestDecodePatchRejectsEmptyAndMalformedBodies(t *testing.T) {
+	for _, body := range []string{`{}`, `[]`, `"name"`, `{"name":`} {
+		err := decodeError(t, body)
+		if len(err.Fields) != 1 || err.Fields[0].Field != "body" {
+			t.Errorf("%s: got %+v", body, err.Fields)
+		}
+	}
+}